@@ -0,0 +1,65 @@
+// Command gojira-pack builds a single archive out of a directory of assets, to be mounted with
+// assets.Manager.MountZip.
+//
+// The images inside each directory of sprites/ are packed into an atlas, written as sprites/<directory>.png with
+// a TexturePacker sheet sprites/<directory>.json. The bitmap fonts and the shaders are validated, every other file
+// is copied as it is. The shaders are compiled with glslangValidator when it's on the PATH, otherwise only their
+// structure is checked: #version, comments and brackets. The archive has a manifest.json listing its files and
+// atlases, and the same assets always produce the same archive.
+//
+// Usage:
+//
+//	gojira-pack [-o assets.zip] [-atlas-size 2048] [-padding 2] <assets directory>
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	output := flag.String("o", "assets.zip", "archive to write")
+	atlasSize := flag.Int("atlas-size", 2048, "maximum width and height of the atlases, in pixels")
+	padding := flag.Int("padding", 2, "empty pixels between the sprites of an atlas")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: gojira-pack [flags] <assets directory>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !glslangInstalled() {
+		fmt.Fprintf(os.Stderr, "gojira-pack: %s not found, the shaders won't be compiled\n", glslangValidator)
+	}
+	if err := run(flag.Arg(0), *output, packOptions{atlasSize: *atlasSize, padding: *padding}); err != nil {
+		fmt.Fprintf(os.Stderr, "gojira-pack: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run packs the directory, the archive is written only if all the assets are valid
+func run(dir string, output string, options packOptions) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	files, err := pack(os.DirFS(dir), options)
+	if err != nil {
+		return err
+	}
+	var archive bytes.Buffer
+	if err := writeArchive(&archive, files); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(output, archive.Bytes(), 0644)
+}
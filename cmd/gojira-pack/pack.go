@@ -0,0 +1,254 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/maxfish/gojira2d/pkg/assets"
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+	"github.com/maxfish/gojira2d/pkg/ui"
+)
+
+// spritesDir every directory inside it becomes an atlas, named after the directory
+const spritesDir = "sprites"
+
+// Modification time of all the files of the archive, so that the same assets always give the same archive
+var packTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+
+// packOptions settings of the atlases
+type packOptions struct {
+	atlasSize int
+	padding   int
+}
+
+// validationError lists all the problems found in the assets
+type validationError struct {
+	problems []string
+}
+
+func (e *validationError) Error() string {
+	return fmt.Sprintf("%d problems found:\n%s", len(e.problems), strings.Join(e.problems, "\n"))
+}
+
+// pack reads the assets and returns the files of the archive, the manifest included, by name
+func pack(fsys fs.FS, options packOptions) (map[string][]byte, error) {
+	names, err := listFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	atlases := make(map[string][]string)
+	var atlasNames []string
+	var problems []string
+	for _, name := range names {
+		if atlas, ok := atlasOf(name); ok {
+			if _, found := atlases[atlas]; !found {
+				atlasNames = append(atlasNames, atlas)
+			}
+			atlases[atlas] = append(atlases[atlas], name)
+			continue
+		}
+		switch ext := path.Ext(name); {
+		case ext == ".fnt":
+			problems = append(problems, checkFont(fsys, name)...)
+		case shaderExtensions[ext]:
+			problems = append(problems, checkShader(fsys, name)...)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	manifest := &assets.Manifest{Atlases: []assets.ManifestAtlas{}}
+	sort.Strings(atlasNames)
+	for _, atlas := range atlasNames {
+		entry, atlasProblems := packAtlas(fsys, atlas, atlases[atlas], options, files)
+		problems = append(problems, atlasProblems...)
+		if entry != nil {
+			manifest.Atlases = append(manifest.Atlases, *entry)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &validationError{problems: problems}
+	}
+
+	for _, name := range sortedNames(files) {
+		sum := sha256.Sum256(files[name])
+		manifest.Files = append(manifest.Files, assets.ManifestFile{
+			Name:   name,
+			Size:   len(files[name]),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[assets.ManifestName] = data
+	return files, nil
+}
+
+// listFiles returns all the files, sorted. Hidden files and directories are skipped
+func listFiles(fsys fs.FS) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && name != assets.ManifestName {
+			names = append(names, name)
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// atlasOf returns the atlas a sprite belongs to. Only the images inside a directory of spritesDir are sprites
+func atlasOf(name string) (string, bool) {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 3 || parts[0] != spritesDir || !imageExtensions[strings.ToLower(path.Ext(name))] {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// packAtlas packs the sprites of an atlas, adding its image and its sheet to the files.
+// The sprites are named after their path inside the atlas directory, without extension
+func packAtlas(
+	fsys fs.FS, atlas string, sprites []string, options packOptions, files map[string][]byte,
+) (*assets.ManifestAtlas, []string) {
+	entry := &assets.ManifestAtlas{
+		Name:        atlas,
+		Image:       path.Join(spritesDir, atlas+".png"),
+		SpriteSheet: path.Join(spritesDir, atlas+".json"),
+	}
+	var problems []string
+	for _, name := range []string{entry.Image, entry.SpriteSheet} {
+		if _, found := files[name]; found {
+			problems = append(problems, fmt.Sprintf("%s: conflicts with the atlas %s", name, atlas))
+		}
+	}
+
+	builder := g.NewAtlasBuilder(options.atlasSize, options.atlasSize, options.padding)
+	prefix := path.Join(spritesDir, atlas) + "/"
+	// Images differing only by extension would give the same sprite
+	spriteFiles := make(map[string]string)
+	for _, name := range sprites {
+		spriteName := strings.TrimSuffix(strings.TrimPrefix(name, prefix), path.Ext(name))
+		if other, found := spriteFiles[spriteName]; found {
+			problems = append(problems, fmt.Sprintf("%s: same sprite name as %s", name, other))
+			continue
+		}
+		spriteFiles[spriteName] = name
+		img, err := decodeImage(fsys, name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		builder.Add(spriteName, img)
+		entry.Sprites = append(entry.Sprites, spriteName)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	layout, err := builder.Pack()
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: %v", prefix, err)}
+	}
+	var imageData bytes.Buffer
+	if err := png.Encode(&imageData, layout.Image); err != nil {
+		return nil, []string{fmt.Sprintf("%s: %v", entry.Image, err)}
+	}
+	sheet, err := layout.SpriteSheetJSON(path.Base(entry.Image))
+	if err != nil {
+		return nil, []string{fmt.Sprintf("%s: %v", entry.SpriteSheet, err)}
+	}
+	files[entry.Image] = imageData.Bytes()
+	files[entry.SpriteSheet] = sheet
+	sort.Strings(entry.Sprites)
+	return entry, nil
+}
+
+func decodeImage(fsys fs.FS, name string) (image.Image, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+// checkFont parses a bitmap font and checks that the images of its pages are in the assets
+func checkFont(fsys fs.FS, name string) []string {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	font, err := ui.NewBmFont(data, name)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	page := font.PageFile(0)
+	if page == "" {
+		return []string{fmt.Sprintf("%s: no page image", name)}
+	}
+	pagePath := path.Join(path.Dir(name), page)
+	if _, err := fs.Stat(fsys, pagePath); err != nil {
+		return []string{fmt.Sprintf("%s: page image %s not found", name, pagePath)}
+	}
+	if _, ok := atlasOf(pagePath); ok {
+		return []string{fmt.Sprintf("%s: page image %s would be packed in an atlas", name, pagePath)}
+	}
+	return nil
+}
+
+// writeArchive writes the files in a zip archive, sorted by name and with the same modification time
+func writeArchive(w io.Writer, files map[string][]byte) error {
+	archive := zip.NewWriter(w)
+	for _, name := range sortedNames(files) {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: packTime}
+		f, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/maxfish/gojira2d/pkg/assets"
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+const testFont = `info face="Test" size=32
+common lineHeight=40 base=30 scaleW=256 scaleH=256 pages=1 packed=0
+page id=0 file="test.png"
+chars count=1
+char id=65 x=0 y=0 width=20 height=30 xoffset=0 yoffset=2 xadvance=22 page=0 chnl=15
+`
+
+func pngData(t *testing.T, width int, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		t.Fatal(err)
+	}
+	return data.Bytes()
+}
+
+func testAssets(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"sprites/hero/idle.png":   {Data: pngData(t, 16, 16)},
+		"sprites/hero/walk/1.png": {Data: pngData(t, 16, 24)},
+		"sprites/items/coin.png":  {Data: pngData(t, 8, 8)},
+		"sprites/background.png":  {Data: pngData(t, 4, 4)},
+		"fonts/test.fnt":          {Data: []byte(testFont)},
+		"fonts/test.png":          {Data: pngData(t, 4, 4)},
+		"shaders/sprite.frag":     {Data: []byte("#version 410 core\nvoid main() {}\n")},
+		"sounds/jump.wav":         {Data: []byte("wav")},
+		".git/config":             {Data: []byte("hidden")},
+		"sprites/hero/.DS_Store":  {Data: []byte("hidden")},
+	}
+}
+
+func TestPack(t *testing.T) {
+	files, err := pack(testAssets(t), packOptions{atlasSize: 256, padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"fonts/test.fnt", "fonts/test.png", "manifest.json", "shaders/sprite.frag", "sounds/jump.wav",
+		"sprites/background.png", "sprites/hero.json", "sprites/hero.png", "sprites/items.json", "sprites/items.png",
+	}
+	if names := sortedNames(files); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, names)
+	}
+
+	// The archive can be mounted and its atlases parsed
+	dir, err := ioutil.TempDir("", "gojira-pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var archive bytes.Buffer
+	if err := writeArchive(&archive, files); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(dir, "assets.zip")
+	if err := ioutil.WriteFile(archivePath, archive.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := assets.NewManager()
+	if err := m.MountZip("game", archivePath); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	manifest, err := assets.ReadManifest(m, "game/"+assets.ManifestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != len(files)-1 || len(manifest.Atlases) != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	hero := manifest.Atlases[0]
+	if hero.Name != "hero" || !reflect.DeepEqual(hero.Sprites, []string{"idle", "walk/1"}) {
+		t.Errorf("expected\n%v received\n%v", []string{"idle", "walk/1"}, hero)
+	}
+	data, err := m.ReadFile("game/" + hero.SpriteSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := g.ParseSpriteSheet(data)
+	if err != nil || sheet.Image != "hero.png" || len(sheet.Frames) != 2 {
+		t.Errorf("unexpected sheet %+v %v", sheet, err)
+	}
+}
+
+func TestPackDeterministic(t *testing.T) {
+	var archives [2]bytes.Buffer
+	for i := range archives {
+		files, err := pack(testAssets(t), packOptions{atlasSize: 256, padding: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := writeArchive(&archives[i], files); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(archives[0].Bytes(), archives[1].Bytes()) {
+		t.Errorf("The same assets should produce the same archive")
+	}
+}
+
+func TestPackProblems(t *testing.T) {
+	fsys := testAssets(t)
+	fsys["fonts/missing_page.fnt"] = &fstest.MapFile{Data: []byte(strings.Replace(testFont, "test.png", "other.png", 1))}
+	fsys["fonts/empty.fnt"] = &fstest.MapFile{Data: []byte("not a font\n")}
+	fsys["shaders/broken.frag"] = &fstest.MapFile{Data: []byte("#version 410 core\nvoid main() {\n")}
+	fsys["sprites/items/broken.png"] = &fstest.MapFile{Data: []byte("not an image")}
+	fsys["sprites/items/coin.jpg"] = &fstest.MapFile{Data: pngData(t, 8, 8)}
+	fsys["sprites/items.png"] = &fstest.MapFile{Data: pngData(t, 4, 4)}
+	fsys["sprites/big/huge.png"] = &fstest.MapFile{Data: pngData(t, 300, 10)}
+
+	_, err := pack(fsys, packOptions{atlasSize: 256, padding: 1})
+	var validationErr *validationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validationError, received %v", err)
+	}
+	expected := []string{
+		"invalid font 'fonts/empty.fnt'",
+		"fonts/missing_page.fnt: page image fonts/other.png not found",
+		"shaders/broken.frag:2: unclosed '{'",
+		"sprites/big/",
+		"sprites/items.png: conflicts with the atlas items",
+		"sprites/items/broken.png",
+		"sprites/items/coin.png: same sprite name as sprites/items/coin.jpg",
+	}
+	if len(validationErr.problems) != len(expected) {
+		t.Fatalf("expected\n%v received\n%v", expected, validationErr.problems)
+	}
+	for i, problem := range validationErr.problems {
+		if !strings.HasPrefix(problem, expected[i]) {
+			t.Errorf("expected\n%v received\n%v", expected[i], problem)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Extensions of the shader stages, they must start with #version. The other shader files are included by them
+var shaderStages = map[string]bool{".vert": true, ".geom": true, ".frag": true}
+
+var shaderExtensions = map[string]bool{".vert": true, ".geom": true, ".frag": true, ".glsl": true}
+
+// glslangValidator the reference GLSL compiler, the shader stages are compiled with it when it's on the PATH
+var glslangValidator = "glslangValidator"
+
+// Errors of glslangValidator, "ERROR: <file>:<line>: <message>"
+var glslangError = regexp.MustCompile(`^ERROR: .*?:(\d+): (.*)$`)
+
+// syntaxError an error at a line of the checked code, 1 based
+type syntaxError struct {
+	line    int
+	message string
+}
+
+// checkShader resolves the includes of a shader and checks its structure. The stages are compiled too when
+// glslangValidator is installed. The errors are reported on the original files
+func checkShader(fsys fs.FS, name string) []string {
+	stage := shaderStages[path.Ext(name)]
+	if !stage {
+		// Included files are checked on their own, their includes are checked by the stages including them
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return []string{err.Error()}
+		}
+		var problems []string
+		for _, e := range checkShaderStructure(string(data), false) {
+			problems = append(problems, fmt.Sprintf("%s:%d: %s", name, e.line, e.message))
+		}
+		return problems
+	}
+
+	source, err := g.LoadShaderSourceFS(fsys, name, nil)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", name, err)}
+	}
+	errors := checkShaderStructure(source.Code, true)
+	if len(errors) == 0 {
+		errors, err = compileShader(source.Code, path.Ext(name))
+		if err != nil {
+			return []string{fmt.Sprintf("%s: %v", name, err)}
+		}
+	}
+	var problems []string
+	for _, e := range errors {
+		file, line := source.Location(e.line)
+		if file == "" {
+			file, line = name, e.line
+		}
+		problems = append(problems, fmt.Sprintf("%s:%d: %s", file, line, e.message))
+	}
+	return problems
+}
+
+// glslangInstalled tells if the shader stages can be compiled
+func glslangInstalled() bool {
+	_, err := exec.LookPath(glslangValidator)
+	return err == nil
+}
+
+// compileShader compiles a shader stage with glslangValidator, nothing is checked if it's not installed
+func compileShader(code string, ext string) ([]syntaxError, error) {
+	if !glslangInstalled() {
+		return nil, nil
+	}
+	dir, err := ioutil.TempDir("", "gojira-pack")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	// The stage is told by the extension
+	file := filepath.Join(dir, "shader"+ext)
+	if err := ioutil.WriteFile(file, []byte(code), 0644); err != nil {
+		return nil, err
+	}
+
+	output, err := exec.Command(glslangValidator, file).CombinedOutput()
+	if _, failed := err.(*exec.ExitError); err != nil && !failed {
+		return nil, err
+	}
+	errors := parseGlslangOutput(string(output))
+	if err != nil && len(errors) == 0 {
+		errors = append(errors, syntaxError{1, strings.TrimSpace(string(output))})
+	}
+	return errors, nil
+}
+
+// parseGlslangOutput returns the errors with a line reported by glslangValidator
+func parseGlslangOutput(output string) []syntaxError {
+	var errors []syntaxError
+	for _, line := range strings.Split(output, "\n") {
+		match := glslangError.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		lineNumber, _ := strconv.Atoi(match[1])
+		errors = append(errors, syntaxError{lineNumber, match[2]})
+	}
+	return errors
+}
+
+// checkShaderStructure finds the mistakes that break the structure of any GLSL code, without compiling it:
+// a missing #version, unterminated comments and unbalanced brackets. The preprocessor lines are not checked
+func checkShaderStructure(code string, needsVersion bool) []syntaxError {
+	type bracket struct {
+		char rune
+		line int
+	}
+	closing := map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+	var errors []syntaxError
+	var open []bracket
+	commentLine := 0
+	versionFound := false
+	for i, line := range strings.Split(code, "\n") {
+		lineNumber := i + 1
+		if commentLine == 0 && strings.HasPrefix(strings.TrimSpace(line), "#") {
+			directive := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+			if strings.HasPrefix(directive, "version") {
+				if versionFound || len(open) > 0 {
+					errors = append(errors, syntaxError{lineNumber, "#version must be the first directive"})
+				}
+				versionFound = true
+			}
+			continue
+		}
+
+		runes := []rune(line)
+		for j := 0; j < len(runes); j++ {
+			c := runes[j]
+			if commentLine > 0 {
+				if c == '*' && j+1 < len(runes) && runes[j+1] == '/' {
+					commentLine = 0
+					j++
+				}
+				continue
+			}
+			if c == '/' && j+1 < len(runes) {
+				if runes[j+1] == '/' {
+					break
+				}
+				if runes[j+1] == '*' {
+					commentLine = lineNumber
+					j++
+					continue
+				}
+			}
+			if needsVersion && !versionFound && c != ' ' && c != '\t' && c != '\r' {
+				errors = append(errors, syntaxError{lineNumber, "missing #version directive"})
+				versionFound = true
+			}
+			switch c {
+			case '(', '[', '{':
+				open = append(open, bracket{c, lineNumber})
+			case ')', ']', '}':
+				if len(open) == 0 {
+					errors = append(errors, syntaxError{lineNumber, fmt.Sprintf("unexpected '%c'", c)})
+					continue
+				}
+				// A wrong bracket closes the open one anyway, so that one mistake is reported once
+				if open[len(open)-1].char != closing[c] {
+					errors = append(errors, syntaxError{lineNumber, fmt.Sprintf("unexpected '%c'", c)})
+				}
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	if commentLine > 0 {
+		errors = append(errors, syntaxError{commentLine, "unterminated comment"})
+	}
+	for _, b := range open {
+		errors = append(errors, syntaxError{b.line, fmt.Sprintf("unclosed '%c'", b.char)})
+	}
+	if needsVersion && !versionFound {
+		errors = append(errors, syntaxError{1, "missing #version directive"})
+	}
+	return errors
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckShaderStructure(t *testing.T) {
+	shaders := []struct {
+		code         string
+		needsVersion bool
+		expected     []syntaxError
+	}{
+		{"#version 410 core\nvoid main() {\n  float a[2];\n}\n", true, nil},
+		{"// comment (\n#version 410 core\n/* { [ */\nvoid main() {}\n", true, nil},
+		{"float light(vec2 p) { return p.x; }\n", false, nil},
+		{"void main() {}\n", true, []syntaxError{{1, "missing #version directive"}}},
+		{"#version 410 core\nvoid main() {\n  f(a];\n}\n", true, []syntaxError{{3, "unexpected ']'"}}},
+		{"#version 410 core\nvoid main() {\n", true, []syntaxError{{2, "unclosed '{'"}}},
+		{"#version 410 core\nvoid main() {}\n}\n", true, []syntaxError{{3, "unexpected '}'"}}},
+		{"#version 410 core\n/* never closed\nvoid main() {}\n", true, []syntaxError{{2, "unterminated comment"}}},
+		{"#version 410 core\n#version 330 core\n", true, []syntaxError{{2, "#version must be the first directive"}}},
+	}
+	for _, s := range shaders {
+		errors := checkShaderStructure(s.code, s.needsVersion)
+		if !reflect.DeepEqual(errors, s.expected) {
+			t.Errorf("expected\n%v received\n%v", s.expected, errors)
+		}
+	}
+}
+
+func TestParseGlslangOutput(t *testing.T) {
+	output := "/tmp/gojira-pack1/shader.frag\n" +
+		"ERROR: /tmp/gojira-pack1/shader.frag:4: 'colour' : undeclared identifier\n" +
+		"WARNING: /tmp/gojira-pack1/shader.frag:2: unused\n" +
+		"ERROR: 0:7: '' : compilation terminated\n" +
+		"ERROR: 2 compilation errors.  No code generated.\n"
+	expected := []syntaxError{
+		{4, "'colour' : undeclared identifier"},
+		{7, "'' : compilation terminated"},
+	}
+	if errors := parseGlslangOutput(output); !reflect.DeepEqual(errors, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, errors)
+	}
+}
+
+func TestCheckShader(t *testing.T) {
+	// The structure only, the result must not depend on the tools installed
+	defer func(previous string) { glslangValidator = previous }(glslangValidator)
+	glslangValidator = "glslangValidator-not-installed"
+
+	fsys := fstest.MapFS{
+		"shaders/main.frag":   {Data: []byte("#version 410 core\n#include \"lib.glsl\"\nvoid main() {}\n")},
+		"shaders/lib.glsl":    {Data: []byte("float light() {\n  return 1.0;\n")},
+		"shaders/broken.vert": {Data: []byte("#version 410 core\n#include \"missing.glsl\"\n")},
+	}
+
+	problems := checkShader(fsys, "shaders/main.frag")
+	expected := []string{"shaders/lib.glsl:1: unclosed '{'"}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, problems)
+	}
+	problems = checkShader(fsys, "shaders/lib.glsl")
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, problems)
+	}
+	problems = checkShader(fsys, "shaders/broken.vert")
+	if len(problems) != 1 || !strings.Contains(problems[0], "shaders/broken.vert:2") {
+		t.Errorf("expected\n%v received\n%v", "shaders/broken.vert:2: ...", problems)
+	}
+}
@@ -10,7 +10,7 @@ func main() {
 	cellSize := 20
 	gridSize := worldSize / cellSize
 
-	app.Init(worldSize, worldSize, "Snake")
+	app.MustInit(worldSize, worldSize, "Snake")
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
@@ -19,7 +19,7 @@ func main() {
 	app.Context.Camera2D.SetVisibleArea(0, 0, float32(worldSize), float32(worldSize))
 
 	kbd := &input.KeyboardController{}
-	kbd.Open(-1)
+	input.MustOpen(kbd, -1)
 
 	game := newSnakeGame(cellSize, gridSize, worldSize, worldSize)
 	update := func(deltaTime float64) { game.update(kbd, deltaTime) }
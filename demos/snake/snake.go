@@ -34,6 +34,8 @@ type snakeGame struct {
 	food                  []bool
 	initScreen, spawnFood float64
 	speedupAfter          float64
+	// A single primitive, moved around to draw all the cells
+	cellPrimitive *g.Primitive2D
 }
 
 func newSnakeGame(cellSize, gridSize, width, height int) *snakeGame {
@@ -68,11 +70,13 @@ func (sg *snakeGame) cellToWorld(gx, gy int) mgl64.Vec3 {
 }
 
 func (sg *snakeGame) drawCell(cell cell, ctx *g.Context) {
-	p := g.NewRegularPolygonPrimitive(
-		sg.cellToWorld(cell.x, cell.y), sg.cellRadius-1, 4, true)
-	p.SetColor(g.Color{1, 1, 1, 1})
-	p.SetAngle(45 * math.Pi / 180)
-	p.Draw(app.Context)
+	if sg.cellPrimitive == nil {
+		sg.cellPrimitive = g.NewRegularPolygonPrimitive(mgl64.Vec3{}, sg.cellRadius-1, 4, true)
+		sg.cellPrimitive.SetColor(g.Color{1, 1, 1, 1})
+		sg.cellPrimitive.SetAngle(45 * math.Pi / 180)
+	}
+	sg.cellPrimitive.SetPosition(sg.cellToWorld(cell.x, cell.y))
+	sg.cellPrimitive.Draw(ctx)
 }
 
 func (sg *snakeGame) isValidPos(x, y int) bool {
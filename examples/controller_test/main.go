@@ -8,19 +8,19 @@ import (
 )
 
 func main() {
-	app.Init(640, 480, "Controller Test")
+	app.MustInit(640, 480, "Controller Test")
 	defer app.Terminate()
 
 	var joy input.GameController
 
 	// Tries getting a joystick...
 	joy = &input.JoystickController{}
-	joy.Open(0)
+	input.MustOpen(joy, 0)
 	if !joy.Connected() {
 		joy.Close()
 		// falls back to the keyboard
 		keyboard := &input.KeyboardController{}
-		keyboard.Open(-1)
+		input.MustOpen(keyboard, -1)
 		joy = keyboard
 	}
 	parts := make([]joystickPart, 0, 32)
@@ -6,10 +6,13 @@ import (
 	"github.com/maxfish/gojira2d/pkg/physics"
 )
 
-const pixelsPerMeter float64 = 50
+const (
+	pixelsPerMeter   float64 = 50
+	maxStepsPerFrame         = 5
+)
 
 func main() {
-	app.Init(800, 600, "Physics")
+	app.MustInit(800, 600, "Physics")
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
@@ -25,10 +28,14 @@ func main() {
 
 	debugDraw := physics.NewBox2DDebugDraw(b2World, pixelsPerMeter)
 
-	app.MainLoop(func(deltaTime float64) {
-		b2World.Step(1/scene.StepsPerSecond, scene.VelocityIterations, scene.PositionIterations)
+	// The simulation runs at the rate stored in the scene, regardless of the frame rate
+	err := app.MainLoopFixed(scene.StepsPerSecond, maxStepsPerFrame, func(deltaTime float64) {
+		b2World.Step(deltaTime, scene.VelocityIterations, scene.PositionIterations)
 		debugDraw.Update()
-	}, func() {
+	}, func(alpha float64) {
 		debugDraw.Draw(app.Context)
 	})
+	if err != nil {
+		panic(err)
+	}
 }
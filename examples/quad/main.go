@@ -7,7 +7,7 @@ import (
 )
 
 func main() {
-	app.Init(640, 480, "Quad")
+	app.MustInit(640, 480, "Quad")
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
@@ -15,7 +15,7 @@ func main() {
 
 	Quad := g.NewQuadPrimitive(mgl64.Vec3{0, 0, 0}, mgl64.Vec2{200, 200})
 	Quad.SetAnchorToCenter()
-	Quad.SetTexture(g.NewTextureFromFile("examples/assets/texture.png"))
+	Quad.SetTexture(g.MustNewTextureFromFile("examples/assets/texture.png"))
 
 	app.MainLoop(func(deltaTime float64) {
 		// NOP
@@ -9,12 +9,12 @@ import (
 )
 
 func main() {
-	app.Init(800, 600, "Quads")
+	app.MustInit(800, 600, "Quads")
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
 	quads := make([]*g.Primitive2D, 0, 12)
-	texture := g.NewTextureFromFile("examples/assets/texture.png")
+	texture := g.MustNewTextureFromFile("examples/assets/texture.png")
 
 	// Creates 12 quads in a grid 4x3
 	for y := 0; y < 3; y++ {
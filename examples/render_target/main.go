@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/app"
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+func main() {
+	app.MustInit(640, 480, "Render target")
+	defer app.Terminate()
+
+	app.SetFPSCounterVisible(true)
+
+	target, err := g.NewRenderTarget(256, 256, false)
+	if err != nil {
+		panic(err)
+	}
+	defer target.Release()
+
+	// Drawn into the target, the texture is shown on the screen quads the right way up
+	quad := g.NewQuadPrimitive(mgl64.Vec3{28, 28, 0}, mgl64.Vec2{200, 200})
+	quad.SetTexture(g.MustNewTextureFromFile("examples/assets/texture.png"))
+
+	left := g.NewQuadPrimitive(mgl64.Vec3{40, 112, 0}, mgl64.Vec2{256, 256})
+	left.SetTexture(target.Texture())
+	right := g.NewQuadPrimitive(mgl64.Vec3{344, 112, 0}, mgl64.Vec2{256, 256})
+	right.SetTexture(target.Texture())
+	right.SetAnchorToCenter()
+	right.SetPosition(mgl64.Vec3{472, 240, 0})
+
+	// The target has its own size, the context uses a camera covering it while the target is pushed
+	targetCamera := g.NewCamera2D(256, 256, 1)
+
+	app.MainLoop(func(deltaTime float64) {
+		right.SetAngle(right.Angle() + deltaTime)
+	}, func() {
+		target.Clear(g.Color{0.2, 0.2, 0.3, 1})
+		camera := app.Context.Camera2D
+		app.Context.Camera2D = targetCamera
+		app.Context.PushRenderTarget(target)
+		quad.Draw(app.Context)
+		app.Context.PopRenderTarget()
+		app.Context.Camera2D = camera
+
+		left.Draw(app.Context)
+		right.Draw(app.Context)
+	})
+}
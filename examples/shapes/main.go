@@ -9,7 +9,7 @@ import (
 )
 
 func main() {
-	app.Init(640, 480, "Shapes")
+	app.MustInit(640, 480, "Shapes")
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
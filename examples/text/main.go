@@ -12,19 +12,19 @@ import (
 )
 
 func main() {
-	app.Init(800, 600, "Text")
+	app.MustInit(800, 600, "Text")
 	app.SetClearColor(graphics.Color{0, 0, 0, 1})
 	defer app.Terminate()
 
 	app.SetFPSCounterVisible(true)
 
-	font2 := ui.NewFontFromFiles(
+	font2 := ui.MustNewFontFromFiles(
 		"bold",
 		"examples/assets/fonts/Roboto-Bold.fnt",
 		"examples/assets/fonts/Roboto-Bold.png",
 	)
 
-	font := ui.NewFontFromFiles(
+	font := ui.MustNewFontFromFiles(
 		"regular",
 		"examples/assets/fonts/Roboto-Regular.fnt",
 		"examples/assets/fonts/Roboto-Regular.png",
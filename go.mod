@@ -1,6 +1,6 @@
 module github.com/maxfish/gojira2d
 
-go 1.14
+go 1.16
 
 require (
 	github.com/ByteArena/box2d v1.0.2
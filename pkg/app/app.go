@@ -7,7 +7,9 @@ import (
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/assets"
 	g "github.com/maxfish/gojira2d/pkg/graphics"
+	"github.com/maxfish/gojira2d/pkg/log"
 	"github.com/maxfish/gojira2d/pkg/ui"
 	"github.com/maxfish/gojira2d/pkg/utils"
 )
@@ -26,32 +28,48 @@ var (
 	FpsCounter     *utils.FPSCounter
 	FpsCounterText *ui.Text
 
-	clearColor g.Color
+	clearColor  g.Color
+	spriteBatch *g.SpriteBatch
 )
 
 func init() {
+	// OpenGL and GLFW calls have to be done from the main thread
 	runtime.LockOSThread()
-	if err := glfw.Init(); err != nil {
-		panic(err)
-	}
 }
 
-// Init initializes the main window
-func Init(width int, height int, windowTitle string) {
+// Init initializes GLFW, OpenGL and the main window
+func Init(width int, height int, windowTitle string) error {
+	if err := glfw.Init(); err != nil {
+		return fmt.Errorf("app: unable to initialize GLFW: %w", err)
+	}
 	windowWidth = width
 	windowHeight = height
-	window = initWindow(windowWidth, windowHeight, windowTitle)
+	var err error
+	window, err = initWindow(windowWidth, windowHeight, windowTitle)
+	if err != nil {
+		glfw.Terminate()
+		return err
+	}
 	Context = &g.Context{}
 	Context.Camera2D = g.NewCamera2D(windowWidth, windowHeight, 1)
 	UIContext = &g.Context{}
 	UIContext.Camera2D = g.NewCamera2D(windowWidth, windowHeight, 1)
+	spriteBatch = g.NewSpriteBatch(g.DefaultSpriteBatchSize)
+	return nil
+}
+
+// MustInit is like Init but panics if the initialization fails
+func MustInit(width int, height int, windowTitle string) {
+	if err := Init(width, height, windowTitle); err != nil {
+		panic(err)
+	}
 }
 
 func Terminate() {
 	glfw.Terminate()
 }
 
-func initWindow(width, height int, title string) *glfw.Window {
+func initWindow(width, height int, title string) (*glfw.Window, error) {
 	glfw.WindowHint(glfw.Resizable, glfw.False)
 	glfw.WindowHint(glfw.ContextVersionMajor, OpenGLMajorVersion)
 	glfw.WindowHint(glfw.ContextVersionMinor, OpenGLMinorVersion)
@@ -60,13 +78,14 @@ func initWindow(width, height int, title string) *glfw.Window {
 
 	window, err := glfw.CreateWindow(width, height, title, nil, nil)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("app: unable to create the window: %w", err)
 	}
 	window.MakeContextCurrent()
 
 	// OpenGL
 	if err := gl.Init(); err != nil {
-		panic(err)
+		window.Destroy()
+		return nil, fmt.Errorf("app: unable to initialize OpenGL: %w", err)
 	}
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthMask(true)
@@ -76,9 +95,9 @@ func initWindow(width, height int, title string) *glfw.Window {
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 
 	version := gl.GoStr(gl.GetString(gl.VERSION))
-	fmt.Println("OpenGL version", version)
+	log.Infof("OpenGL version %s", version)
 
-	return window
+	return window, nil
 }
 
 func GetWindow() *glfw.Window {
@@ -97,26 +116,34 @@ func SetClearColor(color g.Color) {
 	clearColor = color
 }
 
-func SetFPSCounterVisible(visible bool) {
-	if visible {
-		if FpsCounter == nil {
-			FpsCounter = &utils.FPSCounter{}
-			font := ui.NewFontFromFiles(
-				"roboto-regular",
-				"pkg/assets/Roboto-Regular.fnt",
-				"pkg/assets/Roboto-Regular.png",
-			)
-			FpsCounterText = ui.NewText(
-				"0",
-				font,
-				mgl64.Vec3{float64(windowWidth - 30), 10, -1},
-				mgl64.Vec2{20, 20},
-				g.Color{1, 0, 0, 1},
-			)
-		}
-	} else {
+// SetFPSCounterVisible shows or hides the FPS counter in the top right corner of the window.
+// It fails if the font of the counter can't be loaded
+func SetFPSCounterVisible(visible bool) error {
+	if !visible {
 		FpsCounter = nil
+		return nil
+	}
+	if FpsCounter == nil {
+		font, err := assets.Default.Font(assets.EnginePrefix + "/Roboto-Regular.fnt")
+		if err != nil {
+			return err
+		}
+		FpsCounter = &utils.FPSCounter{}
+		FpsCounterText = ui.NewText(
+			"0",
+			font,
+			mgl64.Vec3{float64(windowWidth - 30), 10, -1},
+			mgl64.Vec2{20, 20},
+			g.Color{1, 0, 0, 1},
+		)
 	}
+	return nil
+}
+
+// SetShaderHotReload enables the reload of the shader programs loaded from files when the files change,
+// to be used during development
+func SetShaderHotReload(enabled bool) {
+	g.DefaultShaderWatcher.SetEnabled(enabled)
 }
 
 func MainLoop(
@@ -130,16 +157,65 @@ func MainLoop(
 		oldTime = newTime
 
 		update(deltaTime)
-		Clear()
-		render()
+		renderFrame(deltaTime, render)
+	}
+}
 
-		if FpsCounter != nil {
-			FpsCounter.Update(deltaTime, 1)
-			FpsCounterText.SetText(fmt.Sprintf("%v", FpsCounter.FPS()))
-			FpsCounterText.Draw(UIContext)
+// MainLoopFixed is like MainLoop but update is called at a fixed rate of ticksPerSecond, always receiving the same
+// deltaTime. When the frames are too slow, update is called at most maxSteps times per frame.
+// render receives the interpolation alpha [0,1) between the last update and the next one.
+// It fails without running if ticksPerSecond or maxSteps are not positive
+func MainLoopFixed(
+	ticksPerSecond float64,
+	maxSteps int,
+	update func(deltaTime float64),
+	render func(alpha float64),
+) error {
+	timestep, err := utils.NewFixedTimestep(ticksPerSecond, maxSteps)
+	if err != nil {
+		return err
+	}
+	var newTime, oldTime, deltaTime float64
+	oldTime = glfw.GetTime()
+	for !window.ShouldClose() {
+		newTime = glfw.GetTime()
+		deltaTime = newTime - oldTime
+		oldTime = newTime
+
+		steps := timestep.Advance(deltaTime)
+		if steps > 0 {
+			g.DebugWorld.Clear()
+			g.DebugUI.Clear()
 		}
+		for i := 0; i < steps; i++ {
+			update(timestep.Step())
+		}
+		// The shapes collected during update are drawn until the next update step, the ones of render only once
+		g.DebugWorld.Retain()
+		g.DebugUI.Retain()
+		renderFrame(deltaTime, func() { render(timestep.Alpha()) })
+	}
+	return nil
+}
 
-		glfw.PollEvents()
-		window.SwapBuffers()
+func renderFrame(deltaTime float64, render func()) {
+	g.RunMainThreadCalls()
+	g.DefaultShaderWatcher.Update(deltaTime)
+	Clear()
+	// Quads drawn on the main context during render are batched together
+	spriteBatch.Begin(Context)
+	render()
+	spriteBatch.End()
+	// Debug shapes collected during update and render go on top of the frame
+	g.DebugWorld.Flush(Context)
+
+	if FpsCounter != nil {
+		FpsCounter.Update(deltaTime, 1)
+		FpsCounterText.SetText(fmt.Sprintf("%v", FpsCounter.FPS()))
+		FpsCounterText.Draw(UIContext)
 	}
+	g.DebugUI.Flush(UIContext)
+
+	glfw.PollEvents()
+	window.SwapBuffers()
 }
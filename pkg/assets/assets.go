@@ -0,0 +1,137 @@
+// Package assets loads the assets of a game through a virtual file system, made of directories, zip archives and
+// embedded files mounted together, and caches them by name
+package assets
+
+import (
+	"archive/zip"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+//go:embed Roboto-Regular.fnt Roboto-Regular.png
+var engineFiles embed.FS
+
+// EngineFiles the files used by the engine itself, like the font of the FPS counter
+var EngineFiles fs.FS = engineFiles
+
+// EnginePrefix where EngineFiles are mounted in Default
+const EnginePrefix = "gojira2d"
+
+// Default the manager used by the engine. It has the working directory mounted at the root, so that the relative
+// paths keep working, and EngineFiles mounted at EnginePrefix
+var Default = NewManager()
+
+func init() {
+	Default.MountDir("", ".")
+	Default.Mount(EnginePrefix, EngineFiles)
+}
+
+// Manager resolves the asset names through the mounted file systems and caches what it loads.
+// The names are slash separated and relative, like the ones of fs.FS. A Manager is itself an fs.FS
+type Manager struct {
+	mounts  []mount
+	closers []*zip.ReadCloser
+	cache   map[string]*cacheEntry
+}
+
+type mount struct {
+	prefix string
+	fsys   fs.FS
+}
+
+// NewManager creates a manager without any file system mounted
+func NewManager() *Manager {
+	return &Manager{cache: make(map[string]*cacheEntry)}
+}
+
+// Mount makes the files of fsys visible under prefix, "" mounts them at the root. The file systems mounted last are
+// searched first, so they can override the files of the previous ones
+func (m *Manager) Mount(prefix string, fsys fs.FS) {
+	m.mounts = append(m.mounts, mount{prefix: strings.Trim(prefix, "/"), fsys: fsys})
+}
+
+// MountDir mounts a directory of the OS under prefix
+func (m *Manager) MountDir(prefix string, dir string) {
+	m.Mount(prefix, os.DirFS(dir))
+}
+
+// MountZip mounts the content of a zip archive under prefix, the archive stays open until Close
+func (m *Manager) MountZip(prefix string, zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("assets: unable to mount %s: %w", zipPath, err)
+	}
+	m.closers = append(m.closers, reader)
+	m.Mount(prefix, reader)
+	return nil
+}
+
+// Open opens a file looking into the mounted file systems, the last mounted first. The directories are not merged,
+// a directory lists only the files of the file system it has been found in
+func (m *Manager) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for i := len(m.mounts) - 1; i >= 0; i-- {
+		relative, ok := m.mounts[i].relative(name)
+		if !ok {
+			continue
+		}
+		file, err := m.mounts[i].fsys.Open(relative)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile reads a whole file, see Open
+func (m *Manager) ReadFile(name string) ([]byte, error) {
+	file, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+// Close releases everything in the cache and closes the zip archives mounted
+func (m *Manager) Close() error {
+	m.Clear()
+	var err error
+	for _, closer := range m.closers {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	m.closers = nil
+	m.mounts = nil
+	return err
+}
+
+// relative returns the name of the file inside the mounted file system
+func (mt mount) relative(name string) (string, bool) {
+	switch {
+	case mt.prefix == "":
+		return name, true
+	case name == mt.prefix:
+		return ".", true
+	case strings.HasPrefix(name, mt.prefix+"/"):
+		return name[len(mt.prefix)+1:], true
+	}
+	return "", false
+}
+
+// resolve returns the name of a file relative to another one
+func resolve(relativeTo string, name string) string {
+	return path.Join(path.Dir(relativeTo), name)
+}
@@ -0,0 +1,185 @@
+package assets
+
+import (
+	"archive/zip"
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+func writeZip(t *testing.T, files map[string]string) string {
+	file, err := ioutil.TempFile("", "assets-*.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w := zip.NewWriter(file)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestManagerMounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "level.txt"), []byte("dir"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zipPath := writeZip(t, map[string]string{"level.txt": "zip", "sounds/jump.wav": "wav"})
+	defer os.Remove(zipPath)
+
+	m := NewManager()
+	m.MountDir("", dir)
+	m.Mount("mods/", fstest.MapFS{"level.txt": {Data: []byte("mod")}})
+	if err := m.MountZip("", zipPath); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	files := []struct {
+		name     string
+		expected string
+	}{
+		// The zip, mounted last, overrides the directory
+		{"level.txt", "zip"},
+		{"sounds/jump.wav", "wav"},
+		{"mods/level.txt", "mod"},
+	}
+	for _, f := range files {
+		data, err := m.ReadFile(f.name)
+		if err != nil || string(data) != f.expected {
+			t.Errorf("expected\n%v received\n%v %v", f.expected, string(data), err)
+		}
+	}
+
+	if _, err := m.ReadFile("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected\n%v received\n%v", fs.ErrNotExist, err)
+	}
+	if _, err := m.Open("../level.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected\n%v received\n%v", fs.ErrInvalid, err)
+	}
+	if err := m.MountZip("", filepath.Join(dir, "missing.zip")); err == nil {
+		t.Errorf("expected an error mounting a missing archive")
+	}
+	if err := fstest.TestFS(m, "level.txt", "sounds/jump.wav"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestManagerData(t *testing.T) {
+	fsys := fstest.MapFS{"sounds/jump.wav": {Data: []byte("wav")}}
+	m := NewManager()
+	m.Mount("", fsys)
+
+	data, err := m.Data("sounds/jump.wav")
+	if err != nil || string(data) != "wav" {
+		t.Errorf("expected\n%v received\n%v %v", "wav", string(data), err)
+	}
+	// Cached, the file isn't read again
+	fsys["sounds/jump.wav"].Data = []byte("changed")
+	if data, _ := m.Data("sounds/jump.wav"); string(data) != "wav" {
+		t.Errorf("expected\n%v received\n%v", "wav", string(data))
+	}
+	m.Unload("sounds/jump.wav")
+	if data, _ := m.Data("sounds/jump.wav"); string(data) != "changed" {
+		t.Errorf("expected\n%v received\n%v", "changed", string(data))
+	}
+
+	var notFound *g.AssetNotFoundError
+	if _, err := m.Data("sounds/missing.wav"); !errors.As(err, &notFound) {
+		t.Errorf("expected\n%v received\n%v", "*AssetNotFoundError", err)
+	}
+	if _, err := m.Texture("missing.png"); !errors.As(err, &notFound) {
+		t.Errorf("expected\n%v received\n%v", "*AssetNotFoundError", err)
+	}
+	if _, err := m.Font("missing.fnt"); !errors.As(err, &notFound) {
+		t.Errorf("expected\n%v received\n%v", "*AssetNotFoundError", err)
+	}
+}
+
+func TestUnloadDependentSheets(t *testing.T) {
+	m := NewManager()
+	// Loading a sheet needs OpenGL, the entries are added as SpriteSheet does
+	released := 0
+	m.cache[textureKey+"sprites/items.png"] = &cacheEntry{release: func() { released++ }}
+	m.cache[sheetKey+"sprites/items.json"] = &cacheEntry{uses: textureKey + "sprites/items.png"}
+	m.cache[sheetKey+"sprites/level.json"] = &cacheEntry{uses: textureKey + "sprites/level.png"}
+
+	m.Unload("sprites/items.png")
+	if _, found := m.cache[sheetKey+"sprites/items.json"]; found || released != 1 {
+		t.Errorf("expected the sheet to be unloaded with its texture, released %d", released)
+	}
+	if _, found := m.cache[sheetKey+"sprites/level.json"]; !found {
+		t.Errorf("The sheets of the other textures should stay loaded")
+	}
+}
+
+func TestSceneInvalidJSON(t *testing.T) {
+	m := NewManager()
+	m.Mount("", fstest.MapFS{"scenes/broken.json": {Data: []byte(`{"world": `)}})
+	if _, err := m.Scene("scenes/broken.json", nil); err == nil {
+		t.Errorf("expected an error loading a broken scene")
+	}
+}
+
+func TestDefaultEngineFiles(t *testing.T) {
+	if _, err := Default.ReadFile(EnginePrefix + "/Roboto-Regular.fnt"); err != nil {
+		t.Errorf("expected the embedded font, received %v", err)
+	}
+	if _, err := Default.ReadFile("assets_test.go"); err != nil {
+		t.Errorf("expected the working directory to be mounted, received %v", err)
+	}
+}
+
+func TestShaderCacheKey(t *testing.T) {
+	key := shaderCacheKey("a.vert", "", "a.frag", map[string]string{"B": "2", "A": "1"})
+	expected := shaderKey + "a.vert||a.frag|A=1|B=2"
+	if key != expected {
+		t.Errorf("expected\n%v received\n%v", expected, key)
+	}
+	if !shaderKeyUses(key, "a.frag") || shaderKeyUses(key, "A=1") || shaderKeyUses(key, "") {
+		t.Errorf("unexpected files used by %v", key)
+	}
+}
+
+func TestUnloadShaders(t *testing.T) {
+	m := NewManager()
+	// Building a program needs OpenGL, the entries are added as Shader does
+	released := map[string]bool{}
+	entry := func(key string, files ...string) *cacheEntry {
+		return &cacheEntry{release: func() { released[key] = true }, files: files}
+	}
+	plain := shaderCacheKey("a.vert", "", "a.frag", nil)
+	lit := shaderCacheKey("b.vert", "", "b.frag", nil)
+	m.cache[plain] = entry(plain, "a.frag", "a.vert")
+	m.cache[lit] = entry(lit, "b.frag", "b.vert", "lib/light.glsl")
+
+	// The empty geometry shader is not a file
+	m.Unload("")
+	if len(released) != 0 {
+		t.Errorf("expected\n%v received\n%v", "nothing released", released)
+	}
+	m.Unload("lib/light.glsl")
+	expected := map[string]bool{lit: true}
+	if !reflect.DeepEqual(released, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, released)
+	}
+}
@@ -0,0 +1,256 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ByteArena/box2d"
+	b2djson "github.com/maxfish/go-b2dJson"
+	g "github.com/maxfish/gojira2d/pkg/graphics"
+	"github.com/maxfish/gojira2d/pkg/ui"
+)
+
+// Prefixes of the cache keys, an asset is cached once for each kind it's loaded as
+const (
+	textureKey = "texture:"
+	fontKey    = "font:"
+	shaderKey  = "shader:"
+	sheetKey   = "sheet:"
+	dataKey    = "data:"
+)
+
+type cacheEntry struct {
+	value   interface{}
+	release func()
+	// Key of the entry this one uses, it's removed together with it
+	uses string
+	// Files read to build the entry besides its own, like the ones included by the shaders
+	files []string
+}
+
+// Texture loads an image as a texture with g.DefaultTextureOptions, see TextureWithOptions
+func (m *Manager) Texture(name string) (*g.Texture, error) {
+	return m.TextureWithOptions(name, g.DefaultTextureOptions)
+}
+
+// TextureWithOptions loads an image as a texture, or returns the one already loaded with that name whatever
+// options it has been loaded with. The error is a *g.AssetNotFoundError or a *g.ImageDecodeError
+func (m *Manager) TextureWithOptions(name string, options g.TextureOptions) (*g.Texture, error) {
+	if entry, found := m.cache[textureKey+name]; found {
+		return entry.value.(*g.Texture), nil
+	}
+	imageData, err := m.decodeImage(name)
+	if err != nil {
+		return nil, err
+	}
+	texture := g.NewTextureFromImageWithOptions(imageData, options)
+	m.cache[textureKey+name] = &cacheEntry{value: texture, release: texture.Release}
+	return texture, nil
+}
+
+// Font loads a bitmap font and the image of its first page, relative to the font file
+func (m *Manager) Font(name string) (*ui.Font, error) {
+	if entry, found := m.cache[fontKey+name]; found {
+		return entry.value.(*ui.Font), nil
+	}
+	data, err := m.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := ui.NewBmFont(data, name)
+	if err != nil {
+		return nil, err
+	}
+	imageData, err := m.decodeImage(resolve(name, bm.PageFile(0)))
+	if err != nil {
+		return nil, err
+	}
+	texture := g.NewTextureFromImage(imageData)
+	font := ui.NewFont(bm, texture)
+	m.cache[fontKey+name] = &cacheEntry{value: font, release: texture.Release}
+	return font, nil
+}
+
+// SpriteSheet loads a TexturePacker or Aseprite sheet, like the atlases of the packs, and creates its regions on the
+// texture of its image. The image is cached as a texture, relative to the sheet
+func (m *Manager) SpriteSheet(name string) (*g.SpriteSheet, error) {
+	if entry, found := m.cache[sheetKey+name]; found {
+		return entry.value.(*g.SpriteSheet), nil
+	}
+	data, err := m.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := g.ParseSpriteSheet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	texture, err := m.Texture(resolve(name, sheet.Image))
+	if err != nil {
+		return nil, err
+	}
+	sheet.CreateRegions(texture)
+	m.cache[sheetKey+name] = &cacheEntry{value: sheet, uses: textureKey + resolve(name, sheet.Image)}
+	return sheet, nil
+}
+
+// Shader loads a program from shader files, pass an empty name to skip a shader. The program is cached by the names
+// and the defines, see g.NewShaderProgramFromFS
+func (m *Manager) Shader(vertName string, geomName string, fragName string, defines map[string]string) (*g.ShaderProgram, error) {
+	key := shaderCacheKey(vertName, geomName, fragName, defines)
+	if entry, found := m.cache[key]; found {
+		return entry.value.(*g.ShaderProgram), nil
+	}
+	program, err := g.NewShaderProgramFromFS(m, vertName, geomName, fragName, defines)
+	if err != nil {
+		return nil, err
+	}
+	m.cache[key] = &cacheEntry{value: program, release: program.Release, files: program.Files()}
+	return program, nil
+}
+
+// Data returns the content of a file, read once. It's meant for the assets the engine doesn't decode, like sounds
+func (m *Manager) Data(name string) ([]byte, error) {
+	if entry, found := m.cache[dataKey+name]; found {
+		return entry.value.([]byte), nil
+	}
+	data, err := m.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	m.cache[dataKey+name] = &cacheEntry{value: data}
+	return data, nil
+}
+
+// Scene builds a R.U.B.E. scene in world, a new one if world is nil. Only the file is cached, each call builds
+// new bodies
+func (m *Manager) Scene(name string, world *box2d.B2World) (*b2djson.B2DJsonScene, error) {
+	data, err := m.Data(name)
+	if err != nil {
+		return nil, err
+	}
+	// The loader prints the errors and returns an empty scene, the file is checked first
+	var scene map[string]interface{}
+	if err := json.Unmarshal(data, &scene); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	// The scenes can only be loaded from the OS file system
+	file, err := ioutil.TempFile("", "scene-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b2djson.NewB2DJsonSceneFromFile(file.Name(), world), nil
+}
+
+// Unload releases an asset loaded with any of the methods. Textures, fonts and programs can't be used afterwards,
+// the sheets using an unloaded texture are unloaded too, and so are the programs including an unloaded file
+func (m *Manager) Unload(name string) {
+	if name == "" {
+		return
+	}
+	for _, prefix := range []string{textureKey, fontKey, sheetKey, dataKey} {
+		m.remove(prefix + name)
+	}
+	for key, entry := range m.cache {
+		if strings.HasPrefix(key, shaderKey) && (shaderKeyUses(key, name) || entry.reads(name)) {
+			m.remove(key)
+		}
+	}
+}
+
+// reads tells if a file has been read to build the entry
+func (e *cacheEntry) reads(name string) bool {
+	for _, file := range e.files {
+		if file == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear releases all the assets in the cache
+func (m *Manager) Clear() {
+	for key := range m.cache {
+		m.remove(key)
+	}
+}
+
+func (m *Manager) remove(key string) {
+	entry, found := m.cache[key]
+	if !found {
+		return
+	}
+	delete(m.cache, key)
+	if entry.release != nil {
+		entry.release()
+	}
+	for otherKey, other := range m.cache {
+		if other.uses == key {
+			m.remove(otherKey)
+		}
+	}
+}
+
+// readFile reads a file reporting the missing ones as *g.AssetNotFoundError, like the loaders of the engine
+func (m *Manager) readFile(name string) ([]byte, error) {
+	data, err := m.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &g.AssetNotFoundError{Path: name, Err: err}
+	}
+	return data, err
+}
+
+func (m *Manager) decodeImage(name string) (image.Image, error) {
+	file, err := m.Open(name)
+	if err != nil {
+		return nil, &g.AssetNotFoundError{Path: name, Err: err}
+	}
+	defer file.Close()
+
+	imageData, _, err := image.Decode(file)
+	if err != nil {
+		return nil, &g.ImageDecodeError{Path: name, Err: err}
+	}
+	return imageData, nil
+}
+
+// shaderCacheKey joins the names of the files and the sorted defines
+func shaderCacheKey(vertName string, geomName string, fragName string, defines map[string]string) string {
+	parts := []string{vertName, geomName, fragName}
+	names := make([]string, 0, len(defines))
+	for name := range defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+defines[name])
+	}
+	return shaderKey + strings.Join(parts, "|")
+}
+
+// shaderKeyUses tells if a program has been built from a file, as one of its three shaders
+func shaderKeyUses(key string, name string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(key, shaderKey), "|", 4)
+	for _, part := range parts[:3] {
+		// The shaders not used are empty
+		if part != "" && part == name {
+			return true
+		}
+	}
+	return false
+}
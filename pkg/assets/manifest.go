@@ -0,0 +1,48 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+// ManifestName name of the manifest at the root of the packs built by gojira-pack
+const ManifestName = "manifest.json"
+
+// Manifest describes the content of a pack. The names are relative to the root of the pack
+type Manifest struct {
+	// Files of the pack sorted by name, the manifest excluded
+	Files []ManifestFile `json:"files"`
+	// Atlases built from the sprites, sorted by name
+	Atlases []ManifestAtlas `json:"atlases"`
+}
+
+// ManifestFile a file of a pack
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ManifestAtlas an atlas of a pack, made of an image and a sprite sheet that can be loaded with Manager.SpriteSheet
+type ManifestAtlas struct {
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	SpriteSheet string `json:"spriteSheet"`
+	// Names of the sprites, the regions of the sheet
+	Sprites []string `json:"sprites"`
+}
+
+// ReadManifest reads a manifest from a file system, name is ManifestName or prefix/ManifestName for a pack mounted
+// under prefix
+func ReadManifest(fsys fs.FS, name string) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,286 @@
+package graphics
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// AnimationMode how a clip behaves once its last frame is reached
+type AnimationMode int
+
+// Modes supported by the animation clips
+const (
+	// AnimationLoop restarts from the first frame
+	AnimationLoop AnimationMode = iota
+	// AnimationPingPong plays the frames backwards, then forward again
+	AnimationPingPong
+	// AnimationOnce stops on the last frame
+	AnimationOnce
+)
+
+// AnimationFrame a single frame of a clip
+type AnimationFrame struct {
+	Region *TextureRegion
+	// Duration in seconds
+	Duration float64
+	// Event is passed to the OnFrame callback when the frame is shown, it can be empty
+	Event string
+}
+
+// AnimationClip a named sequence of frames
+type AnimationClip struct {
+	Name   string
+	Mode   AnimationMode
+	Frames []AnimationFrame
+}
+
+// NewAnimationClip creates a clip from a list of frames
+func NewAnimationClip(name string, mode AnimationMode, frames ...AnimationFrame) *AnimationClip {
+	return &AnimationClip{
+		Name:   name,
+		Mode:   mode,
+		Frames: frames,
+	}
+}
+
+// NewAnimationClipFromTag creates a clip from a tag of a sprite sheet, using the frame durations of the sheet.
+// The regions of the sheet must have been created already
+func NewAnimationClipFromTag(sheet *SpriteSheet, tagName string) (*AnimationClip, error) {
+	tag := sheet.Tag(tagName)
+	if tag == nil {
+		return nil, fmt.Errorf("tag '%s' not found", tagName)
+	}
+	mode := AnimationLoop
+	if tag.Direction == TagDirectionPingPong {
+		mode = AnimationPingPong
+	}
+
+	clip := &AnimationClip{Name: tagName, Mode: mode}
+	for _, f := range sheet.TagFrames(tagName) {
+		region := sheet.Region(f.Name)
+		if region == nil {
+			return nil, fmt.Errorf("region '%s' not found", f.Name)
+		}
+		clip.Frames = append(clip.Frames, AnimationFrame{Region: region, Duration: f.Duration})
+	}
+	return clip, nil
+}
+
+// AnimationPlayer keeps track of the current frame of a clip, it doesn't draw anything
+type AnimationPlayer struct {
+	clip      *AnimationClip
+	frame     int
+	direction int
+	elapsed   float64
+	speed     float64
+	playing   bool
+	// OnFrame is called every time a new frame is shown
+	OnFrame func(frame int, event string)
+	// OnComplete is called when a clip reaches its end. Looping clips call it at every loop
+	OnComplete func(clip *AnimationClip)
+}
+
+// NewAnimationPlayer creates a player with normal speed
+func NewAnimationPlayer() *AnimationPlayer {
+	return &AnimationPlayer{speed: 1}
+}
+
+// Play starts a clip from its first frame
+func (a *AnimationPlayer) Play(clip *AnimationClip) {
+	a.clip = clip
+	a.frame = 0
+	a.direction = 1
+	a.elapsed = 0
+	a.playing = clip != nil && len(clip.Frames) > 0
+	if a.playing {
+		a.frameChanged()
+	}
+}
+
+// Stop stops the playback, the current frame stays visible
+func (a *AnimationPlayer) Stop() {
+	a.playing = false
+}
+
+// Resume resumes a stopped playback
+func (a *AnimationPlayer) Resume() {
+	a.playing = a.clip != nil && len(a.clip.Frames) > 0
+}
+
+// IsPlaying tells if the player is running
+func (a *AnimationPlayer) IsPlaying() bool {
+	return a.playing
+}
+
+// SetSpeed sets the playback speed: 1 is normal, 2 twice as fast
+func (a *AnimationPlayer) SetSpeed(speed float64) {
+	if speed < 0 {
+		speed = 0
+	}
+	a.speed = speed
+}
+
+// Speed returns the playback speed
+func (a *AnimationPlayer) Speed() float64 {
+	return a.speed
+}
+
+// Clip returns the current clip
+func (a *AnimationPlayer) Clip() *AnimationClip {
+	return a.clip
+}
+
+// Frame returns the index of the current frame
+func (a *AnimationPlayer) Frame() int {
+	return a.frame
+}
+
+// CurrentFrame returns the current frame, nil if there is no clip
+func (a *AnimationPlayer) CurrentFrame() *AnimationFrame {
+	if a.clip == nil || len(a.clip.Frames) == 0 {
+		return nil
+	}
+	return &a.clip.Frames[a.frame]
+}
+
+// Update advances the playback, it returns true if the frame has changed
+func (a *AnimationPlayer) Update(deltaTime float64) bool {
+	if !a.playing {
+		return false
+	}
+	changed := false
+	a.elapsed += deltaTime * a.speed
+	for a.playing {
+		duration := a.clip.Frames[a.frame].Duration
+		if duration <= 0 || a.elapsed < duration {
+			break
+		}
+		a.elapsed -= duration
+		if a.advance() {
+			changed = true
+			a.frameChanged()
+		}
+	}
+	return changed
+}
+
+// advance moves to the next frame, it returns false if the frame didn't change
+func (a *AnimationPlayer) advance() bool {
+	numFrames := len(a.clip.Frames)
+	switch a.clip.Mode {
+	case AnimationOnce:
+		if a.frame == numFrames-1 {
+			a.playing = false
+			a.elapsed = 0
+			a.completed()
+			return false
+		}
+		a.frame++
+	case AnimationPingPong:
+		if numFrames == 1 {
+			a.completed()
+			return false
+		}
+		next := a.frame + a.direction
+		if next < 0 || next >= numFrames {
+			a.direction = -a.direction
+			next = a.frame + a.direction
+		}
+		a.frame = next
+		if a.frame == 0 {
+			a.completed()
+		}
+	default:
+		a.frame = (a.frame + 1) % numFrames
+		if a.frame == 0 {
+			a.completed()
+		}
+		if numFrames == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *AnimationPlayer) frameChanged() {
+	if a.OnFrame != nil {
+		a.OnFrame(a.frame, a.clip.Frames[a.frame].Event)
+	}
+}
+
+func (a *AnimationPlayer) completed() {
+	if a.OnComplete != nil {
+		a.OnComplete(a.clip)
+	}
+}
+
+// AnimatedSprite a quad showing the frames of the clip being played
+type AnimatedSprite struct {
+	*Primitive2D
+	*AnimationPlayer
+	clips map[string]*AnimationClip
+	// Sets the anchor to the pivot of the region at every frame
+	usePivot bool
+}
+
+// NewAnimatedSprite creates a sprite with no clips. The size is taken from the regions of the frames
+func NewAnimatedSprite(position mgl64.Vec3) *AnimatedSprite {
+	s := &AnimatedSprite{
+		Primitive2D:     NewQuadPrimitive(position, mgl64.Vec2{1, 1}),
+		AnimationPlayer: NewAnimationPlayer(),
+		clips:           make(map[string]*AnimationClip),
+	}
+	return s
+}
+
+// AddClip adds a clip, it can be played by name
+func (s *AnimatedSprite) AddClip(clip *AnimationClip) {
+	s.clips[clip.Name] = clip
+}
+
+// ClipByName returns a clip by name, nil if not found
+func (s *AnimatedSprite) ClipByName(name string) *AnimationClip {
+	return s.clips[name]
+}
+
+// SetUsePivot makes the sprite anchor follow the pivot of the frame regions
+func (s *AnimatedSprite) SetUsePivot(usePivot bool) {
+	s.usePivot = usePivot
+	s.showFrame()
+}
+
+// PlayClip plays a clip by name. Playing the clip currently running doesn't restart it
+func (s *AnimatedSprite) PlayClip(name string) error {
+	clip, found := s.clips[name]
+	if !found {
+		return fmt.Errorf("clip '%s' not found", name)
+	}
+	if clip == s.Clip() && s.IsPlaying() {
+		return nil
+	}
+	s.Play(clip)
+	s.showFrame()
+	return nil
+}
+
+// Update advances the animation and updates the region shown by the quad
+func (s *AnimatedSprite) Update(deltaTime float64) {
+	if s.AnimationPlayer.Update(deltaTime) {
+		s.showFrame()
+	}
+}
+
+func (s *AnimatedSprite) showFrame() {
+	frame := s.CurrentFrame()
+	if frame == nil || frame.Region == nil {
+		return
+	}
+	if frame.Region != s.region {
+		s.SetTextureRegion(frame.Region)
+		s.SetSizeFromTexture()
+	}
+	if s.usePivot {
+		s.SetAnchorToPivot()
+	}
+}
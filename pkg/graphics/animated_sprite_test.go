@@ -0,0 +1,84 @@
+package graphics
+
+import (
+	"testing"
+)
+
+func newTestClip(mode AnimationMode, numFrames int) *AnimationClip {
+	frames := make([]AnimationFrame, numFrames)
+	for i := range frames {
+		frames[i].Duration = 0.1
+	}
+	frames[1].Event = "step"
+	return NewAnimationClip("test", mode, frames...)
+}
+
+func TestAnimationPlayerModes(t *testing.T) {
+	var tests = []struct {
+		mode        AnimationMode
+		frames      []int
+		completions int
+		playing     bool
+	}{
+		{AnimationLoop, []int{1, 2, 0, 1, 2, 0, 1}, 2, true},
+		{AnimationPingPong, []int{1, 2, 1, 0, 1, 2, 1}, 1, true},
+		{AnimationOnce, []int{1, 2, 2, 2, 2, 2, 2}, 1, false},
+	}
+
+	for _, test := range tests {
+		completions := 0
+		p := NewAnimationPlayer()
+		p.OnComplete = func(clip *AnimationClip) { completions++ }
+		p.Play(newTestClip(test.mode, 3))
+		for i, expected := range test.frames {
+			p.Update(0.1)
+			if p.Frame() != expected {
+				t.Errorf("Mode %d, step %d: got frame %d, expecting %d", test.mode, i, p.Frame(), expected)
+			}
+		}
+		if completions != test.completions {
+			t.Errorf("Mode %d: got %d completions, expecting %d", test.mode, completions, test.completions)
+		}
+		if p.IsPlaying() != test.playing {
+			t.Errorf("Mode %d: IsPlaying is %v, expecting %v", test.mode, p.IsPlaying(), test.playing)
+		}
+	}
+}
+
+func TestAnimationPlayerEventsAndSpeed(t *testing.T) {
+	var frames []int
+	var events []string
+	p := NewAnimationPlayer()
+	p.OnFrame = func(frame int, event string) {
+		frames = append(frames, frame)
+		if event != "" {
+			events = append(events, event)
+		}
+	}
+	p.Play(newTestClip(AnimationLoop, 3))
+	if len(frames) != 1 || frames[0] != 0 {
+		t.Errorf("Play should show the first frame, got %v", frames)
+	}
+
+	// Half a frame, nothing changes
+	if p.Update(0.05) {
+		t.Errorf("Frame should not change")
+	}
+	// Double speed: 0.05 + 0.1*2 = 2.5 frames
+	p.SetSpeed(2)
+	if !p.Update(0.1) || p.Frame() != 2 {
+		t.Errorf("Got frame %d, expecting 2", p.Frame())
+	}
+	if len(frames) != 3 || len(events) != 1 || events[0] != "step" {
+		t.Errorf("Got frames %v and events %v", frames, events)
+	}
+
+	p.Stop()
+	if p.Update(1) || p.Frame() != 2 {
+		t.Errorf("A stopped player should not change frame")
+	}
+	p.Resume()
+	if !p.Update(0.05) || p.Frame() != 0 {
+		t.Errorf("Got frame %d after resuming, expecting 0", p.Frame())
+	}
+}
@@ -0,0 +1,240 @@
+package graphics
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// AtlasBuilder packs many images into a single texture. The packing uses a skyline bottom-left algorithm
+type AtlasBuilder struct {
+	maxWidth  int
+	maxHeight int
+	padding   int
+	images    []atlasImage
+}
+
+type atlasImage struct {
+	name  string
+	image image.Image
+}
+
+// AtlasLayout the result of the packing: the composed image and the rectangle of each packed image
+type AtlasLayout struct {
+	Image *image.RGBA
+	Rects map[string]image.Rectangle
+	// Names of the packed images, in packing order
+	Names []string
+}
+
+// skylineSegment a horizontal segment of the skyline, everything below y is already used
+type skylineSegment struct {
+	x     int
+	y     int
+	width int
+}
+
+// NewAtlasBuilder creates a builder for atlases up to maxWidth x maxHeight pixels.
+// padding is the number of empty pixels left between the images, to avoid bleeding when filtering
+func NewAtlasBuilder(maxWidth int, maxHeight int, padding int) *AtlasBuilder {
+	return &AtlasBuilder{
+		maxWidth:  maxWidth,
+		maxHeight: maxHeight,
+		padding:   padding,
+	}
+}
+
+// Add adds an image to be packed with the specified name
+func (b *AtlasBuilder) Add(name string, img image.Image) {
+	b.images = append(b.images, atlasImage{name: name, image: img})
+}
+
+// Len returns the number of images added
+func (b *AtlasBuilder) Len() int {
+	return len(b.images)
+}
+
+// Pack computes the layout and composes the atlas image. It doesn't need an OpenGL context.
+// The result only depends on the images added, not on the order they were added in
+func (b *AtlasBuilder) Pack() (*AtlasLayout, error) {
+	images := make([]atlasImage, len(b.images))
+	copy(images, b.images)
+	// Taller images first, it gives a better packing. Sorting by name too makes the output deterministic
+	sort.Slice(images, func(i, j int) bool {
+		sizeI, sizeJ := images[i].image.Bounds().Size(), images[j].image.Bounds().Size()
+		if sizeI.Y != sizeJ.Y {
+			return sizeI.Y > sizeJ.Y
+		}
+		if sizeI.X != sizeJ.X {
+			return sizeI.X > sizeJ.X
+		}
+		return images[i].name < images[j].name
+	})
+
+	layout := &AtlasLayout{
+		Rects: make(map[string]image.Rectangle, len(images)),
+		Names: make([]string, 0, len(images)),
+	}
+	skyline := []skylineSegment{{0, 0, b.maxWidth}}
+	width, height := 0, 0
+	for _, img := range images {
+		if _, found := layout.Rects[img.name]; found {
+			return nil, fmt.Errorf("atlas: duplicate image name '%s'", img.name)
+		}
+		size := img.image.Bounds().Size()
+		x, y, ok := b.place(&skyline, size.X+b.padding, size.Y+b.padding)
+		if !ok {
+			return nil, fmt.Errorf("atlas: image '%s' (%dx%d) doesn't fit in %dx%d", img.name, size.X, size.Y, b.maxWidth, b.maxHeight)
+		}
+		rect := image.Rect(x, y, x+size.X, y+size.Y)
+		layout.Rects[img.name] = rect
+		layout.Names = append(layout.Names, img.name)
+		if rect.Max.X > width {
+			width = rect.Max.X
+		}
+		if rect.Max.Y > height {
+			height = rect.Max.Y
+		}
+	}
+
+	layout.Image = image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, img := range images {
+		rect := layout.Rects[img.name]
+		draw.Draw(layout.Image, rect, img.image, img.image.Bounds().Min, draw.Src)
+	}
+	return layout, nil
+}
+
+// place finds the lowest position where a width x height rectangle fits, then it updates the skyline
+func (b *AtlasBuilder) place(skyline *[]skylineSegment, width int, height int) (int, int, bool) {
+	segments := *skyline
+	bestIndex, bestX, bestY, bestWaste := -1, 0, 0, 0
+	for i := range segments {
+		y, waste, ok := b.fit(segments, i, width, height)
+		if !ok {
+			continue
+		}
+		if bestIndex < 0 || y < bestY || (y == bestY && waste < bestWaste) {
+			bestIndex, bestX, bestY, bestWaste = i, segments[i].x, y, waste
+		}
+	}
+	if bestIndex < 0 {
+		return 0, 0, false
+	}
+
+	// Inserts the new segment on top of the rectangle and shrinks the ones it covers
+	segment := skylineSegment{bestX, bestY + height, width}
+	segments = append(segments, skylineSegment{})
+	copy(segments[bestIndex+1:], segments[bestIndex:])
+	segments[bestIndex] = segment
+	for i := bestIndex + 1; i < len(segments); {
+		right := segment.x + segment.width
+		if segments[i].x >= right {
+			break
+		}
+		shrink := right - segments[i].x
+		segments[i].x += shrink
+		segments[i].width -= shrink
+		if segments[i].width > 0 {
+			break
+		}
+		segments = append(segments[:i], segments[i+1:]...)
+	}
+	// Merges the adjacent segments at the same height
+	for i := 0; i < len(segments)-1; {
+		if segments[i].y == segments[i+1].y {
+			segments[i].width += segments[i+1].width
+			segments = append(segments[:i+1], segments[i+2:]...)
+			continue
+		}
+		i++
+	}
+
+	*skyline = segments
+	return bestX, bestY, true
+}
+
+// fit checks if a rectangle fits with its left side at the start of the segment.
+// It returns the Y where it would be placed and the area wasted below it
+func (b *AtlasBuilder) fit(segments []skylineSegment, index int, width int, height int) (int, int, bool) {
+	x := segments[index].x
+	if x+width > b.maxWidth {
+		return 0, 0, false
+	}
+	y := 0
+	for i, remaining := index, width; remaining > 0; i++ {
+		if segments[i].y > y {
+			y = segments[i].y
+		}
+		remaining -= segments[i].width
+	}
+	if y+height > b.maxHeight {
+		return 0, 0, false
+	}
+	waste := 0
+	for i, remaining := index, width; remaining > 0; i++ {
+		covered := segments[i].width
+		if covered > remaining {
+			covered = remaining
+		}
+		waste += (y - segments[i].y) * covered
+		remaining -= segments[i].width
+	}
+	return y, waste, true
+}
+
+// Build packs the images and uploads the atlas to a texture. It returns a region for each image, by name
+func (b *AtlasBuilder) Build() (*Texture, map[string]*TextureRegion, error) {
+	layout, err := b.Pack()
+	if err != nil {
+		return nil, nil, err
+	}
+	texture := NewTextureFromImage(layout.Image)
+	if texture == nil {
+		return nil, nil, fmt.Errorf("atlas: unable to create the texture")
+	}
+	return texture, layout.Regions(texture), nil
+}
+
+// Regions creates a region for each packed image, by name, on the texture made from the layout image
+func (l *AtlasLayout) Regions(texture *Texture) map[string]*TextureRegion {
+	regions := make(map[string]*TextureRegion, len(l.Rects))
+	for name, rect := range l.Rects {
+		regions[name] = NewTextureRegion(texture, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+	return regions
+}
+
+// SpriteSheetJSON describes the layout as a TexturePacker sheet, in the array format read by ParseSpriteSheet.
+// imageName is the file the layout image is saved to, relative to the sheet. The frames are sorted by name
+func (l *AtlasLayout) SpriteSheetJSON(imageName string) ([]byte, error) {
+	names := make([]string, 0, len(l.Rects))
+	for name := range l.Rects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	frames := make([]jsonFrame, 0, len(names))
+	for _, name := range names {
+		rect := l.Rects[name]
+		size := jsonRect{W: rect.Dx(), H: rect.Dy()}
+		frames = append(frames, jsonFrame{
+			Filename:         name,
+			Frame:            jsonRect{X: rect.Min.X, Y: rect.Min.Y, W: rect.Dx(), H: rect.Dy()},
+			SpriteSourceSize: size,
+			SourceSize:       size,
+		})
+	}
+	sheet := struct {
+		Frames []jsonFrame `json:"frames"`
+		Meta   struct {
+			Image string   `json:"image"`
+			Size  jsonRect `json:"size"`
+		} `json:"meta"`
+	}{Frames: frames}
+	sheet.Meta.Image = imageName
+	sheet.Meta.Size = jsonRect{W: l.Image.Bounds().Dx(), H: l.Image.Bounds().Dy()}
+	return json.MarshalIndent(sheet, "", "  ")
+}
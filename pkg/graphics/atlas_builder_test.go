@@ -0,0 +1,149 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func newTestImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestAtlasBuilderPack(t *testing.T) {
+	sizes := map[string][2]int{
+		"hero": {32, 48}, "enemy": {32, 32}, "coin": {8, 8}, "tree": {64, 96},
+		"bush": {24, 16}, "cloud": {48, 20}, "sword": {10, 40}, "heart": {16, 14},
+	}
+	b := NewAtlasBuilder(128, 256, 2)
+	for name, size := range sizes {
+		b.Add(name, newTestImage(size[0], size[1], color.RGBA{uint8(size[0]), uint8(size[1]), 0, 255}))
+	}
+	if b.Len() != len(sizes) {
+		t.Errorf("Got %d images, expecting %d", b.Len(), len(sizes))
+	}
+
+	layout, err := b.Pack()
+	if err != nil {
+		t.Fatalf("Not expecting error %v", err)
+	}
+	if len(layout.Rects) != len(sizes) || len(layout.Names) != len(sizes) {
+		t.Fatalf("Got %d rects, expecting %d", len(layout.Rects), len(sizes))
+	}
+	bounds := layout.Image.Bounds()
+	if bounds.Dx() > 128 || bounds.Dy() > 256 {
+		t.Errorf("Atlas %v exceeds the max size", bounds)
+	}
+
+	for name, rect := range layout.Rects {
+		if rect.Dx() != sizes[name][0] || rect.Dy() != sizes[name][1] {
+			t.Errorf("%s: got size %dx%d, expecting %v", name, rect.Dx(), rect.Dy(), sizes[name])
+		}
+		if !rect.In(bounds) {
+			t.Errorf("%s: %v is outside the atlas %v", name, rect, bounds)
+		}
+		for otherName, other := range layout.Rects {
+			if name != otherName && rect.Inset(-1).Overlaps(other) {
+				t.Errorf("%s %v overlaps %s %v, or it's not padded", name, rect, otherName, other)
+			}
+		}
+		// The image has been copied in place
+		c := layout.Image.RGBAAt(rect.Min.X, rect.Min.Y)
+		if int(c.R) != sizes[name][0] || int(c.G) != sizes[name][1] {
+			t.Errorf("%s: wrong pixel %v in the atlas", name, c)
+		}
+	}
+
+	// The same images, added in a different order, give the same result
+	b2 := NewAtlasBuilder(128, 256, 2)
+	for i := len(layout.Names) - 1; i >= 0; i-- {
+		name := layout.Names[i]
+		b2.Add(name, newTestImage(sizes[name][0], sizes[name][1], color.RGBA{}))
+	}
+	layout2, _ := b2.Pack()
+	for name, rect := range layout.Rects {
+		if layout2.Rects[name] != rect {
+			t.Errorf("%s: packing is not deterministic, %v != %v", name, layout2.Rects[name], rect)
+		}
+	}
+}
+
+func TestAtlasBuilderErrors(t *testing.T) {
+	b := NewAtlasBuilder(64, 64, 0)
+	b.Add("big", newTestImage(65, 10, color.RGBA{}))
+	if _, err := b.Pack(); err == nil {
+		t.Errorf("Was expecting an error, none returned")
+	}
+
+	b = NewAtlasBuilder(64, 64, 0)
+	b.Add("one", newTestImage(10, 10, color.RGBA{}))
+	b.Add("one", newTestImage(10, 10, color.RGBA{}))
+	if _, err := b.Pack(); err == nil {
+		t.Errorf("Was expecting an error for duplicate names, none returned")
+	}
+
+	// Exactly fills the atlas
+	b = NewAtlasBuilder(64, 64, 0)
+	for i := 0; i < 4; i++ {
+		b.Add(string(rune('a'+i)), newTestImage(32, 32, color.RGBA{}))
+	}
+	if _, err := b.Pack(); err != nil {
+		t.Errorf("Not expecting error %v", err)
+	}
+}
+
+func TestTextureRegion(t *testing.T) {
+	texture := &Texture{width: 200, height: 100}
+	r := NewTextureRegion(texture, 50, 25, 100, 50)
+	u1, v1, u2, v2 := r.UV()
+	if u1 != 0.25 || v1 != 0.25 || u2 != 0.75 || v2 != 0.75 {
+		t.Errorf("Got UV %f,%f %f,%f, expecting 0.25,0.25 0.75,0.75", u1, v1, u2, v2)
+	}
+	if r.Rect() != image.Rect(50, 25, 150, 75) {
+		t.Errorf("Got rect %v", r.Rect())
+	}
+	expected := []float32{0.25, 0.25, 0.25, 0.75, 0.75, 0.75, 0.75, 0.25}
+	for i, uv := range r.UVCoords() {
+		if uv != expected[i] {
+			t.Errorf("UVCoords failed\nexpected\n%v received\n%v", expected, r.UVCoords())
+			break
+		}
+	}
+}
+
+func TestAtlasLayoutSpriteSheetJSON(t *testing.T) {
+	b := NewAtlasBuilder(64, 64, 1)
+	b.Add("walk/1", newTestImage(10, 20, color.RGBA{}))
+	b.Add("idle", newTestImage(16, 16, color.RGBA{}))
+	layout, err := b.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := layout.SpriteSheetJSON("hero.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := ParseSpriteSheet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Image != "hero.png" || len(sheet.Frames) != 2 {
+		t.Fatalf("unexpected sheet %+v", sheet)
+	}
+	// Sorted by name
+	for i, name := range []string{"idle", "walk/1"} {
+		f := sheet.Frames[i]
+		rect := layout.Rects[name]
+		received := image.Rect(f.X, f.Y, f.X+f.Width, f.Y+f.Height)
+		if f.Name != name || received != rect || f.SourceWidth != rect.Dx() || f.SourceHeight != rect.Dy() {
+			t.Errorf("expected\n%v %v received\n%v %v", name, rect, f.Name, received)
+		}
+	}
+}
@@ -10,6 +10,20 @@ import (
 const MinZoom float64 = 0.01
 const MaxZoom float64 = 20
 
+// Set with SetPixelPerfect, shared by all the cameras
+var pixelPerfect bool
+
+// SetPixelPerfect snaps the area visible by all the cameras to whole screen pixels, so that what is drawn at
+// integer positions never falls between two pixels. Use it with PixelArtTextureOptions for pixel art
+func SetPixelPerfect(enabled bool) {
+	pixelPerfect = enabled
+}
+
+// PixelPerfect tells if the cameras are snapped to whole screen pixels
+func PixelPerfect() bool {
+	return pixelPerfect
+}
+
 // Camera2D a Camera based on an orthogonal projection
 type Camera2D struct {
 	x                  float64
@@ -27,6 +41,11 @@ type Camera2D struct {
 	inverseMatrix      mgl64.Mat4
 	projectionMatrix32 mgl32.Mat4
 	matrixDirty        bool
+	// Value of pixelPerfect when the matrix has been built
+	snapped bool
+	// World area covered by the camera
+	visibleMin mgl64.Vec2
+	visibleMax mgl64.Vec2
 }
 
 // NewCamera2D sets up an orthogonal projection camera
@@ -115,8 +134,14 @@ func (c *Camera2D) SetVisibleArea(x1 float32, y1 float32, x2 float32, y2 float32
 	}
 }
 
+// VisibleArea returns the top left and bottom right corners of the world area covered by the camera
+func (c *Camera2D) VisibleArea() (mgl64.Vec2, mgl64.Vec2) {
+	c.rebuildMatrix()
+	return c.visibleMin, c.visibleMax
+}
+
 func (c *Camera2D) rebuildMatrix() {
-	if !c.matrixDirty {
+	if !c.matrixDirty && c.snapped == pixelPerfect {
 		return
 	}
 	var left, right, top, bottom float64
@@ -137,6 +162,17 @@ func (c *Camera2D) rebuildMatrix() {
 	right += c.x
 	top += c.y
 	bottom += c.y
+	if pixelPerfect {
+		// The position stays the same, only the projection is moved
+		dx := math.Round(left*c.zoom)/c.zoom - left
+		dy := math.Round(bottom*c.zoom)/c.zoom - bottom
+		left += dx
+		right += dx
+		top += dy
+		bottom += dy
+	}
+	c.visibleMin = mgl64.Vec2{left, math.Min(top, bottom)}
+	c.visibleMax = mgl64.Vec2{right, math.Max(top, bottom)}
 
 	if c.flipVertical {
 		bottom, top = top, bottom
@@ -147,6 +183,7 @@ func (c *Camera2D) rebuildMatrix() {
 	// updates the float32 version
 	c.projectionMatrix32 = utils.Mat4From64to32Bits(c.projectionMatrix)
 	c.matrixDirty = false
+	c.snapped = pixelPerfect
 }
 
 func (c *Camera2D) ScreenToWorld(vec mgl64.Vec2) mgl64.Vec3 {
@@ -123,3 +123,51 @@ func TestCamera2DCoordsConversion(t *testing.T) {
 	}
 
 }
+
+func TestCamera2DVisibleArea(t *testing.T) {
+	var tests = []struct {
+		centered bool
+		flip     bool
+		min, max mgl64.Vec2
+	}{
+		{false, false, mgl64.Vec2{10, 20}, mgl64.Vec2{60, 70}},
+		{true, false, mgl64.Vec2{-15, -5}, mgl64.Vec2{35, 45}},
+		{true, true, mgl64.Vec2{-15, -5}, mgl64.Vec2{35, 45}},
+	}
+
+	for _, test := range tests {
+		c := NewCamera2D(100, 100, 2)
+		c.SetCentered(test.centered)
+		c.SetFlipVertical(test.flip)
+		c.SetPosition(10, 20)
+		min, max := c.VisibleArea()
+		if !min.ApproxEqual(test.min) || !max.ApproxEqual(test.max) {
+			t.Errorf("Centered %v, flipped %v: expected\n%v %v received\n%v %v", test.centered, test.flip, test.min, test.max, min, max)
+		}
+	}
+}
+
+func TestCamera2DPixelPerfect(t *testing.T) {
+	defer SetPixelPerfect(false)
+
+	c := NewCamera2D(100, 100, 2)
+	c.SetPosition(10.3, 4.6)
+	if min, _ := c.VisibleArea(); !min.ApproxEqual(mgl64.Vec2{10.3, 4.6}) {
+		t.Errorf("expected\n%v received\n%v", mgl64.Vec2{10.3, 4.6}, min)
+	}
+
+	// Enabling the mode rebuilds the matrices of the existing cameras
+	SetPixelPerfect(true)
+	min, max := c.VisibleArea()
+	if !min.ApproxEqual(mgl64.Vec2{10.5, 4.5}) || !max.ApproxEqual(mgl64.Vec2{60.5, 54.5}) {
+		t.Errorf("expected\n%v %v received\n%v %v", mgl64.Vec2{10.5, 4.5}, mgl64.Vec2{60.5, 54.5}, min, max)
+	}
+
+	// Centered on an odd size the edges are still on whole pixels
+	c = NewCamera2D(101, 100, 1)
+	c.SetCentered(true)
+	c.SetPosition(0, 0)
+	if min, _ := c.VisibleArea(); !min.ApproxEqual(mgl64.Vec2{-50, -50}) && !min.ApproxEqual(mgl64.Vec2{-51, -50}) {
+		t.Errorf("expected the left edge on a whole pixel, received %v", min)
+	}
+}
@@ -0,0 +1,89 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ColoredVertexSize number of floats per vertex of a ColoredMesh: X,Y,Z,U,V,R,G,B,A
+const ColoredVertexSize = 9
+
+// ColoredMesh a dynamic list of triangles having a color per vertex. The vertices are replaced every frame,
+// so many small shapes (e.g. particles) can be drawn with a single draw call
+type ColoredMesh struct {
+	vaoId       uint32
+	vboId       uint32
+	maxVertices int
+	numVertices int
+}
+
+// NewColoredMesh creates a mesh able to hold up to maxVertices vertices
+func NewColoredMesh(maxVertices int) *ColoredMesh {
+	m := &ColoredMesh{maxVertices: maxVertices}
+
+	stride := int32(ColoredVertexSize * Float32Size)
+	gl.GenVertexArrays(1, &m.vaoId)
+	gl.BindVertexArray(m.vaoId)
+	gl.GenBuffers(1, &m.vboId)
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.vboId)
+	gl.BufferData(gl.ARRAY_BUFFER, maxVertices*ColoredVertexSize*Float32Size, nil, gl.DYNAMIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, gl.PtrOffset(3*Float32Size))
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, stride, gl.PtrOffset(5*Float32Size))
+	gl.BindVertexArray(0)
+	return m
+}
+
+// SetVertices uploads the vertices, ColoredVertexSize floats each. The vertices exceeding the capacity are ignored
+func (m *ColoredMesh) SetVertices(vertices []float32) {
+	if len(vertices) > m.maxVertices*ColoredVertexSize {
+		vertices = vertices[:m.maxVertices*ColoredVertexSize]
+	}
+	m.numVertices = len(vertices) / ColoredVertexSize
+	if m.numVertices == 0 {
+		return
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, m.vboId)
+	// Orphans the previous storage so the driver doesn't have to wait for the last draw call
+	gl.BufferData(gl.ARRAY_BUFFER, m.maxVertices*ColoredVertexSize*Float32Size, nil, gl.DYNAMIC_DRAW)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, m.numVertices*ColoredVertexSize*Float32Size, gl.Ptr(vertices))
+}
+
+// NumVertices returns the number of vertices uploaded with the last SetVertices
+func (m *ColoredMesh) NumVertices() int {
+	return m.numVertices
+}
+
+// MaxVertices returns the capacity of the mesh
+func (m *ColoredMesh) MaxVertices() int {
+	return m.maxVertices
+}
+
+// Draw draws the triangles with one draw call. The texture can be nil if the shader doesn't use it
+func (m *ColoredMesh) Draw(context *Context, texture *Texture, shader *ShaderProgram, model *mgl32.Mat4) {
+	if m.numVertices == 0 {
+		return
+	}
+	// Whatever has been batched so far has to be drawn below the mesh
+	context.FlushBatch()
+	context.BindShader(shader)
+	context.BindTexture(texture)
+	context.ApplyProjection(shader)
+	shader.LogUniformError(shader.SetUniform("model", model))
+
+	gl.BindVertexArray(m.vaoId)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(m.numVertices))
+	gl.BindVertexArray(0)
+	context.drawCalls++
+}
+
+// Release releases the OpenGL buffers used by the mesh
+func (m *ColoredMesh) Release() {
+	gl.DeleteBuffers(1, &m.vboId)
+	gl.DeleteVertexArrays(1, &m.vaoId)
+	m.vboId = 0
+	m.vaoId = 0
+}
@@ -15,28 +15,125 @@ type Drawable interface {
 
 // Context ...
 type Context struct {
-	Camera2D             *Camera2D
-	viewMatrix           mgl64.Mat4
-	currentTexture       *Texture
-	currentShaderProgram *ShaderProgram
+	Camera2D       *Camera2D
+	viewMatrix     mgl64.Mat4
+	currentTexture *Texture
+	batch          *SpriteBatch
+	drawCalls      int
+	// Binds actually changing the GL state
+	shaderSwitches  int
+	textureSwitches int
+	renderTargets   []renderTargetState
 }
 
-// BindTexture sets texture to be current texture if it isn't already
+// Batch returns the SpriteBatch currently active on this context, nil if there is none
+func (c *Context) Batch() *SpriteBatch {
+	return c.batch
+}
+
+// DrawCalls returns the number of draw calls issued on this context since the last ResetDrawCalls
+func (c *Context) DrawCalls() int {
+	return c.drawCalls
+}
+
+// ShaderSwitches returns the number of times BindShader changed the program in use since the last ResetDrawCalls
+func (c *Context) ShaderSwitches() int {
+	return c.shaderSwitches
+}
+
+// TextureSwitches returns the number of times BindTexture changed the texture of this context since the last
+// ResetDrawCalls
+func (c *Context) TextureSwitches() int {
+	return c.textureSwitches
+}
+
+// ResetDrawCalls resets the draw calls and the switches counters
+func (c *Context) ResetDrawCalls() {
+	c.drawCalls = 0
+	c.shaderSwitches = 0
+	c.textureSwitches = 0
+}
+
+// FlushBatch draws whatever the active SpriteBatch has collected so far
+func (c *Context) FlushBatch() {
+	if c.batch != nil {
+		c.batch.Flush()
+	}
+}
+
+// The batch with vertices not drawn yet. It's flushed as soon as another context draws, so that everything is drawn
+// in the order it has been issued, whatever the context
+var pendingBatch *SpriteBatch
+
+// flushOtherContexts draws the vertices batched on the other contexts, before this one changes the GL state
+func (c *Context) flushOtherContexts() {
+	if batch := c.otherPendingBatch(); batch != nil {
+		batch.Flush()
+	}
+}
+
+func (c *Context) otherPendingBatch() *SpriteBatch {
+	if pendingBatch == nil || pendingBatch.context == nil || pendingBatch.context == c {
+		return nil
+	}
+	return pendingBatch
+}
+
+// Blending currently set for the textures with premultiplied alpha, the state is shared by all the contexts
+var premultipliedBlend bool
+
+// BindTexture sets texture to be current texture if it isn't already.
+// The blending function follows the alpha of the texture, premultiplied or not. The vertices batched on the other
+// contexts are drawn first, so that the drawing order is kept
 func (c *Context) BindTexture(texture *Texture) {
+	c.flushOtherContexts()
+	if texture != c.currentTexture {
+		c.textureSwitches++
+	}
 	if texture == nil {
 		gl.BindTexture(gl.TEXTURE_2D, 0)
 		c.currentTexture = nil
+		setPremultipliedBlend(false)
 		return
 	}
 
 	gl.BindTexture(gl.TEXTURE_2D, texture.id)
 	c.currentTexture = texture
+	setPremultipliedBlend(texture.options.PremultipliedAlpha)
+}
+
+func setPremultipliedBlend(premultiplied bool) {
+	if premultiplied == premultipliedBlend {
+		return
+	}
+	if premultiplied {
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+	} else {
+		gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	}
+	premultipliedBlend = premultiplied
 }
 
-// BindShader sets shader to be current shader if it isn't already
+// Program currently in use, the state is shared by all the contexts
+var boundProgramID uint32
+
+// Replaced by the tests
+var useProgram = gl.UseProgram
+
+// BindShader sets shader to be current shader if it isn't already.
+// The vertices batched on the other contexts are drawn first, like for BindTexture
 func (c *Context) BindShader(shader *ShaderProgram) {
-	if c.currentShaderProgram == nil || shader.id != c.currentShaderProgram.id {
-		gl.UseProgram(shader.id)
-		c.currentShaderProgram = shader
+	c.flushOtherContexts()
+	if shader.id != boundProgramID {
+		useProgram(shader.id)
+		boundProgramID = shader.id
+		c.shaderSwitches++
+	}
+}
+
+// forgetProgram is called when a program is deleted, GL can give its id to a new program which must be bound again
+func forgetProgram(programID uint32) {
+	if programID == boundProgramID {
+		boundProgramID = 0
 	}
 }
@@ -0,0 +1,96 @@
+package graphics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// stubUseProgram records the programs put in use, until the returned function is called
+func stubUseProgram(used *[]uint32) func() {
+	previous, previousBound := useProgram, boundProgramID
+	useProgram = func(id uint32) {
+		*used = append(*used, id)
+	}
+	boundProgramID = 0
+	return func() {
+		useProgram, boundProgramID = previous, previousBound
+	}
+}
+
+func TestBindShaderSharedByContexts(t *testing.T) {
+	var used []uint32
+	defer stubUseProgram(&used)()
+
+	world, ui := &Context{}, &Context{}
+	a, b := &ShaderProgram{id: 1}, &ShaderProgram{id: 2}
+	world.BindShader(a)
+	world.BindShader(a)
+	// The other context changes the program of the GL state both contexts draw with
+	ui.BindShader(b)
+	world.BindShader(a)
+
+	expected := []uint32{1, 2, 1}
+	if !reflect.DeepEqual(used, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, used)
+	}
+	// Only the binds changing the program are counted
+	if world.ShaderSwitches() != 2 || ui.ShaderSwitches() != 1 {
+		t.Errorf("expected\n%v %v received\n%v %v", 2, 1, world.ShaderSwitches(), ui.ShaderSwitches())
+	}
+}
+
+func TestBindShaderAfterReload(t *testing.T) {
+	var used []uint32
+	defer stubUseProgram(&used)()
+
+	c := &Context{}
+	program := &ShaderProgram{id: 1}
+	c.BindShader(program)
+	// A reload builds a new program and deletes the old one, like reloadShaderProgram
+	program.id = 2
+	forgetProgram(1)
+	c.BindShader(program)
+	// The deleted id is given to another program
+	forgetProgram(2)
+	c.BindShader(&ShaderProgram{id: 2})
+
+	expected := []uint32{1, 2, 2}
+	if !reflect.DeepEqual(used, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, used)
+	}
+}
+
+func TestProjectionFlippedOnRenderTargets(t *testing.T) {
+	c := &Context{Camera2D: NewCamera2D(100, 50, 1)}
+	top := mgl32.Vec4{10, 0, 0, 1}
+	if y := c.projection().Mul4x1(top).Y(); y != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, y)
+	}
+	// The top of the camera goes to the first row of the texture, at the bottom of the framebuffer
+	c.renderTargets = append(c.renderTargets, renderTargetState{target: &RenderTarget{}})
+	if y := c.projection().Mul4x1(top).Y(); y != -1 {
+		t.Errorf("expected\n%v received\n%v", -1, y)
+	}
+	c.renderTargets[0].target.bottomUp = true
+	if y := c.projection().Mul4x1(top).Y(); y != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, y)
+	}
+}
+
+func TestBatchFlushedByOtherContexts(t *testing.T) {
+	defer func(previous *SpriteBatch) { pendingBatch = previous }(pendingBatch)
+
+	world, ui := &Context{}, &Context{}
+	batch := &SpriteBatch{}
+	batch.Begin(world)
+	pendingBatch = batch
+	// The world quads queued so far must be drawn before the UI, drawing on the world keeps batching
+	if world.otherPendingBatch() != nil {
+		t.Errorf("The context of the batch should not flush it")
+	}
+	if ui.otherPendingBatch() != batch {
+		t.Errorf("expected\n%v received\n%v", batch, ui.otherPendingBatch())
+	}
+}
@@ -0,0 +1,324 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/utils"
+)
+
+// Default sizes of the debug shapes, in screen pixels
+const (
+	DefaultDebugLineWidth = 1.5
+	DefaultDebugPointSize = 5
+	DefaultDebugTextSize  = 8
+)
+
+// Number of segments of the debug circles
+const debugCircleSegments = 24
+
+// Initial capacity, in vertices, of the debug mesh. It grows when needed
+const debugInitialVertices = 4096
+
+// Shared debug draws, flushed by the app after the frame has been rendered
+var (
+	// DebugWorld draws in world coordinates, on top of the frame
+	DebugWorld = NewDebugDraw()
+	// DebugUI draws in screen coordinates, on top of everything else
+	DebugUI = NewDebugDraw()
+)
+
+type debugLine struct {
+	a, b  mgl64.Vec2
+	color Color
+}
+
+type debugPoint struct {
+	p     mgl64.Vec2
+	color Color
+}
+
+type debugText struct {
+	position mgl64.Vec2
+	text     string
+	color    Color
+}
+
+// debugRetained counts the shapes of each kind kept by Flush
+type debugRetained struct {
+	lines, points, texts, triangles int
+}
+
+// DebugDraw collects shapes to draw for a single frame: calls can be issued from anywhere, e.g. during update.
+// Everything collected is drawn by Flush with a single draw call. Line widths, point and text sizes are in
+// screen pixels whatever the camera zoom, all the rest is in the coordinates of the context the shapes are flushed to
+type DebugDraw struct {
+	enabled   bool
+	z         float64
+	lineWidth float64
+	pointSize float64
+	textSize  float64
+	lines     []debugLine
+	points    []debugPoint
+	texts     []debugText
+	// Vertices of the filled shapes, ColoredVertexSize floats each
+	triangles []float32
+	// Shapes kept by Flush, see Retain
+	retained debugRetained
+
+	vertices []float32
+	mesh     *ColoredMesh
+	shader   *ShaderProgram
+}
+
+// NewDebugDraw creates a debug draw. The OpenGL resources are created on the first Flush
+func NewDebugDraw() *DebugDraw {
+	return &DebugDraw{
+		enabled:   true,
+		z:         -1,
+		lineWidth: DefaultDebugLineWidth,
+		pointSize: DefaultDebugPointSize,
+		textSize:  DefaultDebugTextSize,
+	}
+}
+
+// SetEnabled enables or disables the debug draw, when disabled all the calls are ignored
+func (d *DebugDraw) SetEnabled(enabled bool) {
+	d.enabled = enabled
+	if !enabled {
+		d.Clear()
+	}
+}
+
+// Enabled tells if the debug draw is enabled
+func (d *DebugDraw) Enabled() bool {
+	return d.enabled
+}
+
+// SetZ sets the Z of the shapes, used for the drawing order
+func (d *DebugDraw) SetZ(z float64) {
+	d.z = z
+}
+
+// SetLineWidth sets the width of the lines, in pixels
+func (d *DebugDraw) SetLineWidth(width float64) {
+	d.lineWidth = width
+}
+
+// SetPointSize sets the size of the points, in pixels
+func (d *DebugDraw) SetPointSize(size float64) {
+	d.pointSize = size
+}
+
+// SetTextSize sets the height of the characters, in pixels
+func (d *DebugDraw) SetTextSize(size float64) {
+	d.textSize = size
+}
+
+// Line draws a segment
+func (d *DebugDraw) Line(a mgl64.Vec2, b mgl64.Vec2, color Color) {
+	if !d.enabled {
+		return
+	}
+	d.lines = append(d.lines, debugLine{a, b, color})
+}
+
+// Polyline draws a sequence of segments
+func (d *DebugDraw) Polyline(points []mgl64.Vec2, closed bool, color Color) {
+	for i := 1; i < len(points); i++ {
+		d.Line(points[i-1], points[i], color)
+	}
+	if closed && len(points) > 2 {
+		d.Line(points[len(points)-1], points[0], color)
+	}
+}
+
+// Rect draws the outline of a rectangle
+func (d *DebugDraw) Rect(topLeft mgl64.Vec2, size mgl64.Vec2, color Color) {
+	d.Polyline(rectCorners(topLeft, size), true, color)
+}
+
+// FillRect draws a filled rectangle
+func (d *DebugDraw) FillRect(topLeft mgl64.Vec2, size mgl64.Vec2, color Color) {
+	if !d.enabled {
+		return
+	}
+	c := rectCorners(topLeft, size)
+	d.triangles = appendColoredTriangle(d.triangles, c[0], c[1], c[2], d.z, color)
+	d.triangles = appendColoredTriangle(d.triangles, c[0], c[2], c[3], d.z, color)
+}
+
+// Circle draws the outline of a circle
+func (d *DebugDraw) Circle(center mgl64.Vec2, radius float64, color Color) {
+	points, err := utils.CircleToPolygon(center, radius, debugCircleSegments, 0)
+	if err != nil {
+		return
+	}
+	d.Polyline(points, true, color)
+}
+
+// FillCircle draws a filled circle
+func (d *DebugDraw) FillCircle(center mgl64.Vec2, radius float64, color Color) {
+	if !d.enabled {
+		return
+	}
+	points, err := utils.CircleToPolygon(center, radius, debugCircleSegments, 0)
+	if err != nil {
+		return
+	}
+	for i := range points {
+		d.triangles = appendColoredTriangle(d.triangles, center, points[i], points[(i+1)%len(points)], d.z, color)
+	}
+}
+
+// Arrow draws a segment with an arrow head at its end, the head is headSize long
+func (d *DebugDraw) Arrow(from mgl64.Vec2, to mgl64.Vec2, headSize float64, color Color) {
+	d.Line(from, to, color)
+	direction := to.Sub(from)
+	if direction.Len() == 0 {
+		return
+	}
+	back := direction.Normalize().Mul(-headSize)
+	d.Line(to, to.Add(mgl64.Rotate2D(math.Pi/6).Mul2x1(back)), color)
+	d.Line(to, to.Add(mgl64.Rotate2D(-math.Pi/6).Mul2x1(back)), color)
+}
+
+// Point draws a filled square, its size is in pixels
+func (d *DebugDraw) Point(p mgl64.Vec2, color Color) {
+	if !d.enabled {
+		return
+	}
+	d.points = append(d.points, debugPoint{p, color})
+}
+
+// Cross draws an X centered on p, size is its width
+func (d *DebugDraw) Cross(p mgl64.Vec2, size float64, color Color) {
+	h := size / 2
+	d.Line(p.Add(mgl64.Vec2{-h, -h}), p.Add(mgl64.Vec2{h, h}), color)
+	d.Line(p.Add(mgl64.Vec2{-h, h}), p.Add(mgl64.Vec2{h, -h}), color)
+}
+
+// Text draws a text with its top left corner at position. Only ASCII letters, digits and some punctuation are supported
+func (d *DebugDraw) Text(position mgl64.Vec2, text string, color Color) {
+	if !d.enabled {
+		return
+	}
+	d.texts = append(d.texts, debugText{position, text, color})
+}
+
+// Clear removes everything collected so far, the retained shapes included
+func (d *DebugDraw) Clear() {
+	d.retained = debugRetained{}
+	d.truncate()
+}
+
+// Retain keeps the shapes collected so far after the next flushes, until Clear. The shapes collected afterwards are
+// still removed by Flush. app.MainLoopFixed uses it to keep drawing the shapes collected during update on the frames
+// without update steps
+func (d *DebugDraw) Retain() {
+	d.retained = debugRetained{len(d.lines), len(d.points), len(d.texts), len(d.triangles)}
+}
+
+// truncate removes the shapes not retained
+func (d *DebugDraw) truncate() {
+	d.lines = d.lines[:d.retained.lines]
+	d.points = d.points[:d.retained.points]
+	d.texts = d.texts[:d.retained.texts]
+	d.triangles = d.triangles[:d.retained.triangles]
+}
+
+// Empty tells if nothing has been collected
+func (d *DebugDraw) Empty() bool {
+	return len(d.lines) == 0 && len(d.points) == 0 && len(d.texts) == 0 && len(d.triangles) == 0
+}
+
+// Flush draws everything collected on the context and removes it, except the shapes retained
+func (d *DebugDraw) Flush(context *Context) {
+	if d.Empty() {
+		return
+	}
+	camera := context.Camera2D
+	d.vertices = d.buildVertices(d.vertices[:0], 1/camera.Zoom(), camera.flipVertical)
+	d.truncate()
+
+	numVertices := len(d.vertices) / ColoredVertexSize
+	if d.mesh == nil || d.mesh.MaxVertices() < numVertices {
+		capacity := debugInitialVertices
+		for capacity < numVertices {
+			capacity *= 2
+		}
+		if d.mesh != nil {
+			d.mesh.Release()
+		}
+		d.mesh = NewColoredMesh(capacity)
+	}
+	if d.shader == nil {
+		d.shader = DefaultShaderCache.builtin(VertexShaderColor, "", FragmentShaderVertexColor)
+	}
+	d.mesh.SetVertices(d.vertices)
+	identity := mgl32.Ident4()
+	d.mesh.Draw(context, nil, d.shader, &identity)
+}
+
+// buildVertices turns everything collected into triangles. pixelSize is the size of a screen pixel in the
+// coordinates of the shapes, with flipY the text is mirrored to stay readable when the Y axis points up
+func (d *DebugDraw) buildVertices(dst []float32, pixelSize float64, flipY bool) []float32 {
+	dst = append(dst, d.triangles...)
+
+	halfWidth := d.lineWidth / 2 * pixelSize
+	for _, l := range d.lines {
+		dst = appendColoredLine(dst, l.a, l.b, halfWidth, d.z, l.color)
+	}
+
+	h := d.pointSize / 2 * pixelSize
+	for _, p := range d.points {
+		c := rectCorners(p.p.Sub(mgl64.Vec2{h, h}), mgl64.Vec2{2 * h, 2 * h})
+		dst = appendColoredTriangle(dst, c[0], c[1], c[2], d.z, p.color)
+		dst = appendColoredTriangle(dst, c[0], c[2], c[3], d.z, p.color)
+	}
+
+	size := d.textSize * pixelSize
+	for _, t := range d.texts {
+		segments := debugTextSegments(t.text, size)
+		for i := 0; i < len(segments); i += 2 {
+			a, b := segments[i], segments[i+1]
+			if flipY {
+				a[1], b[1] = -a[1], -b[1]
+			}
+			dst = appendColoredLine(dst, t.position.Add(a), t.position.Add(b), halfWidth, d.z, t.color)
+		}
+	}
+	return dst
+}
+
+func rectCorners(topLeft mgl64.Vec2, size mgl64.Vec2) []mgl64.Vec2 {
+	return []mgl64.Vec2{
+		topLeft,
+		{topLeft.X(), topLeft.Y() + size.Y()},
+		topLeft.Add(size),
+		{topLeft.X() + size.X(), topLeft.Y()},
+	}
+}
+
+// appendColoredLine appends a segment as a quad, extended by half its width at both ends so that the corners of
+// consecutive segments are closed
+func appendColoredLine(dst []float32, a mgl64.Vec2, b mgl64.Vec2, halfWidth float64, z float64, color Color) []float32 {
+	direction := b.Sub(a)
+	if direction.Len() == 0 {
+		direction = mgl64.Vec2{1, 0}
+	}
+	direction = direction.Normalize().Mul(halfWidth)
+	normal := mgl64.Vec2{-direction.Y(), direction.X()}
+	a = a.Sub(direction)
+	b = b.Add(direction)
+	dst = appendColoredTriangle(dst, a.Add(normal), a.Sub(normal), b.Sub(normal), z, color)
+	return appendColoredTriangle(dst, a.Add(normal), b.Sub(normal), b.Add(normal), z, color)
+}
+
+func appendColoredTriangle(dst []float32, a mgl64.Vec2, b mgl64.Vec2, c mgl64.Vec2, z float64, color Color) []float32 {
+	for _, p := range [3]mgl64.Vec2{a, b, c} {
+		dst = append(dst, float32(p.X()), float32(p.Y()), float32(z), 0, 0, color[0], color[1], color[2], color[3])
+	}
+	return dst
+}
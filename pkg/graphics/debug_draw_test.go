@@ -0,0 +1,116 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestDebugDrawVertices(t *testing.T) {
+	red := Color{1, 0, 0, 1}
+	d := NewDebugDraw()
+	d.SetZ(0)
+	d.SetLineWidth(2)
+	d.Line(mgl64.Vec2{0, 0}, mgl64.Vec2{10, 0}, red)
+	d.Rect(mgl64.Vec2{0, 0}, mgl64.Vec2{10, 10}, red)
+	d.FillRect(mgl64.Vec2{0, 0}, mgl64.Vec2{10, 10}, red)
+	d.Point(mgl64.Vec2{5, 5}, red)
+	d.Cross(mgl64.Vec2{5, 5}, 4, red)
+	d.Arrow(mgl64.Vec2{0, 0}, mgl64.Vec2{0, 10}, 2, red)
+
+	// 1 + 4 + 2 + 3 lines, 2 triangles for the rect and 2 for the point
+	expected := (10*2 + 2 + 2) * 3
+	vertices := d.buildVertices(nil, 1, false)
+	if len(vertices) != expected*ColoredVertexSize {
+		t.Fatalf("expected\n%v received\n%v", expected, len(vertices)/ColoredVertexSize)
+	}
+
+	// The filled rect comes first, then the first line
+	line := vertices[6*ColoredVertexSize:]
+	expectedLine := []float32{-1, 1, 0, 0, 0, 1, 0, 0, 1}
+	for i := range expectedLine {
+		if line[i] != expectedLine[i] {
+			t.Errorf("expected\n%v received\n%v", expectedLine, line[:ColoredVertexSize])
+			break
+		}
+	}
+
+	// Zooming in makes the lines thinner in world coordinates
+	vertices = d.buildVertices(nil, 0.5, false)
+	line = vertices[6*ColoredVertexSize:]
+	if line[0] != -0.5 || line[1] != 0.5 {
+		t.Errorf("expected\n%v received\n%v", []float32{-0.5, 0.5}, line[:2])
+	}
+
+	d.Clear()
+	if !d.Empty() {
+		t.Errorf("Clear should remove everything")
+	}
+	d.SetEnabled(false)
+	d.Circle(mgl64.Vec2{0, 0}, 5, red)
+	d.Text(mgl64.Vec2{0, 0}, "ignored", red)
+	if !d.Empty() {
+		t.Errorf("A disabled debug draw should ignore the calls")
+	}
+}
+
+func TestDebugDrawRetain(t *testing.T) {
+	d := NewDebugDraw()
+	red := Color{1, 0, 0, 1}
+	// Collected during update
+	d.Line(mgl64.Vec2{0, 0}, mgl64.Vec2{1, 0}, red)
+	d.FillRect(mgl64.Vec2{0, 0}, mgl64.Vec2{1, 1}, red)
+	d.Retain()
+	// Collected during render, removed by Flush
+	d.Point(mgl64.Vec2{0, 0}, red)
+	d.Line(mgl64.Vec2{0, 0}, mgl64.Vec2{0, 1}, red)
+	d.truncate()
+
+	if len(d.lines) != 1 || len(d.points) != 0 || len(d.triangles) != 6*ColoredVertexSize {
+		t.Errorf("expected\n%v received\n%v %v %v", "1 0 54", len(d.lines), len(d.points), len(d.triangles))
+	}
+	d.Clear()
+	d.Line(mgl64.Vec2{0, 0}, mgl64.Vec2{1, 0}, red)
+	d.truncate()
+	if !d.Empty() {
+		t.Errorf("Clear should drop the retained shapes")
+	}
+}
+
+func TestDebugTextSegments(t *testing.T) {
+	tests := []struct {
+		text        string
+		numSegments int
+		max         mgl64.Vec2
+	}{
+		{"", 0, mgl64.Vec2{}},
+		{"L", 4, mgl64.Vec2{10, 10}},
+		{"l", 4, mgl64.Vec2{10, 10}},
+		{"1 1", 6, mgl64.Vec2{40, 10}},
+		{"-\n-", 4, mgl64.Vec2{10, 23}},
+		{"~", 0, mgl64.Vec2{}},
+	}
+	for _, test := range tests {
+		segments := debugTextSegments(test.text, 10)
+		if len(segments) != test.numSegments*2 {
+			t.Errorf("%q expected\n%v received\n%v", test.text, test.numSegments, len(segments)/2)
+			continue
+		}
+		max := mgl64.Vec2{}
+		for _, p := range segments {
+			max = mgl64.Vec2{math.Max(max.X(), p.X()), math.Max(max.Y(), p.Y())}
+		}
+		if !max.ApproxEqual(test.max) {
+			t.Errorf("%q bounds expected\n%v received\n%v", test.text, test.max, max)
+		}
+	}
+
+	for char, glyph := range debugFontGlyphs {
+		for _, s := range glyph {
+			if _, found := debugFontSegments[byte(s)]; !found {
+				t.Errorf("Glyph %q uses the unknown segment %q", char, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package graphics
+
+import "github.com/go-gl/mathgl/mgl64"
+
+// A minimal stroke font for the debug text, every glyph is a set of segments of a cell 1 unit wide and 1 unit tall
+// (Y pointing down). It needs no texture, so the text is drawn together with the other debug shapes
+
+// Segments of the glyphs, by letter
+var debugFontSegments = map[byte][2]mgl64.Vec2{
+	'a': {{0, 0}, {0.5, 0}},       // top, left half
+	'b': {{0.5, 0}, {1, 0}},       // top, right half
+	'c': {{1, 0}, {1, 0.5}},       // right, upper half
+	'd': {{1, 0.5}, {1, 1}},       // right, lower half
+	'e': {{0.5, 1}, {1, 1}},       // bottom, right half
+	'f': {{0, 1}, {0.5, 1}},       // bottom, left half
+	'g': {{0, 0.5}, {0, 1}},       // left, lower half
+	'h': {{0, 0}, {0, 0.5}},       // left, upper half
+	'k': {{0, 0}, {0.5, 0.5}},     // diagonal from the top left corner to the center
+	'm': {{0.5, 0}, {0.5, 0.5}},   // vertical, upper half
+	'n': {{1, 0}, {0.5, 0.5}},     // diagonal from the top right corner to the center
+	'p': {{0, 0.5}, {0.5, 0.5}},   // middle, left half
+	'r': {{0.5, 0.5}, {1, 0.5}},   // middle, right half
+	's': {{0.5, 0.5}, {0, 1}},     // diagonal from the center to the bottom left corner
+	't': {{0.5, 0.5}, {0.5, 1}},   // vertical, lower half
+	'u': {{0.5, 0.5}, {1, 1}},     // diagonal from the center to the bottom right corner
+	'v': {{0, 0.5}, {0.5, 1}},     // diagonal from the left side to the bottom center
+	'w': {{1, 0.5}, {0.5, 1}},     // diagonal from the right side to the bottom center
+	'y': {{0.5, 0.2}, {0.5, 0.3}}, // upper dot
+	'z': {{0.5, 0.9}, {0.5, 1}},   // lower dot
+}
+
+// Glyphs as lists of segments, lowercase letters are drawn as uppercase ones
+var debugFontGlyphs = map[byte]string{
+	'0':  "abcdefghns",
+	'1':  "cdn",
+	'2':  "abcrpgfe",
+	'3':  "abcdefr",
+	'4':  "hprcd",
+	'5':  "abhprdef",
+	'6':  "abhgfedpr",
+	'7':  "abcd",
+	'8':  "abcdefghpr",
+	'9':  "abcdefhpr",
+	'A':  "abcdghpr",
+	'B':  "abcdefmtr",
+	'C':  "abhgfe",
+	'D':  "abcdefmt",
+	'E':  "abhgfep",
+	'F':  "abhgp",
+	'G':  "abhgfedr",
+	'H':  "hgcdpr",
+	'I':  "abmtef",
+	'J':  "cdefg",
+	'K':  "hgpnu",
+	'L':  "hgfe",
+	'M':  "hgcdkn",
+	'N':  "hgcdku",
+	'O':  "abcdefgh",
+	'P':  "abhgcpr",
+	'Q':  "abcdefghu",
+	'R':  "abhgcpru",
+	'S':  "abhprdef",
+	'T':  "abmt",
+	'U':  "hgfedc",
+	'V':  "hvcw",
+	'W':  "hgcdsu",
+	'X':  "knsu",
+	'Y':  "knt",
+	'Z':  "abnsfe",
+	'-':  "pr",
+	'+':  "prmt",
+	'=':  "prfe",
+	'_':  "fe",
+	'*':  "knsumt",
+	'/':  "ns",
+	'\\': "ku",
+	'<':  "nu",
+	'>':  "ks",
+	'(':  "nu",
+	')':  "ks",
+	'[':  "afgh",
+	']':  "bcde",
+	'.':  "z",
+	',':  "s",
+	':':  "yz",
+	'!':  "mz",
+	'?':  "abcrz",
+	'\'': "m",
+	'"':  "hc",
+	'%':  "nsyz",
+}
+
+// Horizontal distance between two glyphs and vertical distance between two lines, relative to the glyph size
+const (
+	debugFontAdvance    = 1.5
+	debugFontLineHeight = 1.8
+)
+
+// debugTextSegments returns the segments drawing a text, 2 points per segment. The text starts at the origin,
+// the glyphs are size wide and tall. A new line starts with '\n'
+func debugTextSegments(text string, size float64) []mgl64.Vec2 {
+	var points []mgl64.Vec2
+	x, y := 0.0, 0.0
+	for i := 0; i < len(text); i++ {
+		char := text[i]
+		if char == '\n' {
+			x = 0
+			y += debugFontLineHeight * size
+			continue
+		}
+		if char >= 'a' && char <= 'z' {
+			char -= 'a' - 'A'
+		}
+		for _, s := range debugFontGlyphs[char] {
+			segment := debugFontSegments[byte(s)]
+			points = append(points,
+				mgl64.Vec2{x + segment[0].X()*size, y + segment[0].Y()*size},
+				mgl64.Vec2{x + segment[1].X()*size, y + segment[1].Y()*size},
+			)
+		}
+		x += debugFontAdvance * size
+	}
+	return points
+}
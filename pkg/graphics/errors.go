@@ -0,0 +1,121 @@
+package graphics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AssetNotFoundError an asset file doesn't exist or can't be opened
+type AssetNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e *AssetNotFoundError) Error() string {
+	return fmt.Sprintf("asset not found '%s': %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error of the file system
+func (e *AssetNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ImageDecodeError an image file is not in a supported format
+type ImageDecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *ImageDecodeError) Error() string {
+	return fmt.Sprintf("unable to decode the image '%s': %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error of the image decoder
+func (e *ImageDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ShaderCompileError a shader failed to compile
+type ShaderCompileError struct {
+	Type ShaderType
+	// File of the shader, empty if it wasn't loaded from a file
+	File string
+	// Line of the first error, 0 if unknown. For the shaders loaded from files it's the line in File
+	Line int
+	// Info log of the compiler. For the shaders loaded from files the lines refer to the original files
+	Log string
+}
+
+func (e *ShaderCompileError) Error() string {
+	location := ""
+	if e.File != "" {
+		location = " " + e.File
+		if e.Line > 0 {
+			location += ":" + strconv.Itoa(e.Line)
+		}
+	} else if e.Line > 0 {
+		location = " at line " + strconv.Itoa(e.Line)
+	}
+	return fmt.Sprintf("failed to compile the %s shader%s:\n%s", e.Type, location, e.Log)
+}
+
+// ShaderLinkError a program failed to link
+type ShaderLinkError struct {
+	// Files of the shaders, empty if they weren't loaded from files
+	Files []string
+	Log   string
+}
+
+func (e *ShaderLinkError) Error() string {
+	if len(e.Files) > 0 {
+		return fmt.Sprintf("failed to link %s:\n%s", strings.Join(e.Files, ", "), e.Log)
+	}
+	return fmt.Sprintf("failed to link the shader program:\n%s", e.Log)
+}
+
+// UniformError a uniform can't be set, or doesn't exist
+type UniformError struct {
+	Name   string
+	Reason string
+}
+
+func (e *UniformError) Error() string {
+	return fmt.Sprintf("uniform '%s': %s", e.Name, e.Reason)
+}
+
+// String returns the name of the shader type
+func (t ShaderType) String() string {
+	switch t {
+	case VERTEX:
+		return "vertex"
+	case GEOMETRY:
+		return "geometry"
+	case FRAGMENT:
+		return "fragment"
+	}
+	return fmt.Sprintf("ShaderType(%d)", uint32(t))
+}
+
+// Formats used by the drivers to report the errors: "0(12) : error ..." (NVIDIA) and "ERROR: 0:12: ..." (Mesa, AMD, Apple)
+var shaderLogLine = []*regexp.Regexp{
+	regexp.MustCompile(`^(\s*)\d+\((\d+)\)(.*)$`),
+	regexp.MustCompile(`^(\s*(?:ERROR|WARNING): )\d+:(\d+)(.*)$`),
+}
+
+// shaderLogFirstLine returns the line of the first error of a compilation log, 0 if there's none
+func shaderLogFirstLine(log string) int {
+	for _, line := range strings.Split(log, "\n") {
+		if strings.Contains(strings.ToUpper(line), "WARNING") {
+			continue
+		}
+		for _, format := range shaderLogLine {
+			if match := format.FindStringSubmatch(line); match != nil {
+				n, _ := strconv.Atoi(match[2])
+				return n
+			}
+		}
+	}
+	return 0
+}
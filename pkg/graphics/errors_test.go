@@ -0,0 +1,67 @@
+package graphics
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShaderLogFirstLine(t *testing.T) {
+	logs := []struct {
+		log      string
+		expected int
+	}{
+		{"0(7) : error C0000: syntax error", 7},
+		{"WARNING: 0:2: unused\nERROR: 0:15: 'x' : undeclared identifier", 15},
+		{"ERROR: too many errors", 0},
+		{"", 0},
+	}
+	for _, l := range logs {
+		if line := shaderLogFirstLine(l.log); line != l.expected {
+			t.Errorf("expected\n%v received\n%v", l.expected, line)
+		}
+	}
+}
+
+func TestShaderErrorMessages(t *testing.T) {
+	errs := []struct {
+		err      error
+		expected string
+	}{
+		{&ShaderCompileError{Type: FRAGMENT, Log: "log"}, "failed to compile the fragment shader:\nlog"},
+		{&ShaderCompileError{Type: VERTEX, Line: 3, Log: "log"}, "failed to compile the vertex shader at line 3:\nlog"},
+		{&ShaderCompileError{Type: GEOMETRY, File: "a.geom", Line: 3, Log: "log"}, "failed to compile the geometry shader a.geom:3:\nlog"},
+		{&ShaderLinkError{Log: "log"}, "failed to link the shader program:\nlog"},
+		{&ShaderLinkError{Files: []string{"a.vert", "a.frag"}, Log: "log"}, "failed to link a.vert, a.frag:\nlog"},
+	}
+	for _, e := range errs {
+		if e.err.Error() != e.expected {
+			t.Errorf("expected\n%v received\n%v", e.expected, e.err.Error())
+		}
+	}
+}
+
+func TestNewTextureFromFileErrors(t *testing.T) {
+	_, err := NewTextureFromFile("missing.png")
+	var notFound *AssetNotFoundError
+	if !errors.As(err, &notFound) || notFound.Path != "missing.png" || !os.IsNotExist(notFound.Err) {
+		t.Errorf("expected an AssetNotFoundError, received %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "textures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "invalid.png")
+	if err := ioutil.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewTextureFromFile(path)
+	var decodeErr *ImageDecodeError
+	if !errors.As(err, &decodeErr) || decodeErr.Path != path {
+		t.Errorf("expected an ImageDecodeError, received %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package graphics
+
+import "sync"
+
+// Functions queued by CallOnMainThread
+var mainThreadCalls struct {
+	sync.Mutex
+	calls []func()
+}
+
+// CallOnMainThread queues a function to be called by RunMainThreadCalls. It can be used from any goroutine,
+// typically to run OpenGL code once some work done in background is ready
+func CallOnMainThread(f func()) {
+	mainThreadCalls.Lock()
+	mainThreadCalls.calls = append(mainThreadCalls.calls, f)
+	mainThreadCalls.Unlock()
+}
+
+// RunMainThreadCalls calls the queued functions in order, the ones queued meanwhile wait for the next call.
+// It must be called from the main thread, the app package does it once per frame
+func RunMainThreadCalls() {
+	mainThreadCalls.Lock()
+	calls := mainThreadCalls.calls
+	mainThreadCalls.calls = nil
+	mainThreadCalls.Unlock()
+
+	for _, f := range calls {
+		f()
+	}
+}
@@ -0,0 +1,276 @@
+package graphics
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Node an element of a scene graph. Its transformation is relative to the parent: moving, rotating or hiding
+// a node affects all its descendants. A node can carry a drawable, which is rendered with the node transformation
+type Node struct {
+	name     string
+	parent   *Node
+	children []*Node
+	drawable Drawable
+
+	position mgl64.Vec3
+	angle    float64
+	scale    mgl64.Vec2
+	anchor   mgl64.Vec2
+	visible  bool
+
+	localMatrix mgl64.Mat4
+	worldMatrix mgl64.Mat4
+	dirty       bool
+	// Incremented every time the world matrix is rebuilt, the attached primitives compare it with their own copy
+	version uint64
+}
+
+// parentable is implemented by the drawables which can follow the transformation of a node
+type parentable interface {
+	SetParent(parent *Node)
+}
+
+// NewNode creates an empty node with no transformation
+func NewNode(name string) *Node {
+	return &Node{
+		name:    name,
+		scale:   mgl64.Vec2{1, 1},
+		visible: true,
+		dirty:   true,
+	}
+}
+
+// NewNodeWithDrawable creates a node carrying a drawable
+func NewNodeWithDrawable(name string, drawable Drawable) *Node {
+	n := NewNode(name)
+	n.SetDrawable(drawable)
+	return n
+}
+
+// Name returns the name of the node
+func (n *Node) Name() string {
+	return n.name
+}
+
+// SetDrawable sets the drawable rendered by the node, nil to remove it.
+// Primitive2D, and the types embedding it, are transformed by the node
+func (n *Node) SetDrawable(drawable Drawable) {
+	if p, ok := n.drawable.(parentable); ok {
+		p.SetParent(nil)
+	}
+	n.drawable = drawable
+	if p, ok := drawable.(parentable); ok {
+		p.SetParent(n)
+	}
+}
+
+// Drawable returns the drawable of the node
+func (n *Node) Drawable() Drawable {
+	return n.drawable
+}
+
+// Parent returns the parent node, nil for a root
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the children of the node, in drawing order
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// AddChild appends a child, it's removed from its previous parent
+func (n *Node) AddChild(child *Node) {
+	for a := n; a != nil; a = a.parent {
+		if a == child {
+			panic("graphics: a node can't be added to its own subtree")
+		}
+	}
+	child.RemoveFromParent()
+	child.parent = n
+	n.children = append(n.children, child)
+	child.markDirty()
+}
+
+// RemoveChild removes a child, it returns false if the node is not a child
+func (n *Node) RemoveChild(child *Node) bool {
+	for i, c := range n.children {
+		if c == child {
+			copy(n.children[i:], n.children[i+1:])
+			n.children[len(n.children)-1] = nil
+			n.children = n.children[:len(n.children)-1]
+			child.parent = nil
+			child.markDirty()
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveFromParent detaches the node from its parent, it becomes a root
+func (n *Node) RemoveFromParent() {
+	if n.parent != nil {
+		n.parent.RemoveChild(n)
+	}
+}
+
+// FindChild searches the subtree depth-first for a node with the specified name, nil if not found
+func (n *Node) FindChild(name string) *Node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+		if found := c.FindChild(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Walk calls a function on the node and all its descendants, depth-first. Returning false skips the children
+func (n *Node) Walk(function func(node *Node) bool) {
+	if !function(n) {
+		return
+	}
+	for _, c := range n.children {
+		c.Walk(function)
+	}
+}
+
+// SetPosition sets the position relative to the parent. Z is used for the drawing order
+func (n *Node) SetPosition(position mgl64.Vec3) {
+	n.position = position
+	n.markDirty()
+}
+
+// Position returns the position relative to the parent
+func (n *Node) Position() mgl64.Vec3 {
+	return n.position
+}
+
+// SetAngle sets the rotation around the Z axis, relative to the parent
+func (n *Node) SetAngle(radians float64) {
+	n.angle = radians
+	n.markDirty()
+}
+
+// Angle in radians, relative to the parent
+func (n *Node) Angle() float64 {
+	return n.angle
+}
+
+// SetScale sets the scaling factor on X and Y, relative to the parent
+func (n *Node) SetScale(scale mgl64.Vec2) {
+	n.scale = scale
+	n.markDirty()
+}
+
+// Scale returns the scaling factor on X and Y, relative to the parent
+func (n *Node) Scale() mgl64.Vec2 {
+	return n.scale
+}
+
+// SetAnchor sets the point of the node, in local coordinates, placed at Position. Rotation and scale happen around it
+func (n *Node) SetAnchor(anchor mgl64.Vec2) {
+	n.anchor = anchor
+	n.markDirty()
+}
+
+// Anchor returns the anchor point
+func (n *Node) Anchor() mgl64.Vec2 {
+	return n.anchor
+}
+
+// SetVisible shows or hides the node and its descendants
+func (n *Node) SetVisible(visible bool) {
+	n.visible = visible
+}
+
+// Visible returns the visibility flag of the node itself
+func (n *Node) Visible() bool {
+	return n.visible
+}
+
+// IsVisible tells if the node is actually shown: it and all its ancestors must be visible
+func (n *Node) IsVisible() bool {
+	for a := n; a != nil; a = a.parent {
+		if !a.visible {
+			return false
+		}
+	}
+	return true
+}
+
+// markDirty invalidates the world matrix of the node and of its descendants.
+// A dirty node always has dirty descendants, so the propagation can stop there
+func (n *Node) markDirty() {
+	if n.dirty {
+		return
+	}
+	n.dirty = true
+	for _, c := range n.children {
+		c.markDirty()
+	}
+}
+
+// LocalMatrix returns the transformation relative to the parent
+func (n *Node) LocalMatrix() mgl64.Mat4 {
+	n.rebuildMatrices()
+	return n.localMatrix
+}
+
+// WorldMatrix returns the transformation from the node coordinates to the world ones
+func (n *Node) WorldMatrix() *mgl64.Mat4 {
+	n.rebuildMatrices()
+	return &n.worldMatrix
+}
+
+func (n *Node) rebuildMatrices() {
+	if n.parent != nil {
+		// Rebuilds the ancestors first, a clean node always has clean ancestors
+		n.parent.rebuildMatrices()
+	}
+	if !n.dirty {
+		return
+	}
+	n.localMatrix = mgl64.Translate3D(n.position.X(), n.position.Y(), n.position.Z()).
+		Mul4(mgl64.HomogRotate3DZ(n.angle)).
+		Mul4(mgl64.Scale3D(n.scale.X(), n.scale.Y(), 1)).
+		Mul4(mgl64.Translate3D(-n.anchor.X(), -n.anchor.Y(), 0))
+	if n.parent != nil {
+		n.worldMatrix = n.parent.worldMatrix.Mul4(n.localMatrix)
+	} else {
+		n.worldMatrix = n.localMatrix
+	}
+	n.dirty = false
+	n.version++
+}
+
+// LocalToWorld converts a point from the node coordinates to the world ones
+func (n *Node) LocalToWorld(point mgl64.Vec3) mgl64.Vec3 {
+	return n.WorldMatrix().Mul4x1(point.Vec4(1)).Vec3()
+}
+
+// WorldToLocal converts a point from the world coordinates to the node ones
+func (n *Node) WorldToLocal(point mgl64.Vec3) mgl64.Vec3 {
+	return n.WorldMatrix().Inv().Mul4x1(point.Vec4(1)).Vec3()
+}
+
+// WorldPosition returns the position of the node in world coordinates
+func (n *Node) WorldPosition() mgl64.Vec3 {
+	return n.WorldMatrix().Col(3).Vec3()
+}
+
+// Draw draws the visible subtree depth-first: the drawable of a node is drawn before its children,
+// which are drawn in the order they were added
+func (n *Node) Draw(context *Context) {
+	if !n.visible {
+		return
+	}
+	if n.drawable != nil {
+		n.drawable.Draw(context)
+	}
+	for _, c := range n.children {
+		c.Draw(context)
+	}
+}
@@ -0,0 +1,163 @@
+package graphics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type drawRecorder struct {
+	testDrawable
+	drawn *[]string
+}
+
+func (d *drawRecorder) Draw(context *Context) {
+	*d.drawn = append(*d.drawn, d.name)
+}
+
+func TestNodeTransformations(t *testing.T) {
+	root := NewNode("root")
+	root.SetPosition(mgl64.Vec3{100, 50, 0})
+	arm := NewNode("arm")
+	arm.SetPosition(mgl64.Vec3{10, 0, 0})
+	root.AddChild(arm)
+
+	expected := mgl64.Vec3{110, 50, 0}
+	if !arm.WorldPosition().ApproxEqual(expected) {
+		t.Errorf("Child position expected\n%v received\n%v", expected, arm.WorldPosition())
+	}
+
+	// Changes on the parent are propagated to the already computed children
+	root.SetAngle(math.Pi / 2)
+	root.SetScale(mgl64.Vec2{2, 2})
+	expected = mgl64.Vec3{100, 70, 0}
+	if !arm.WorldPosition().ApproxEqualThreshold(expected, 1e-9) {
+		t.Errorf("Rotated child position expected\n%v received\n%v", expected, arm.WorldPosition())
+	}
+
+	point := mgl64.Vec3{1, 0, 0}
+	world := arm.LocalToWorld(point)
+	expected = mgl64.Vec3{100, 72, 0}
+	if !world.ApproxEqualThreshold(expected, 1e-9) {
+		t.Errorf("LocalToWorld expected\n%v received\n%v", expected, world)
+	}
+	if local := arm.WorldToLocal(world); !local.ApproxEqualThreshold(point, 1e-9) {
+		t.Errorf("WorldToLocal expected\n%v received\n%v", point, local)
+	}
+
+	// The anchor is the point placed at Position, rotation happens around it
+	root.SetAnchor(mgl64.Vec2{5, 0})
+	expected = mgl64.Vec3{100, 60, 0}
+	if !arm.WorldPosition().ApproxEqualThreshold(expected, 1e-9) {
+		t.Errorf("Anchored child position expected\n%v received\n%v", expected, arm.WorldPosition())
+	}
+}
+
+func TestNodeHierarchy(t *testing.T) {
+	root := NewNode("root")
+	a := NewNode("a")
+	b := NewNode("b")
+	c := NewNode("c")
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChild(c)
+	a.SetPosition(mgl64.Vec3{5, 0, 0})
+
+	if root.FindChild("c") != c || root.FindChild("x") != nil {
+		t.Errorf("FindChild failed")
+	}
+
+	// Moving c under b keeps a single parent and updates the world matrix
+	b.AddChild(c)
+	if len(a.Children()) != 0 || c.Parent() != b {
+		t.Errorf("Reparenting failed: %d children left, parent %v", len(a.Children()), c.Parent().Name())
+	}
+	if !c.WorldPosition().ApproxEqual(mgl64.Vec3{0, 0, 0}) {
+		t.Errorf("Reparented position expected\n%v received\n%v", mgl64.Vec3{0, 0, 0}, c.WorldPosition())
+	}
+
+	var names []string
+	root.Walk(func(node *Node) bool {
+		names = append(names, node.Name())
+		return node != a
+	})
+	expected := []string{"root", "a", "b", "c"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Walk expected\n%v received\n%v", expected, names)
+	}
+
+	c.RemoveFromParent()
+	if c.Parent() != nil || len(b.Children()) != 0 {
+		t.Errorf("RemoveFromParent failed")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Adding a node to its own subtree should panic")
+		}
+	}()
+	a.AddChild(root)
+}
+
+func TestNodeVisibilityAndDrawing(t *testing.T) {
+	var drawn []string
+	newNode := func(name string) *Node {
+		return NewNodeWithDrawable(name, &drawRecorder{testDrawable{name: name}, &drawn})
+	}
+	root := newNode("root")
+	body := newNode("body")
+	weapon := newNode("weapon")
+	label := newNode("label")
+	root.AddChild(body)
+	root.AddChild(label)
+	body.AddChild(weapon)
+
+	root.Draw(&Context{})
+	expected := []string{"root", "body", "weapon", "label"}
+	if !reflect.DeepEqual(drawn, expected) {
+		t.Errorf("Draw order expected\n%v received\n%v", expected, drawn)
+	}
+
+	body.SetVisible(false)
+	if weapon.IsVisible() || !weapon.Visible() {
+		t.Errorf("Visibility is not inherited")
+	}
+	drawn = nil
+	root.Draw(&Context{})
+	expected = []string{"root", "label"}
+	if !reflect.DeepEqual(drawn, expected) {
+		t.Errorf("Draw with a hidden node expected\n%v received\n%v", expected, drawn)
+	}
+}
+
+func TestNodeWithPrimitive(t *testing.T) {
+	p := &Primitive2D{}
+	p.size = mgl64.Vec2{2, 2}
+	p.scale = mgl64.Vec2{1, 1}
+	p.rebuildMatrices()
+	p.SetPosition(mgl64.Vec3{1, 0, 0})
+
+	node := NewNodeWithDrawable("sprite", p)
+	if p.Parent() != node {
+		t.Errorf("The primitive is not attached to the node")
+	}
+	node.SetPosition(mgl64.Vec3{10, 20, 0})
+	expected := mgl64.Mat4FromRows(mgl64.Vec4{2, 0, 0, 11}, mgl64.Vec4{0, 2, 0, 20}, mgl64.Vec4{0, 0, 1, 0}, mgl64.Vec4{0, 0, 0, 1})
+	if !p.ModelMatrix().ApproxEqual(expected) {
+		t.Errorf("expected\n%s received\n%s", expected.String(), p.ModelMatrix().String())
+	}
+
+	// Moving the node invalidates the model matrix of the primitive
+	node.SetPosition(mgl64.Vec3{0, 0, 0})
+	expected = mgl64.Mat4FromRows(mgl64.Vec4{2, 0, 0, 1}, mgl64.Vec4{0, 2, 0, 0}, mgl64.Vec4{0, 0, 1, 0}, mgl64.Vec4{0, 0, 0, 1})
+	if !p.ModelMatrix().ApproxEqual(expected) {
+		t.Errorf("expected\n%s received\n%s", expected.String(), p.ModelMatrix().String())
+	}
+
+	node.SetDrawable(nil)
+	if p.Parent() != nil {
+		t.Errorf("The primitive is still attached to the node")
+	}
+}
@@ -0,0 +1,298 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// LineJoin the shape of the corners of a thick polyline
+type LineJoin int
+
+// Joins of the thick polylines
+const (
+	LineJoinMiter LineJoin = iota
+	LineJoinBevel
+	LineJoinRound
+)
+
+// LineCap the shape of the ends of a thick polyline
+type LineCap int
+
+// Caps of the thick polylines
+const (
+	LineCapButt LineCap = iota
+	LineCapSquare
+	LineCapRound
+)
+
+// DefaultMiterLimit ratio between the length of a miter and the line width above which the corner is beveled
+const DefaultMiterLimit = 4
+
+// Maximum angle covered by a triangle of the round joins and caps
+const roundSegmentAngle = math.Pi / 8
+
+// Distance below which two consecutive points are considered the same
+const pointEpsilon = 1e-9
+
+// LineStyle describes how a polyline is turned into triangles
+type LineStyle struct {
+	Width float64
+	Join  LineJoin
+	Cap   LineCap
+	// 0 means DefaultMiterLimit
+	MiterLimit float64
+	// Lengths of the dashes and of the gaps between them, alternated. Empty for a solid line.
+	// Every dash gets the caps of the line, a zero length dash with round caps is a dot
+	Dashes     []float64
+	DashOffset float64
+}
+
+// TriangulatePolyline turns a polyline into a list of triangles, 2 floats (X,Y) per vertex.
+// The triangles of consecutive segments overlap on the inner side of the corners
+func TriangulatePolyline(points []mgl64.Vec2, closed bool, style LineStyle) []float32 {
+	if style.Width <= 0 || len(points) == 0 {
+		return nil
+	}
+	if len(style.Dashes) == 0 {
+		return appendPolylineTriangles(nil, points, closed, style)
+	}
+	var vertices []float32
+	for _, dash := range splitDashes(points, closed, style.Dashes, style.DashOffset) {
+		vertices = appendPolylineTriangles(vertices, dash, false, style)
+	}
+	return vertices
+}
+
+// splitDashes returns the pieces of the polyline covered by the dashes
+func splitDashes(points []mgl64.Vec2, closed bool, dashes []float64, offset float64) [][]mgl64.Vec2 {
+	if closed {
+		points = append(append([]mgl64.Vec2(nil), points...), points[0])
+	}
+	total := 0.0
+	for _, d := range dashes {
+		if d < 0 {
+			return [][]mgl64.Vec2{points}
+		}
+		total += d
+	}
+	if total <= 0 {
+		return [][]mgl64.Vec2{points}
+	}
+	if len(dashes)%2 != 0 {
+		// An odd pattern is repeated to alternate dashes and gaps
+		dashes = append(append([]float64(nil), dashes...), dashes...)
+		total *= 2
+	}
+
+	index := 0
+	offset = math.Mod(offset, total)
+	if offset < 0 {
+		offset += total
+	}
+	// A zero length dash at the very start is kept, it's a dot
+	for offset > dashes[index] || (offset == dashes[index] && dashes[index] > 0) {
+		offset -= dashes[index]
+		index = (index + 1) % len(dashes)
+	}
+	remaining := dashes[index] - offset
+	on := index%2 == 0
+
+	var pieces [][]mgl64.Vec2
+	var current []mgl64.Vec2
+	if on {
+		current = []mgl64.Vec2{points[0]}
+	}
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		length := b.Sub(a).Len()
+		if length < pointEpsilon {
+			continue
+		}
+		direction := b.Sub(a).Mul(1 / length)
+		position := 0.0
+		for length-position > remaining {
+			position += remaining
+			p := a.Add(direction.Mul(position))
+			if on {
+				pieces = append(pieces, append(current, p))
+				current = nil
+			} else {
+				current = []mgl64.Vec2{p}
+			}
+			on = !on
+			index = (index + 1) % len(dashes)
+			remaining = dashes[index]
+		}
+		remaining -= length - position
+		if on {
+			current = append(current, b)
+		}
+	}
+	if on && len(current) > 0 {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+// appendPolylineTriangles appends the triangles of a solid polyline: a quad per segment, the joins and the caps
+func appendPolylineTriangles(dst []float32, points []mgl64.Vec2, closed bool, style LineStyle) []float32 {
+	points = removeDuplicatePoints(points, closed)
+	halfWidth := style.Width / 2
+
+	if len(points) == 1 {
+		// A single point only shows its caps
+		p := points[0]
+		switch style.Cap {
+		case LineCapSquare:
+			dst = appendQuad(dst,
+				p.Add(mgl64.Vec2{-halfWidth, -halfWidth}), p.Add(mgl64.Vec2{-halfWidth, halfWidth}),
+				p.Add(mgl64.Vec2{halfWidth, halfWidth}), p.Add(mgl64.Vec2{halfWidth, -halfWidth}))
+		case LineCapRound:
+			dst = appendArc(dst, p, mgl64.Vec2{halfWidth, 0}, 2*math.Pi)
+		}
+		return dst
+	}
+	if len(points) == 2 {
+		closed = false
+	}
+
+	numSegments := len(points) - 1
+	if closed {
+		numSegments++
+	}
+	for i := 0; i < numSegments; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		n := segmentNormal(a, b).Mul(halfWidth)
+		dst = appendQuad(dst, a.Add(n), a.Sub(n), b.Sub(n), b.Add(n))
+	}
+
+	// Joins
+	first, last := 1, len(points)-1
+	if closed {
+		first, last = 0, len(points)
+	}
+	for i := first; i < last; i++ {
+		prev := points[(i-1+len(points))%len(points)]
+		next := points[(i+1)%len(points)]
+		dst = appendJoin(dst, prev, points[i], next, halfWidth, style)
+	}
+
+	// Caps
+	if !closed && style.Cap != LineCapButt {
+		dst = appendCap(dst, points[0], points[0].Sub(points[1]).Normalize(), halfWidth, style.Cap)
+		n := len(points)
+		dst = appendCap(dst, points[n-1], points[n-1].Sub(points[n-2]).Normalize(), halfWidth, style.Cap)
+	}
+	return dst
+}
+
+// appendJoin fills the gap left on the outer side of the corner at p
+func appendJoin(dst []float32, prev mgl64.Vec2, p mgl64.Vec2, next mgl64.Vec2, halfWidth float64, style LineStyle) []float32 {
+	d0 := p.Sub(prev).Normalize()
+	d1 := next.Sub(p).Normalize()
+	cross := d0.X()*d1.Y() - d0.Y()*d1.X()
+	dot := d0.Dot(d1)
+	reversed := math.Abs(cross) < pointEpsilon && dot < 0
+	if math.Abs(cross) < pointEpsilon && !reversed {
+		// Straight line
+		return dst
+	}
+
+	// The outer side is the one where the normals of the two segments diverge
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	n0 := mgl64.Vec2{-d0.Y(), d0.X()}.Mul(side * halfWidth)
+	n1 := mgl64.Vec2{-d1.Y(), d1.X()}.Mul(side * halfWidth)
+
+	switch style.Join {
+	case LineJoinRound:
+		angle := math.Atan2(n0.X()*n1.Y()-n0.Y()*n1.X(), n0.Dot(n1))
+		if reversed {
+			// Half a turn, passing in front of the line
+			angle = -math.Pi
+		}
+		return appendArc(dst, p, n0, angle)
+	case LineJoinMiter:
+		if !reversed {
+			miter := n0.Add(n1).Normalize()
+			cos := miter.Dot(n0.Normalize())
+			limit := style.MiterLimit
+			if limit <= 0 {
+				limit = DefaultMiterLimit
+			}
+			if 1/cos <= limit {
+				tip := p.Add(miter.Mul(halfWidth / cos))
+				dst = appendTriangle(dst, p, p.Add(n0), tip)
+				return appendTriangle(dst, p, tip, p.Add(n1))
+			}
+		}
+	}
+	return appendTriangle(dst, p, p.Add(n0), p.Add(n1))
+}
+
+// appendCap appends the cap at the end p of a polyline, direction points outwards
+func appendCap(dst []float32, p mgl64.Vec2, direction mgl64.Vec2, halfWidth float64, lineCap LineCap) []float32 {
+	n := mgl64.Vec2{-direction.Y(), direction.X()}.Mul(halfWidth)
+	switch lineCap {
+	case LineCapSquare:
+		e := direction.Mul(halfWidth)
+		return appendQuad(dst, p.Add(n), p.Sub(n), p.Sub(n).Add(e), p.Add(n).Add(e))
+	case LineCapRound:
+		// From one side to the other, passing through the direction
+		return appendArc(dst, p, n, -math.Pi)
+	}
+	return dst
+}
+
+// appendArc appends a fan of triangles centered on c, starting at c+start and covering angle radians
+func appendArc(dst []float32, c mgl64.Vec2, start mgl64.Vec2, angle float64) []float32 {
+	steps := int(math.Ceil(math.Abs(angle) / roundSegmentAngle))
+	if steps < 1 {
+		steps = 1
+	}
+	step := mgl64.Rotate2D(angle / float64(steps))
+	from := start
+	for i := 0; i < steps; i++ {
+		to := step.Mul2x1(from)
+		dst = appendTriangle(dst, c, c.Add(from), c.Add(to))
+		from = to
+	}
+	return dst
+}
+
+// segmentNormal returns the unit normal of the segment a-b, pointing to its left when the Y axis points up
+func segmentNormal(a mgl64.Vec2, b mgl64.Vec2) mgl64.Vec2 {
+	d := b.Sub(a).Normalize()
+	return mgl64.Vec2{-d.Y(), d.X()}
+}
+
+// removeDuplicatePoints removes the consecutive points that are the same, segments of zero length have no normal
+func removeDuplicatePoints(points []mgl64.Vec2, closed bool) []mgl64.Vec2 {
+	result := make([]mgl64.Vec2, 0, len(points))
+	for _, p := range points {
+		if len(result) == 0 || p.Sub(result[len(result)-1]).Len() >= pointEpsilon {
+			result = append(result, p)
+		}
+	}
+	if closed && len(result) > 1 && result[0].Sub(result[len(result)-1]).Len() < pointEpsilon {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+func appendTriangle(dst []float32, a mgl64.Vec2, b mgl64.Vec2, c mgl64.Vec2) []float32 {
+	return append(dst,
+		float32(a.X()), float32(a.Y()),
+		float32(b.X()), float32(b.Y()),
+		float32(c.X()), float32(c.Y()),
+	)
+}
+
+// appendQuad appends the quad a,b,c,d as the two triangles a,b,c and a,c,d
+func appendQuad(dst []float32, a mgl64.Vec2, b mgl64.Vec2, c mgl64.Vec2, d mgl64.Vec2) []float32 {
+	dst = appendTriangle(dst, a, b, c)
+	return appendTriangle(dst, a, c, d)
+}
@@ -0,0 +1,125 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func polylineBounds(vertices []float32) (mgl64.Vec2, mgl64.Vec2) {
+	min := mgl64.Vec2{math.Inf(1), math.Inf(1)}
+	max := mgl64.Vec2{math.Inf(-1), math.Inf(-1)}
+	for i := 0; i < len(vertices); i += 2 {
+		x, y := float64(vertices[i]), float64(vertices[i+1])
+		min = mgl64.Vec2{math.Min(min.X(), x), math.Min(min.Y(), y)}
+		max = mgl64.Vec2{math.Max(max.X(), x), math.Max(max.Y(), y)}
+	}
+	return min, max
+}
+
+func hasVertex(vertices []float32, v mgl64.Vec2) bool {
+	for i := 0; i < len(vertices); i += 2 {
+		if math.Abs(float64(vertices[i])-v.X()) < 1e-5 && math.Abs(float64(vertices[i+1])-v.Y()) < 1e-5 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTriangulatePolyline(t *testing.T) {
+	line := []mgl64.Vec2{{0, 0}, {10, 0}}
+	corner := []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}}
+	sharp := []mgl64.Vec2{{0, 0}, {10, 0}, {0, 1}}
+	square := []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+
+	tests := []struct {
+		name        string
+		points      []mgl64.Vec2
+		closed      bool
+		style       LineStyle
+		numVertices int
+		min         mgl64.Vec2
+		max         mgl64.Vec2
+	}{
+		{"butt", line, false, LineStyle{Width: 2}, 6, mgl64.Vec2{0, -1}, mgl64.Vec2{10, 1}},
+		{"square cap", line, false, LineStyle{Width: 2, Cap: LineCapSquare}, 18, mgl64.Vec2{-1, -1}, mgl64.Vec2{11, 1}},
+		{"round cap", line, false, LineStyle{Width: 2, Cap: LineCapRound}, 6 + 2*8*3, mgl64.Vec2{-1, -1}, mgl64.Vec2{11, 1}},
+		{"duplicate points", []mgl64.Vec2{{0, 0}, {0, 0}, {10, 0}, {10, 0}}, false, LineStyle{Width: 2}, 6, mgl64.Vec2{0, -1}, mgl64.Vec2{10, 1}},
+		{"miter", corner, false, LineStyle{Width: 2}, 18, mgl64.Vec2{0, -1}, mgl64.Vec2{11, 10}},
+		{"bevel", corner, false, LineStyle{Width: 2, Join: LineJoinBevel}, 15, mgl64.Vec2{0, -1}, mgl64.Vec2{11, 10}},
+		{"round join", corner, false, LineStyle{Width: 2, Join: LineJoinRound}, 12 + 4*3, mgl64.Vec2{0, -1}, mgl64.Vec2{11, 10}},
+		{"miter limit", sharp, false, LineStyle{Width: 2}, 15, mgl64.Vec2{-0.0995037, -1}, mgl64.Vec2{10.0995037, 1.9950372}},
+		{"closed", square, true, LineStyle{Width: 2}, 4*6 + 4*6, mgl64.Vec2{-1, -1}, mgl64.Vec2{11, 11}},
+		{"round dot", []mgl64.Vec2{{5, 5}}, false, LineStyle{Width: 2, Cap: LineCapRound}, 16 * 3, mgl64.Vec2{4, 4}, mgl64.Vec2{6, 6}},
+		{"no width", line, false, LineStyle{}, 0, mgl64.Vec2{}, mgl64.Vec2{}},
+	}
+
+	for _, test := range tests {
+		vertices := TriangulatePolyline(test.points, test.closed, test.style)
+		if len(vertices) != test.numVertices*2 {
+			t.Errorf("%s: number of vertices expected\n%v received\n%v", test.name, test.numVertices, len(vertices)/2)
+			continue
+		}
+		if test.numVertices == 0 {
+			continue
+		}
+		min, max := polylineBounds(vertices)
+		if !min.ApproxEqualThreshold(test.min, 1e-5) || !max.ApproxEqualThreshold(test.max, 1e-5) {
+			t.Errorf("%s: bounds expected\n%v %v received\n%v %v", test.name, test.min, test.max, min, max)
+		}
+	}
+
+	// The miter reaches the outer corner, the bevel cuts it
+	miter := TriangulatePolyline(corner, false, LineStyle{Width: 2})
+	if !hasVertex(miter, mgl64.Vec2{11, -1}) {
+		t.Errorf("The miter join should reach the outer corner")
+	}
+	bevel := TriangulatePolyline(corner, false, LineStyle{Width: 2, Join: LineJoinBevel})
+	if hasVertex(bevel, mgl64.Vec2{11, -1}) {
+		t.Errorf("The bevel join should not reach the outer corner")
+	}
+}
+
+func TestSplitDashes(t *testing.T) {
+	line := []mgl64.Vec2{{0, 0}, {10, 0}}
+	tests := []struct {
+		name     string
+		points   []mgl64.Vec2
+		closed   bool
+		dashes   []float64
+		offset   float64
+		expected [][]mgl64.Vec2
+	}{
+		{"dashes", line, false, []float64{2, 3}, 0,
+			[][]mgl64.Vec2{{{0, 0}, {2, 0}}, {{5, 0}, {7, 0}}}},
+		{"offset", line, false, []float64{2, 3}, 1,
+			[][]mgl64.Vec2{{{0, 0}, {1, 0}}, {{4, 0}, {6, 0}}, {{9, 0}, {10, 0}}}},
+		{"odd pattern", line, false, []float64{4}, 0,
+			[][]mgl64.Vec2{{{0, 0}, {4, 0}}, {{8, 0}, {10, 0}}}},
+		{"corner", []mgl64.Vec2{{0, 0}, {4, 0}, {4, 4}}, false, []float64{6, 10}, 0,
+			[][]mgl64.Vec2{{{0, 0}, {4, 0}, {4, 2}}}},
+		{"closed", []mgl64.Vec2{{0, 0}, {4, 0}, {4, 4}}, true, []float64{3, 6}, 0,
+			[][]mgl64.Vec2{{{0, 0}, {3, 0}}, {{3.2929, 3.2929}, {1.1716, 1.1716}}}},
+	}
+
+	for _, test := range tests {
+		pieces := splitDashes(test.points, test.closed, test.dashes, test.offset)
+		equal := len(pieces) == len(test.expected)
+		for i := 0; equal && i < len(pieces); i++ {
+			equal = len(pieces[i]) == len(test.expected[i])
+			for j := 0; equal && j < len(pieces[i]); j++ {
+				equal = pieces[i][j].ApproxEqualThreshold(test.expected[i][j], 1e-2)
+			}
+		}
+		if !equal {
+			t.Errorf("%s: expected\n%v received\n%v", test.name, test.expected, pieces)
+		}
+	}
+
+	// Zero length dashes with round caps are dots
+	vertices := TriangulatePolyline(line, false, LineStyle{Width: 2, Cap: LineCapRound, Dashes: []float64{0, 5}})
+	if len(vertices) != 2*16*3*2 {
+		t.Errorf("expected 2 dots, received %d vertices", len(vertices)/2)
+	}
+}
@@ -0,0 +1,458 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/maxfish/gojira2d/pkg/log"
+)
+
+// PostProcessPass a fullscreen fragment shader applied to the output of the previous pass.
+// Every pass receives the uniforms: tex (the input), time (seconds) and resolution (pixels)
+type PostProcessPass struct {
+	Name          string
+	Enabled       bool
+	shaderProgram *ShaderProgram
+	floats        map[string]float32
+	lut           *Texture
+}
+
+// NewPostProcessPass creates a pass from the source of a fragment shader, see NewShaderProgram for the errors
+func NewPostProcessPass(name string, fragSource string) (*PostProcessPass, error) {
+	shaderProgram, err := DefaultShaderCache.Acquire(VertexShaderFullscreen, "", fragSource)
+	if err != nil {
+		return nil, err
+	}
+	return &PostProcessPass{
+		Name:          name,
+		Enabled:       true,
+		shaderProgram: shaderProgram,
+		floats:        make(map[string]float32),
+	}, nil
+}
+
+// MustNewPostProcessPass is like NewPostProcessPass but panics if the shader can't be built
+func MustNewPostProcessPass(name string, fragSource string) *PostProcessPass {
+	p, err := NewPostProcessPass(name, fragSource)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewGrayscalePass converts the image to shades of gray
+func NewGrayscalePass() *PostProcessPass {
+	return MustNewPostProcessPass("grayscale", FragmentShaderGrayscale)
+}
+
+// NewVignettePass darkens the borders of the image. radius and softness are relative to the screen size
+func NewVignettePass(radius float32, softness float32) *PostProcessPass {
+	p := MustNewPostProcessPass("vignette", FragmentShaderVignette)
+	p.SetFloat("radius", radius)
+	p.SetFloat("softness", softness)
+	return p
+}
+
+// NewBlurPass blurs the image, radius is in pixels
+func NewBlurPass(radius float32) *PostProcessPass {
+	p := MustNewPostProcessPass("blur", FragmentShaderBlur)
+	p.SetFloat("radius", radius)
+	return p
+}
+
+// NewScanlinesPass simulates a CRT screen: curvature and scanlines. intensity is in the range [0,1]
+func NewScanlinesPass(intensity float32) *PostProcessPass {
+	p := MustNewPostProcessPass("scanlines", FragmentShaderScanlines)
+	p.SetFloat("intensity", intensity)
+	return p
+}
+
+// NewColorGradingPass remaps the colors using a LUT texture. The LUT is a strip of N slices of NxN pixels,
+// the blue channel selects the slice, red and green are the X and Y inside the slice
+func NewColorGradingPass(lut *Texture) *PostProcessPass {
+	p := MustNewPostProcessPass("color_grading", FragmentShaderColorGrading)
+	p.SetLUT(lut)
+	return p
+}
+
+// SetFloat sets the value of a float uniform of the pass
+func (p *PostProcessPass) SetFloat(name string, value float32) {
+	p.floats[name] = value
+}
+
+// Float returns the value of a float uniform of the pass
+func (p *PostProcessPass) Float(name string) float32 {
+	return p.floats[name]
+}
+
+// SetLUT sets the texture bound to the lut uniform
+func (p *PostProcessPass) SetLUT(lut *Texture) {
+	p.lut = lut
+	if lut != nil {
+		p.SetFloat("lutSize", float32(lut.height))
+	}
+}
+
+// Shader returns the shader program of the pass
+func (p *PostProcessPass) Shader() *ShaderProgram {
+	return p.shaderProgram
+}
+
+// Release releases the shader program of the pass
+func (p *PostProcessPass) Release() {
+	if p.shaderProgram != nil {
+		p.shaderProgram.Release()
+		p.shaderProgram = nil
+	}
+}
+
+// PostProcessor renders the scene into an offscreen buffer and applies a chain of fullscreen passes to it
+type PostProcessor struct {
+	width      int
+	height     int
+	targets    [2]*RenderTarget
+	passes     []*PostProcessPass
+	copyPass   *PostProcessPass
+	vaoId      uint32
+	vboId      uint32
+	time       float64
+	clearColor Color
+}
+
+// NewPostProcessor creates a post processor working at the specified resolution, usually the window size
+func NewPostProcessor(width int, height int) (*PostProcessor, error) {
+	pp := &PostProcessor{
+		width:  width,
+		height: height,
+	}
+	for i := range pp.targets {
+		target, err := NewRenderTarget(width, height, i == 0)
+		if err != nil {
+			pp.Release()
+			return nil, err
+		}
+		// Drawn with the fullscreen quad, its UVs follow OpenGL
+		target.bottomUp = true
+		pp.targets[i] = target
+	}
+	copyPass, err := NewPostProcessPass("copy", FragmentShaderTexture)
+	if err != nil {
+		pp.Release()
+		return nil, err
+	}
+	pp.copyPass = copyPass
+
+	// Fullscreen quad in normalized device coordinates: X,Y,U,V
+	vertices := []float32{-1, -1, 0, 0, 1, -1, 1, 0, 1, 1, 1, 1, -1, 1, 0, 1}
+	stride := int32(4 * Float32Size)
+	gl.GenVertexArrays(1, &pp.vaoId)
+	gl.BindVertexArray(pp.vaoId)
+	gl.GenBuffers(1, &pp.vboId)
+	gl.BindBuffer(gl.ARRAY_BUFFER, pp.vboId)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*Float32Size, gl.Ptr(vertices), gl.STATIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, gl.PtrOffset(2*Float32Size))
+	gl.BindVertexArray(0)
+
+	return pp, nil
+}
+
+// SetClearColor sets the color used to clear the offscreen buffer in Begin
+func (pp *PostProcessor) SetClearColor(color Color) {
+	pp.clearColor = color
+}
+
+// AddPass appends a pass at the end of the chain
+func (pp *PostProcessor) AddPass(pass *PostProcessPass) {
+	pp.passes = append(pp.passes, pass)
+}
+
+// InsertPass inserts a pass at the specified position of the chain
+func (pp *PostProcessor) InsertPass(index int, pass *PostProcessPass) {
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(pp.passes) {
+		pp.AddPass(pass)
+		return
+	}
+	pp.passes = append(pp.passes, nil)
+	copy(pp.passes[index+1:], pp.passes[index:])
+	pp.passes[index] = pass
+}
+
+// RemovePass removes the first pass with the specified name and returns it, nil if not found
+func (pp *PostProcessor) RemovePass(name string) *PostProcessPass {
+	index := pp.passIndex(name)
+	if index < 0 {
+		return nil
+	}
+	pass := pp.passes[index]
+	pp.passes = append(pp.passes[:index], pp.passes[index+1:]...)
+	return pass
+}
+
+// MovePass moves the pass with the specified name to a new position of the chain
+func (pp *PostProcessor) MovePass(name string, index int) {
+	pass := pp.RemovePass(name)
+	if pass == nil {
+		log.Errorf("post processing pass '%s' not found", name)
+		return
+	}
+	pp.InsertPass(index, pass)
+}
+
+// Pass returns the first pass with the specified name, nil if not found
+func (pp *PostProcessor) Pass(name string) *PostProcessPass {
+	index := pp.passIndex(name)
+	if index < 0 {
+		return nil
+	}
+	return pp.passes[index]
+}
+
+// Passes returns the chain of passes, in order of execution
+func (pp *PostProcessor) Passes() []*PostProcessPass {
+	return pp.passes
+}
+
+func (pp *PostProcessor) passIndex(name string) int {
+	for i, pass := range pp.passes {
+		if pass.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Update advances the time passed to the passes
+func (pp *PostProcessor) Update(deltaTime float64) {
+	pp.time += deltaTime
+}
+
+// Begin redirects the drawing of the context to the offscreen buffer
+func (pp *PostProcessor) Begin(context *Context) {
+	pp.targets[0].Clear(pp.clearColor)
+	context.PushRenderTarget(pp.targets[0])
+}
+
+// End runs the chain of passes, the last one draws into the target active before Begin
+func (pp *PostProcessor) End(context *Context) {
+	context.PopRenderTarget()
+
+	passes := make([]*PostProcessPass, 0, len(pp.passes))
+	for _, pass := range pp.passes {
+		if pass.Enabled {
+			passes = append(passes, pass)
+		}
+	}
+	if len(passes) == 0 {
+		passes = append(passes, pp.copyPass)
+	}
+
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	blend := gl.IsEnabled(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+
+	source := 0
+	for i, pass := range passes {
+		last := i == len(passes)-1
+		if !last {
+			context.PushRenderTarget(pp.targets[1-source])
+		}
+		pp.drawPass(context, pass, pp.targets[source].Texture())
+		if !last {
+			context.PopRenderTarget()
+			source = 1 - source
+		}
+	}
+
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	if blend {
+		gl.Enable(gl.BLEND)
+	}
+}
+
+func (pp *PostProcessor) drawPass(context *Context, pass *PostProcessPass, input *Texture) {
+	shader := pass.shaderProgram
+	context.BindShader(shader)
+	context.BindTexture(input)
+
+	time := float32(pp.time)
+	resolution := mgl32.Vec2{float32(pp.width), float32(pp.height)}
+	// Optional, the passes not using them don't have them
+	if _, found := shader.ActiveUniform("time"); found {
+		shader.LogUniformError(shader.SetUniform("time", &time))
+	}
+	if _, found := shader.ActiveUniform("resolution"); found {
+		shader.LogUniformError(shader.SetUniform("resolution", &resolution))
+	}
+	for name, value := range pass.floats {
+		v := value
+		shader.LogUniformError(shader.SetUniform(name, &v))
+	}
+	if pass.lut != nil {
+		gl.ActiveTexture(gl.TEXTURE1)
+		gl.BindTexture(gl.TEXTURE_2D, pass.lut.id)
+		shader.LogUniformError(shader.SetUniformSampler("lut", 1))
+		gl.ActiveTexture(gl.TEXTURE0)
+	}
+
+	gl.BindVertexArray(pp.vaoId)
+	gl.DrawArrays(gl.TRIANGLE_FAN, 0, 4)
+	gl.BindVertexArray(0)
+	context.drawCalls++
+}
+
+// Release releases the offscreen buffers. The passes added are not released
+func (pp *PostProcessor) Release() {
+	if pp.copyPass != nil {
+		pp.copyPass.Release()
+		pp.copyPass = nil
+	}
+	for i, target := range pp.targets {
+		if target != nil {
+			target.Release()
+			pp.targets[i] = nil
+		}
+	}
+	if pp.vboId != 0 {
+		gl.DeleteBuffers(1, &pp.vboId)
+		gl.DeleteVertexArrays(1, &pp.vaoId)
+		pp.vboId = 0
+		pp.vaoId = 0
+	}
+}
+
+const (
+	// VertexShaderFullscreen passes through vertices already in normalized device coordinates
+	VertexShaderFullscreen = `
+        #version 410 core
+
+        layout(location=0) in vec2 vertex;
+        layout(location=1) in vec2 uv;
+
+        out vec2 uv_out;
+
+        void main() {
+            gl_Position = vec4(vertex, 0, 1);
+            uv_out = uv;
+        }
+        ` + "\x00"
+
+	// FragmentShaderGrayscale converts the colors to their luminance
+	FragmentShaderGrayscale = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 color;
+
+        uniform sampler2D tex;
+
+        void main() {
+            vec4 c = texture(tex, uv_out);
+            float luminance = dot(c.rgb, vec3(0.299, 0.587, 0.114));
+            color = vec4(vec3(luminance), c.a);
+        }
+        ` + "\x00"
+
+	// FragmentShaderVignette darkens the image away from its center
+	FragmentShaderVignette = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 color;
+
+        uniform sampler2D tex;
+        uniform vec2 resolution;
+        uniform float radius;
+        uniform float softness;
+
+        void main() {
+            vec4 c = texture(tex, uv_out);
+            vec2 position = uv_out - vec2(0.5);
+            position.x *= resolution.x / resolution.y;
+            float vignette = smoothstep(radius, radius - softness, length(position));
+            color = vec4(c.rgb * vignette, c.a);
+        }
+        ` + "\x00"
+
+	// FragmentShaderBlur a 9x9 box blur, the samples are spread over the radius
+	FragmentShaderBlur = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 color;
+
+        uniform sampler2D tex;
+        uniform vec2 resolution;
+        uniform float radius;
+
+        void main() {
+            vec2 texel = radius / (4.0 * resolution);
+            vec4 sum = vec4(0);
+            for (int x = -4; x <= 4; x++) {
+                for (int y = -4; y <= 4; y++) {
+                    sum += texture(tex, uv_out + vec2(x, y) * texel);
+                }
+            }
+            color = sum / 81.0;
+        }
+        ` + "\x00"
+
+	// FragmentShaderScanlines simulates a CRT screen with curvature and moving scanlines
+	FragmentShaderScanlines = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 color;
+
+        uniform sampler2D tex;
+        uniform vec2 resolution;
+        uniform float time;
+        uniform float intensity;
+
+        void main() {
+            vec2 uv = uv_out * 2.0 - 1.0;
+            uv *= 1.0 + pow(abs(uv.yx) / 4.0, vec2(2.0));
+            uv = uv * 0.5 + 0.5;
+            if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+                color = vec4(0, 0, 0, 1);
+                return;
+            }
+            vec4 c = texture(tex, uv);
+            float scanline = sin((uv.y * resolution.y + time * 10.0) * 3.14159) * 0.5 + 0.5;
+            color = vec4(c.rgb * (1.0 - intensity * scanline), c.a);
+        }
+        ` + "\x00"
+
+	// FragmentShaderColorGrading remaps the colors through a LUT strip
+	FragmentShaderColorGrading = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 color;
+
+        uniform sampler2D tex;
+        uniform sampler2D lut;
+        uniform float lutSize;
+
+        vec3 lookup(vec3 c, float slice) {
+            float x = (slice * lutSize + c.r * (lutSize - 1.0) + 0.5) / (lutSize * lutSize);
+            float y = (c.g * (lutSize - 1.0) + 0.5) / lutSize;
+            return texture(lut, vec2(x, y)).rgb;
+        }
+
+        void main() {
+            vec4 c = clamp(texture(tex, uv_out), 0.0, 1.0);
+            float blue = c.b * (lutSize - 1.0);
+            float slice = floor(blue);
+            vec3 graded = mix(lookup(c.rgb, slice), lookup(c.rgb, min(slice + 1.0, lutSize - 1.0)), blue - slice);
+            color = vec4(graded, c.a);
+        }
+        ` + "\x00"
+)
@@ -0,0 +1,39 @@
+package graphics
+
+import (
+	"testing"
+)
+
+func TestPostProcessorPasses(t *testing.T) {
+	pp := &PostProcessor{}
+	pp.AddPass(&PostProcessPass{Name: "blur"})
+	pp.AddPass(&PostProcessPass{Name: "vignette"})
+	pp.InsertPass(0, &PostProcessPass{Name: "grayscale"})
+	pp.InsertPass(10, &PostProcessPass{Name: "scanlines"})
+
+	checkOrder := func(step string, expected ...string) {
+		passes := pp.Passes()
+		if len(passes) != len(expected) {
+			t.Errorf("%s: got %d passes, expecting %d", step, len(passes), len(expected))
+			return
+		}
+		for i, pass := range passes {
+			if pass.Name != expected[i] {
+				t.Errorf("%s: pass #%d is %s, expecting %s", step, i, pass.Name, expected[i])
+			}
+		}
+	}
+	checkOrder("Add/Insert", "grayscale", "blur", "vignette", "scanlines")
+
+	pp.MovePass("scanlines", 1)
+	checkOrder("MovePass", "grayscale", "scanlines", "blur", "vignette")
+
+	if pp.Pass("blur") == nil || pp.Pass("bloom") != nil {
+		t.Errorf("Pass lookup failed")
+	}
+
+	if pp.RemovePass("grayscale") == nil || pp.RemovePass("grayscale") != nil {
+		t.Errorf("RemovePass failed")
+	}
+	checkOrder("RemovePass", "scanlines", "blur", "vignette")
+}
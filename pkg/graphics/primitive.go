@@ -6,6 +6,8 @@ type Primitive struct {
 	vboUVCoords   uint32
 	arrayMode     uint32
 	arraySize     int32
+	vertices      []float32
+	uvCoords      []float32
 	texture       *Texture
 	shaderProgram *ShaderProgram
 }
@@ -1,11 +1,12 @@
 package graphics
 
 import (
-	"fmt"
+	"errors"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/log"
 	"github.com/maxfish/gojira2d/pkg/utils"
 )
 
@@ -37,7 +38,11 @@ type Primitive2D struct {
 	flipX       bool
 	flipY       bool
 	color       Color
+	region      *TextureRegion
 	modelMatrix ModelMatrix
+	// Node the primitive is attached to, its world matrix is applied on top of the model matrix
+	parent        *Node
+	parentVersion uint64
 }
 
 // SetPosition sets the X,Y,Z position of the primitive. Z is used for the drawing order
@@ -88,20 +93,57 @@ func (p *Primitive2D) SetSize(size mgl64.Vec2) {
 	p.modelMatrix.dirty = true
 }
 
-// SetSizeFromTexture sets the size of the current primitive to the pixel size of the texture
+// SetSizeFromTexture sets the size of the current primitive to the pixel size of the texture, or of the region if set
 func (p *Primitive2D) SetSizeFromTexture() {
+	if p.region != nil {
+		p.SetSize(p.region.OriginalSize())
+		return
+	}
 	if p.texture == nil {
 		return
 	}
 	p.SetSize(mgl64.Vec2{float64(p.texture.width), float64(p.texture.height)})
 }
 
+// SetTextureRegion makes the quad show only a region of a texture. Pass nil to go back to the whole texture.
+// The size of the quad is the one of the original image, a trimmed region covers only part of it
+func (p *Primitive2D) SetTextureRegion(region *TextureRegion) {
+	p.region = region
+	if region == nil {
+		p.SetVertices([]float32{0, 0, 0, 1, 1, 1, 1, 0})
+		p.SetUVCoords([]float32{0, 0, 0, 1, 1, 1, 1, 0})
+		return
+	}
+	p.texture = region.texture
+	p.SetVertices(region.QuadVertices())
+	p.SetUVCoords(region.UVCoords())
+}
+
+// SetAnchorToPivot sets the anchor at the pivot point of the texture region, if any
+func (p *Primitive2D) SetAnchorToPivot() {
+	if p.region == nil {
+		return
+	}
+	pivot := p.region.Pivot()
+	p.SetAnchor(mgl64.Vec2{pivot.X() * p.size.X(), pivot.Y() * p.size.Y()})
+}
+
+// TextureRegion returns the region of the texture shown by the quad, nil if the whole texture is used
+func (p *Primitive2D) TextureRegion() *TextureRegion {
+	return p.region
+}
+
 // SetScale sets the scaling factor on X and Y for the primitive. The scaling respects the anchor and the rotation
 func (p *Primitive2D) SetScale(scale mgl64.Vec2) {
 	p.scale = scale
 	p.rebuildScaleMatrix()
 }
 
+// Scale returns the scaling factor on X and Y
+func (p *Primitive2D) Scale() mgl64.Vec2 {
+	return p.scale
+}
+
 // SetFlipX flips the primitive around the Y axis
 func (p *Primitive2D) SetFlipX(flipX bool) {
 	p.flipX = flipX
@@ -119,29 +161,61 @@ func (p *Primitive2D) SetColor(color Color) {
 	p.color = color
 }
 
+// Color returns the color passed to the shader
+func (p *Primitive2D) Color() Color {
+	return p.color
+}
+
 // SetUniforms sets the shader's uniform variables
 func (p *Primitive2D) SetUniforms() {
-	p.shaderProgram.SetUniform("color", &p.color)
-	p.shaderProgram.SetUniform("model", p.ModelMatrix32())
+	if hasColorUniform(p.shaderProgram) {
+		p.shaderProgram.LogUniformError(p.shaderProgram.SetUniform("color", &p.color))
+	}
+	p.shaderProgram.LogUniformError(p.shaderProgram.SetUniform("model", p.ModelMatrix32()))
 }
 
-// Draw draws the primitive
+// Draw draws the primitive. If a SpriteBatch is active on the context, quads and filled polygons are added to it
 func (p *Primitive2D) Draw(context *Context) {
-	shaderID := p.shaderProgram.ID()
-	context.BindTexture(p.texture)
-	gl.UseProgram(shaderID)
-	cameraMatrix := context.Camera2D.ProjectionMatrix32()
-	p.shaderProgram.SetUniform("projection", &cameraMatrix)
+	if context.batch != nil {
+		if p.isBatchable() {
+			context.batch.Add(p)
+			return
+		}
+		// Flushes what has been collected so far to respect the drawing order
+		context.batch.Flush()
+	}
+	p.drawDirect(context)
+}
+
+// DrawInBatch draws the primitive assuming that the correct texture and shader are already bound.
+// If a SpriteBatch is active on the context, the primitive is added to it instead
+func (p *Primitive2D) DrawInBatch(context *Context) {
+	if context.batch != nil {
+		if p.isBatchable() {
+			context.batch.Add(p)
+			return
+		}
+		if context.batch.NumVertices() > 0 {
+			// Flushing the batch changes the bound texture and shader
+			context.batch.Flush()
+			p.drawDirect(context)
+			return
+		}
+	}
 	p.SetUniforms()
 	gl.BindVertexArray(p.vaoId)
 	gl.DrawArrays(p.arrayMode, 0, p.arraySize)
+	context.drawCalls++
 }
 
-// DrawInBatch draws the primitive assuming that the correct texture and shader are already bound
-func (p *Primitive2D) DrawInBatch(context *Context) {
+func (p *Primitive2D) drawDirect(context *Context) {
+	context.BindTexture(p.texture)
+	context.BindShader(p.shaderProgram)
+	context.ApplyProjection(p.shaderProgram)
 	p.SetUniforms()
 	gl.BindVertexArray(p.vaoId)
 	gl.DrawArrays(p.arrayMode, 0, p.arraySize)
+	context.drawCalls++
 }
 
 func (p *Primitive2D) rebuildMatrices() {
@@ -168,14 +242,36 @@ func (p *Primitive2D) rebuildScaleMatrix() {
 }
 
 func (p *Primitive2D) rebuildModelMatrix() {
+	if p.parent != nil {
+		p.parent.rebuildMatrices()
+		if p.parent.version != p.parentVersion {
+			p.parentVersion = p.parent.version
+			p.modelMatrix.dirty = true
+		}
+	}
 	if p.modelMatrix.dirty {
 		p.modelMatrix.Mat4 = p.modelMatrix.translation.Mul4(p.modelMatrix.rotation).Mul4(p.modelMatrix.scale).Mul4(p.modelMatrix.anchor).Mul4(p.modelMatrix.size)
+		if p.parent != nil {
+			p.modelMatrix.Mat4 = p.parent.worldMatrix.Mul4(p.modelMatrix.Mat4)
+		}
 		// updates the float32 version
 		p.modelMatrix.mat32 = utils.Mat4From64to32Bits(p.modelMatrix.Mat4)
 		p.modelMatrix.dirty = false
 	}
 }
 
+// SetParent attaches the primitive to a node, nil detaches it. Usually called by Node.SetDrawable
+func (p *Primitive2D) SetParent(parent *Node) {
+	p.parent = parent
+	p.parentVersion = 0
+	p.modelMatrix.dirty = true
+}
+
+// Parent returns the node the primitive is attached to
+func (p *Primitive2D) Parent() *Node {
+	return p.parent
+}
+
 // ModelMatrix returns the current model matrix
 func (p *Primitive2D) ModelMatrix() *mgl64.Mat4 {
 	p.rebuildModelMatrix()
@@ -195,7 +291,7 @@ func NewQuadPrimitive(position mgl64.Vec3, size mgl64.Vec2) *Primitive2D {
 		size:     size,
 		scale:    mgl64.Vec2{1, 1},
 	}
-	q.shaderProgram = NewShaderProgram(VertexShaderBase, "", FragmentShaderTexture)
+	q.shaderProgram = DefaultShaderCache.builtin(VertexShaderBase, "", FragmentShaderTexture)
 	q.rebuildMatrices()
 	q.arrayMode = gl.TRIANGLE_FAN
 	q.arraySize = 4
@@ -206,11 +302,18 @@ func NewQuadPrimitive(position mgl64.Vec3, size mgl64.Vec2) *Primitive2D {
 	return q
 }
 
+// NewQuadPrimitiveFromRegion creates a rectangular primitive showing a texture region, the size is the one of the region
+func NewQuadPrimitiveFromRegion(position mgl64.Vec3, region *TextureRegion) *Primitive2D {
+	q := NewQuadPrimitive(position, region.OriginalSize())
+	q.SetTextureRegion(region)
+	return q
+}
+
 // NewRegularPolygonPrimitive creates a primitive from a regular polygon
 func NewRegularPolygonPrimitive(center mgl64.Vec3, radius float64, numSegments int, filled bool) *Primitive2D {
 	circlePoints, err := utils.CircleToPolygon(mgl64.Vec2{0, 0}, radius, numSegments, 0)
 	if err != nil {
-		fmt.Println(err)
+		log.Errorf("%v", err)
 		return nil
 	}
 
@@ -219,7 +322,7 @@ func NewRegularPolygonPrimitive(center mgl64.Vec3, radius float64, numSegments i
 		size:     mgl64.Vec2{1, 1},
 		scale:    mgl64.Vec2{1, 1},
 	}
-	q.shaderProgram = NewShaderProgram(VertexShaderBase, "", FragmentShaderSolidColor)
+	q.shaderProgram = DefaultShaderCache.builtin(VertexShaderBase, "", FragmentShaderSolidColor)
 	q.rebuildMatrices()
 
 	// Vertices
@@ -240,6 +343,54 @@ func NewRegularPolygonPrimitive(center mgl64.Vec3, radius float64, numSegments i
 	return q
 }
 
+// NewPolygonPrimitive creates a filled primitive from a polygon, concave or with holes. The points coordinates are
+// relative to the passed center. The UV coordinates map the bounding box of the polygon to the whole texture, to
+// texture it set a texture and a shader using it (e.g. FragmentShaderTextureColor)
+func NewPolygonPrimitive(center mgl64.Vec3, points []mgl64.Vec2, holes [][]mgl64.Vec2) (*Primitive2D, error) {
+	vertices, uvCoords, err := polygonVertices(points, holes)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Primitive2D{
+		position: center,
+		size:     mgl64.Vec2{1, 1},
+		scale:    mgl64.Vec2{1, 1},
+	}
+	q.shaderProgram = DefaultShaderCache.builtin(VertexShaderBase, "", FragmentShaderSolidColor)
+	q.rebuildMatrices()
+	q.arrayMode = gl.TRIANGLES
+	q.SetVertices(vertices)
+	q.SetUVCoords(uvCoords)
+	return q, nil
+}
+
+// polygonVertices triangulates a polygon and returns the vertices of the triangles with their UV coordinates
+func polygonVertices(points []mgl64.Vec2, holes [][]mgl64.Vec2) ([]float32, []float32, error) {
+	indices, err := utils.TriangulatePolygon(points, holes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(indices) == 0 {
+		return nil, nil, errors.New("the polygon has no area")
+	}
+	all := points
+	for _, hole := range holes {
+		all = append(all[:len(all):len(all)], hole...)
+	}
+
+	topLeft, bottomRight := utils.GetBoundingBox(all)
+	size := bottomRight.Sub(topLeft)
+	vertices := make([]float32, 0, len(indices)*2)
+	uvCoords := make([]float32, 0, len(indices)*2)
+	for _, i := range indices {
+		p := all[i]
+		vertices = append(vertices, float32(p.X()), float32(p.Y()))
+		uvCoords = append(uvCoords, float32((p.X()-topLeft.X())/size.X()), float32((p.Y()-topLeft.Y())/size.Y()))
+	}
+	return vertices, uvCoords, nil
+}
+
 // NewTriangles creates a primitive as a collection of triangles
 func NewTriangles(
 	vertices []float32,
@@ -274,7 +425,7 @@ func NewPolylinePrimitive(center mgl64.Vec3, points []mgl64.Vec2, closed bool) *
 		size:     mgl64.Vec2{1, 1},
 		scale:    mgl64.Vec2{1, 1},
 	}
-	primitive.shaderProgram = NewShaderProgram(VertexShaderBase, "", FragmentShaderSolidColor)
+	primitive.shaderProgram = DefaultShaderCache.builtin(VertexShaderBase, "", FragmentShaderSolidColor)
 	primitive.rebuildMatrices()
 
 	// Vertices
@@ -295,7 +446,60 @@ func NewPolylinePrimitive(center mgl64.Vec3, points []mgl64.Vec2, closed bool) *
 	return primitive
 }
 
-// SetVertices uploads new set of vertices into opengl buffer
+// NewThickPolylinePrimitive creates a primitive from a sequence of points, drawn as triangles with the width, joins,
+// caps and dashes of the style. The points coordinates are relative to the passed center
+func NewThickPolylinePrimitive(center mgl64.Vec3, points []mgl64.Vec2, closed bool, style LineStyle) *Primitive2D {
+	primitive := &Primitive2D{
+		position: center,
+		size:     mgl64.Vec2{1, 1},
+		scale:    mgl64.Vec2{1, 1},
+	}
+	primitive.shaderProgram = DefaultShaderCache.builtin(VertexShaderBase, "", FragmentShaderSolidColor)
+	primitive.rebuildMatrices()
+	primitive.arrayMode = gl.TRIANGLES
+	vertices := TriangulatePolyline(points, closed, style)
+	if len(vertices) == 0 {
+		// Nothing to draw, a point with butt caps or a line with no width
+		return primitive
+	}
+	primitive.SetVertices(vertices)
+	return primitive
+}
+
+// NewDashedPolylinePrimitive creates a thick polyline with butt caps and miter joins, dashes are the lengths
+// of the dashes and of the gaps between them, alternated
+func NewDashedPolylinePrimitive(center mgl64.Vec3, points []mgl64.Vec2, closed bool, width float64, dashes []float64) *Primitive2D {
+	return NewThickPolylinePrimitive(center, points, closed, LineStyle{Width: width, Dashes: dashes})
+}
+
+// NewCurvePrimitive creates a primitive from a curve, tessellated with the given tolerance (0 for the default one).
+// A filled curve is closed with a straight line from its end to its start. The curve coordinates are relative to the passed center
+func NewCurvePrimitive(center mgl64.Vec3, curve utils.Curve, tolerance float64, filled bool) (*Primitive2D, error) {
+	points, closed := curvePoints(curve, tolerance)
+	if filled {
+		return NewPolygonPrimitive(center, points, nil)
+	}
+	return NewPolylinePrimitive(center, points, closed), nil
+}
+
+// NewThickCurvePrimitive creates a primitive from a curve, drawn as triangles with the width, joins, caps and dashes
+// of the style. The curve coordinates are relative to the passed center
+func NewThickCurvePrimitive(center mgl64.Vec3, curve utils.Curve, tolerance float64, style LineStyle) *Primitive2D {
+	points, closed := curvePoints(curve, tolerance)
+	return NewThickPolylinePrimitive(center, points, closed, style)
+}
+
+// curvePoints tessellates a curve, a closed curve doesn't repeat its first point at the end
+func curvePoints(curve utils.Curve, tolerance float64) ([]mgl64.Vec2, bool) {
+	points := utils.Tessellate(curve, tolerance)
+	n := len(points)
+	if n > 2 && points[0].Sub(points[n-1]).Len() < 1e-9 {
+		return points[:n-1], true
+	}
+	return points, false
+}
+
+// SetVertices uploads new set of vertices into opengl buffer. A copy is kept on the CPU side for batching
 func (p *Primitive2D) SetVertices(vertices []float32) {
 	if p.vaoId == 0 {
 		gl.GenVertexArrays(1, &p.vaoId)
@@ -308,6 +512,7 @@ func (p *Primitive2D) SetVertices(vertices []float32) {
 	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*Float32Size, gl.Ptr(vertices), gl.STATIC_DRAW)
 	gl.EnableVertexAttribArray(0)
 	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
+	p.vertices = vertices
 	p.arraySize = int32(len(vertices) / 2)
 	gl.BindVertexArray(0)
 }
@@ -325,5 +530,26 @@ func (p *Primitive2D) SetUVCoords(uvCoords []float32) {
 	gl.BufferData(gl.ARRAY_BUFFER, len(uvCoords)*Float32Size, gl.Ptr(uvCoords), gl.STATIC_DRAW)
 	gl.EnableVertexAttribArray(1)
 	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
+	p.uvCoords = uvCoords
 	gl.BindVertexArray(0)
 }
+
+// Release deletes the buffers of the primitive, it can't be drawn afterwards. The texture and the shader are shared,
+// they're not released
+func (p *Primitive2D) Release() {
+	if p.vboVertices != 0 {
+		gl.DeleteBuffers(1, &p.vboVertices)
+		p.vboVertices = 0
+	}
+	if p.vboUVCoords != 0 {
+		gl.DeleteBuffers(1, &p.vboUVCoords)
+		p.vboUVCoords = 0
+	}
+	if p.vaoId != 0 {
+		gl.DeleteVertexArrays(1, &p.vaoId)
+		p.vaoId = 0
+	}
+	p.vertices = nil
+	p.uvCoords = nil
+	p.arraySize = 0
+}
@@ -38,7 +38,7 @@ func TestPrimitive2DTransformations(t *testing.T) {
 		t.Errorf("SetSizeFromTexture with no texture failed\nexpected\n%s received\n%s", expected.String(), p.modelMatrix.String())
 	}
 
-	p.texture = &Texture{0, 20, 20}
+	p.texture = &Texture{width: 20, height: 20}
 	p.SetSizeFromTexture()
 	expected = mgl64.Mat4FromRows(mgl64.Vec4{40, 0, 0, 0}, mgl64.Vec4{0, 60, 0, 0}, mgl64.Vec4{0, 0, 1, 0}, mgl64.Vec4{0, 0, 0, 1})
 	if !p.ModelMatrix().ApproxEqual(expected) {
@@ -88,3 +88,26 @@ func TestPrimitive2DTransformations(t *testing.T) {
 	}
 
 }
+
+func TestPolygonVertices(t *testing.T) {
+	points := []mgl64.Vec2{{0, 0}, {0, 10}, {20, 10}, {20, 0}}
+	hole := []mgl64.Vec2{{5, 2}, {15, 2}, {15, 8}, {5, 8}}
+	vertices, uvCoords, err := polygonVertices(points, [][]mgl64.Vec2{hole})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vertices) != 8*3*2 || len(uvCoords) != len(vertices) {
+		t.Fatalf("expected\n%v received\n%v %v", 8*3*2, len(vertices), len(uvCoords))
+	}
+	for i := 0; i < len(vertices); i += 2 {
+		u := float32(vertices[i] / 20)
+		v := float32(vertices[i+1] / 10)
+		if uvCoords[i] != u || uvCoords[i+1] != v {
+			t.Errorf("UV of %v expected\n%v received\n%v", vertices[i:i+2], []float32{u, v}, uvCoords[i:i+2])
+		}
+	}
+
+	if _, _, err := polygonVertices(points[:2], nil); err == nil {
+		t.Errorf("expected an error for a polygon with 2 points")
+	}
+}
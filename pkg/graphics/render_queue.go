@@ -0,0 +1,147 @@
+package graphics
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// RenderStats counters collected while drawing a RenderQueue
+type RenderStats struct {
+	Items           int
+	DrawCalls       int
+	ShaderSwitches  int
+	TextureSwitches int
+}
+
+// positioned is implemented by the drawables exposing a position, the Z is used for sorting
+type positioned interface {
+	Position() mgl64.Vec3
+}
+
+type renderItem struct {
+	drawable    Drawable
+	layer       int
+	z           float64
+	translucent bool
+	order       int
+}
+
+// RenderQueue collects drawables and draws them sorted by layer and Z.
+// Inside a layer, the opaque items are drawn first, grouped by shader and texture to minimise the state changes.
+// The translucent ones follow, back-to-front (higher Z first), so that alpha blending works as expected
+type RenderQueue struct {
+	items []renderItem
+	stats RenderStats
+}
+
+// NewRenderQueue creates an empty queue
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{
+		items: make([]renderItem, 0, 256),
+	}
+}
+
+// Add queues a drawable on a layer, lower layers are drawn first. The Z is taken from the drawable's position, if available
+func (q *RenderQueue) Add(drawable Drawable, layer int, translucent bool) {
+	var z float64
+	if p, ok := drawable.(positioned); ok {
+		z = p.Position().Z()
+	}
+	q.items = append(q.items, renderItem{
+		drawable:    drawable,
+		layer:       layer,
+		z:           z,
+		translucent: translucent,
+		order:       len(q.items),
+	})
+}
+
+// Len returns the number of queued drawables
+func (q *RenderQueue) Len() int {
+	return len(q.items)
+}
+
+// Clear removes all the queued drawables
+func (q *RenderQueue) Clear() {
+	q.items = q.items[:0]
+}
+
+// Sort sorts the queued drawables in drawing order
+func (q *RenderQueue) Sort() {
+	sort.Slice(q.items, func(i, j int) bool {
+		a, b := &q.items[i], &q.items[j]
+		if a.layer != b.layer {
+			return a.layer < b.layer
+		}
+		if a.translucent != b.translucent {
+			return !a.translucent
+		}
+		if a.translucent {
+			if a.z != b.z {
+				return a.z > b.z
+			}
+			return a.order < b.order
+		}
+		shaderA, shaderB := shaderID(a.drawable.Shader()), shaderID(b.drawable.Shader())
+		if shaderA != shaderB {
+			return shaderA < shaderB
+		}
+		textureA, textureB := textureID(a.drawable.Texture()), textureID(b.drawable.Texture())
+		if textureA != textureB {
+			return textureA < textureB
+		}
+		return a.order < b.order
+	})
+}
+
+// Draw sorts and draws all the queued drawables, then empties the queue
+func (q *RenderQueue) Draw(context *Context) {
+	q.Sort()
+	q.stats = RenderStats{Items: len(q.items)}
+	drawCalls := context.DrawCalls()
+	shaderSwitches, textureSwitches := context.ShaderSwitches(), context.TextureSwitches()
+
+	var shader *ShaderProgram
+	var texture *Texture
+	for i, item := range q.items {
+		itemShader := item.drawable.Shader()
+		if itemShader != nil && (i == 0 || itemShader != shader) {
+			context.BindShader(itemShader)
+			context.ApplyProjection(itemShader)
+			shader = itemShader
+		}
+		itemTexture := item.drawable.Texture()
+		if i == 0 || itemTexture != texture {
+			context.BindTexture(itemTexture)
+			texture = itemTexture
+		}
+		item.drawable.DrawInBatch(context)
+	}
+	context.FlushBatch()
+
+	q.stats.DrawCalls = context.DrawCalls() - drawCalls
+	// Counted by the context, the binds done while a batch is active don't always change the GL state
+	q.stats.ShaderSwitches = context.ShaderSwitches() - shaderSwitches
+	q.stats.TextureSwitches = context.TextureSwitches() - textureSwitches
+	q.Clear()
+}
+
+// Stats returns the counters collected during the last Draw
+func (q *RenderQueue) Stats() RenderStats {
+	return q.stats
+}
+
+func shaderID(shader *ShaderProgram) uint32 {
+	if shader == nil {
+		return 0
+	}
+	return shader.id
+}
+
+func textureID(texture *Texture) uint32 {
+	if texture == nil {
+		return 0
+	}
+	return texture.id
+}
@@ -0,0 +1,54 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type testDrawable struct {
+	name     string
+	texture  *Texture
+	shader   *ShaderProgram
+	position mgl64.Vec3
+}
+
+func (d *testDrawable) Texture() *Texture            { return d.texture }
+func (d *testDrawable) Shader() *ShaderProgram       { return d.shader }
+func (d *testDrawable) Position() mgl64.Vec3         { return d.position }
+func (d *testDrawable) Draw(context *Context)        {}
+func (d *testDrawable) DrawInBatch(context *Context) {}
+
+func TestRenderQueueSort(t *testing.T) {
+	shader1 := &ShaderProgram{id: 1}
+	shader2 := &ShaderProgram{id: 2}
+	texture1 := &Texture{id: 1}
+	texture2 := &Texture{id: 2}
+
+	q := NewRenderQueue()
+	q.Add(&testDrawable{"ui", texture1, shader1, mgl64.Vec3{0, 0, 0}}, 1, false)
+	q.Add(&testDrawable{"glass-near", texture2, shader1, mgl64.Vec3{0, 0, -1}}, 0, true)
+	q.Add(&testDrawable{"tree", texture2, shader2, mgl64.Vec3{0, 0, 0}}, 0, false)
+	q.Add(&testDrawable{"rock", texture2, shader1, mgl64.Vec3{0, 0, 0}}, 0, false)
+	q.Add(&testDrawable{"glass-far", texture1, shader2, mgl64.Vec3{0, 0, 1}}, 0, true)
+	q.Add(&testDrawable{"grass", texture1, shader1, mgl64.Vec3{0, 0, 0}}, 0, false)
+	q.Add(&testDrawable{"bush", texture2, shader1, mgl64.Vec3{0, 0, 0}}, 0, false)
+
+	if q.Len() != 7 {
+		t.Errorf("Got %d items, expecting 7", q.Len())
+	}
+
+	q.Sort()
+	expected := []string{"grass", "rock", "bush", "tree", "glass-far", "glass-near", "ui"}
+	for i, item := range q.items {
+		name := item.drawable.(*testDrawable).name
+		if name != expected[i] {
+			t.Errorf("Item #%d is %s, expecting %s", i, name, expected[i])
+		}
+	}
+
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("Clear failed, %d items left", q.Len())
+	}
+}
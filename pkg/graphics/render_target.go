@@ -0,0 +1,159 @@
+package graphics
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/maxfish/gojira2d/pkg/log"
+)
+
+// RenderTarget an offscreen framebuffer with a color texture and an optional depth buffer.
+// Everything drawn on a Context while the target is pushed ends up in its texture. The projection is flipped
+// vertically meanwhile, so that the rows of the texture start from the top like the ones of the textures loaded
+// from images, and it can be shown on a Primitive2D the right way up
+type RenderTarget struct {
+	fboId   uint32
+	depthId uint32
+	texture *Texture
+	width   int32
+	height  int32
+	// The rows start from the bottom, as OpenGL does, used by the PostProcessor drawing its buffers fullscreen
+	bottomUp bool
+}
+
+// renderTargetState keeps what's needed to restore the previous state when a target is popped
+type renderTargetState struct {
+	target   *RenderTarget
+	viewport [4]int32
+}
+
+// NewRenderTarget creates a framebuffer of the specified size, withDepth adds a depth attachment
+func NewRenderTarget(width int, height int, withDepth bool) (*RenderTarget, error) {
+	texture, err := NewEmptyTexture(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RenderTarget{
+		texture: texture,
+		width:   int32(width),
+		height:  int32(height),
+	}
+
+	var previousFbo int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previousFbo)
+
+	gl.GenFramebuffers(1, &r.fboId)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, r.fboId)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, texture.id, 0)
+
+	if withDepth {
+		gl.GenRenderbuffers(1, &r.depthId)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, r.depthId)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, r.width, r.height)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, r.depthId)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+	}
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previousFbo))
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		r.Release()
+		return nil, fmt.Errorf("framebuffer incomplete, status 0x%x", status)
+	}
+
+	return r, nil
+}
+
+// Texture returns the texture the target renders into. It can be assigned to any primitive, with the default UVs
+// it's shown the right way up
+func (r *RenderTarget) Texture() *Texture {
+	return r.texture
+}
+
+// Width returns the target width in pixels
+func (r *RenderTarget) Width() int32 {
+	return r.width
+}
+
+// Height returns the target height in pixels
+func (r *RenderTarget) Height() int32 {
+	return r.height
+}
+
+// HasDepth tells if the target has a depth attachment
+func (r *RenderTarget) HasDepth() bool {
+	return r.depthId != 0
+}
+
+// Clear clears the color, and the depth if present, of the target
+func (r *RenderTarget) Clear(color Color) {
+	var previousFbo int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previousFbo)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, r.fboId)
+	gl.ClearColor(color[0], color[1], color[2], color[3])
+	var mask uint32 = gl.COLOR_BUFFER_BIT
+	if r.HasDepth() {
+		mask |= gl.DEPTH_BUFFER_BIT
+	}
+	gl.Clear(mask)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previousFbo))
+}
+
+// Release releases the framebuffer and its attachments, the texture included
+func (r *RenderTarget) Release() {
+	if r.depthId != 0 {
+		gl.DeleteRenderbuffers(1, &r.depthId)
+		r.depthId = 0
+	}
+	if r.fboId != 0 {
+		gl.DeleteFramebuffers(1, &r.fboId)
+		r.fboId = 0
+	}
+	if r.texture != nil {
+		r.texture.Release()
+		r.texture = nil
+	}
+}
+
+// PushRenderTarget redirects the drawing of this context to the target, until PopRenderTarget is called.
+// Pushes can be nested. The context camera is not changed, the rendering is stretched to the target size
+func (c *Context) PushRenderTarget(target *RenderTarget) {
+	c.FlushBatch()
+
+	state := renderTargetState{target: target}
+	gl.GetIntegerv(gl.VIEWPORT, &state.viewport[0])
+	c.renderTargets = append(c.renderTargets, state)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, target.fboId)
+	gl.Viewport(0, 0, target.width, target.height)
+}
+
+// PopRenderTarget restores the target, and the viewport, active before the last PushRenderTarget
+func (c *Context) PopRenderTarget() {
+	if len(c.renderTargets) == 0 {
+		log.Errorf("PopRenderTarget called with no render target pushed")
+		return
+	}
+	c.FlushBatch()
+
+	last := len(c.renderTargets) - 1
+	state := c.renderTargets[last]
+	c.renderTargets = c.renderTargets[:last]
+
+	var fboId uint32
+	if last > 0 {
+		fboId = c.renderTargets[last-1].target.fboId
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fboId)
+	gl.Viewport(state.viewport[0], state.viewport[1], state.viewport[2], state.viewport[3])
+}
+
+// RenderTarget returns the render target currently pushed on the context, nil when drawing on screen
+func (c *Context) RenderTarget() *RenderTarget {
+	if len(c.renderTargets) == 0 {
+		return nil
+	}
+	return c.renderTargets[len(c.renderTargets)-1].target
+}
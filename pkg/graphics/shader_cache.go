@@ -0,0 +1,138 @@
+package graphics
+
+import "github.com/maxfish/gojira2d/pkg/log"
+
+// DefaultShaderCache is the cache used by the primitives for their built-in programs
+var DefaultShaderCache = NewShaderCache()
+
+// ShaderCache shares the programs built from the same sources, so that each one is compiled only once.
+// Every Acquire has to be matched by a Release, the program is deleted when nobody uses it anymore.
+// The built-in programs of the primitives are never deleted
+type ShaderCache struct {
+	entries map[shaderSources]*shaderCacheEntry
+	hits    int
+	misses  int
+}
+
+// shaderSources the key of a program in the cache. The sources themselves are compared, two programs can't be
+// mistaken for each other like with a hash
+type shaderSources struct {
+	vert, geom, frag string
+}
+
+type shaderCacheEntry struct {
+	program    *ShaderProgram
+	references int
+	builtin    bool
+}
+
+// ShaderCacheStats counters of a ShaderCache
+type ShaderCacheStats struct {
+	// Programs currently in the cache
+	Programs int
+	// References acquired and not released yet, the built-in programs are not counted
+	References int
+	Hits       int
+	Misses     int
+}
+
+// NewShaderCache creates an empty cache
+func NewShaderCache() *ShaderCache {
+	return &ShaderCache{entries: make(map[shaderSources]*shaderCacheEntry)}
+}
+
+// Acquire returns the program built from the sources, compiling it if it's not in the cache yet.
+// The program has to be released with Release, or with its own Release method. Programs failing to build are
+// not cached, see NewShaderProgram for the errors
+func (c *ShaderCache) Acquire(vertSource string, geomSource string, fragSource string) (*ShaderProgram, error) {
+	entry, err := c.entry(vertSource, geomSource, fragSource)
+	if err != nil {
+		return nil, err
+	}
+	entry.references++
+	return entry.program, nil
+}
+
+// MustAcquire is like Acquire but panics if the program can't be built
+func (c *ShaderCache) MustAcquire(vertSource string, geomSource string, fragSource string) *ShaderProgram {
+	program, err := c.Acquire(vertSource, geomSource, fragSource)
+	if err != nil {
+		panic(err)
+	}
+	return program
+}
+
+// builtin returns a program shared by all the primitives, it stays in the cache until Clear.
+// The built-in sources are expected to compile, it panics otherwise
+func (c *ShaderCache) builtin(vertSource string, geomSource string, fragSource string) *ShaderProgram {
+	entry, err := c.entry(vertSource, geomSource, fragSource)
+	if err != nil {
+		panic(err)
+	}
+	entry.builtin = true
+	return entry.program
+}
+
+// Replaced by the tests
+var newShaderProgram = NewShaderProgram
+
+func (c *ShaderCache) entry(vertSource string, geomSource string, fragSource string) (*shaderCacheEntry, error) {
+	key := shaderSources{vertSource, geomSource, fragSource}
+	entry, found := c.entries[key]
+	if found {
+		c.hits++
+		return entry, nil
+	}
+	c.misses++
+	program, err := newShaderProgram(vertSource, geomSource, fragSource)
+	if err != nil {
+		return nil, err
+	}
+	entry = &shaderCacheEntry{program: program}
+	entry.program.cache = c
+	entry.program.cacheKey = key
+	c.entries[key] = entry
+	return entry, nil
+}
+
+// Release gives back a program obtained with Acquire. When it's not referenced anymore it's deleted,
+// together with its shaders
+func (c *ShaderCache) Release(program *ShaderProgram) {
+	entry, found := c.entries[program.cacheKey]
+	if !found || entry.program != program {
+		log.Errorf("releasing a shader program not in the cache")
+		return
+	}
+	if entry.references == 0 {
+		if !entry.builtin {
+			log.Errorf("releasing a shader program more times than acquired")
+		}
+		return
+	}
+	entry.references--
+	if entry.references == 0 && !entry.builtin {
+		delete(c.entries, program.cacheKey)
+		program.release()
+	}
+}
+
+// Clear deletes all the programs of the cache, including the built-in ones. The programs still in use become invalid
+func (c *ShaderCache) Clear() {
+	for key, entry := range c.entries {
+		entry.program.release()
+		delete(c.entries, key)
+	}
+}
+
+// Stats returns the counters of the cache. References growing over time are a sign of programs not released
+func (c *ShaderCache) Stats() ShaderCacheStats {
+	stats := ShaderCacheStats{
+		Programs: len(c.entries),
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+	for _, entry := range c.entries {
+		stats.References += entry.references
+	}
+	return stats
+}
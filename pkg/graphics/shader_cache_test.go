@@ -0,0 +1,55 @@
+package graphics
+
+import "testing"
+
+func TestShaderCacheReferences(t *testing.T) {
+	c := NewShaderCache()
+	key := shaderSources{"vertex", "", "fragment"}
+	program := &ShaderProgram{id: 1, cache: c, cacheKey: key}
+	c.entries[key] = &shaderCacheEntry{program: program}
+
+	for i := 0; i < 3; i++ {
+		if p, _ := c.Acquire("vertex", "", "fragment"); p != program {
+			t.Fatalf("Acquire should return the cached program")
+		}
+	}
+	program.Release()
+	expected := ShaderCacheStats{Programs: 1, References: 2, Hits: 3}
+	if stats := c.Stats(); stats != expected {
+		t.Errorf("expected\n%+v received\n%+v", expected, stats)
+	}
+
+	// Built-in programs are not reference counted
+	builtinKey := shaderSources{"vertex", "", "builtin"}
+	c.entries[builtinKey] = &shaderCacheEntry{program: &ShaderProgram{id: 2, cache: c, cacheKey: builtinKey}}
+	c.builtin("vertex", "", "builtin")
+	c.builtin("vertex", "", "builtin")
+	expected = ShaderCacheStats{Programs: 2, References: 2, Hits: 5}
+	if stats := c.Stats(); stats != expected {
+		t.Errorf("expected\n%+v received\n%+v", expected, stats)
+	}
+}
+
+func TestShaderCacheComparesSources(t *testing.T) {
+	c := NewShaderCache()
+	program := &ShaderProgram{id: 1, cache: c, cacheKey: shaderSources{"ab", "", "c"}}
+	c.entries[program.cacheKey] = &shaderCacheEntry{program: program}
+
+	if p, _ := c.Acquire("ab", "", "c"); p != program {
+		t.Errorf("Acquire should return the cached program")
+	}
+
+	// Moving code between the shaders gives a different program
+	previous := newShaderProgram
+	defer func() { newShaderProgram = previous }()
+	newShaderProgram = func(vertSource string, geomSource string, fragSource string) (*ShaderProgram, error) {
+		return &ShaderProgram{id: 2}, nil
+	}
+	if p, _ := c.Acquire("a", "", "bc"); p == program {
+		t.Errorf("Acquire should build a new program")
+	}
+	expected := ShaderCacheStats{Programs: 2, References: 2, Hits: 1, Misses: 1}
+	if stats := c.Stats(); stats != expected {
+		t.Errorf("expected\n%+v received\n%+v", expected, stats)
+	}
+}
@@ -1,12 +1,13 @@
 package graphics
 
 import (
-	"fmt"
+	"errors"
+	"io/fs"
+	"sort"
 	"strings"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/mathgl/mgl32"
-	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
 // ShaderType Type of the shader
@@ -23,61 +24,181 @@ const (
 type ShaderProgram struct {
 	id       uint32
 	uniforms map[string]int32
+	// Filled after the link
+	activeUniforms map[string]UniformInfo
+	attributes     []AttributeInfo
+	uniformBlocks  []UniformBlockInfo
+	// Errors already logged by LogUniformError
+	loggedErrors map[string]bool
+	// Set for the programs shared through a ShaderCache
+	cache    *ShaderCache
+	cacheKey shaderSources
+	// Set for the programs loaded from files
+	files   *shaderFiles
+	watcher *ShaderWatcher
+}
+
+// ShaderCounters counts the OpenGL objects created by the shader programs, used to detect leaks
+type ShaderCounters struct {
+	// Programs and shaders currently alive
+	Programs int
+	Shaders  int
+	// Programs created and released since the start
+	Created  int
+	Released int
+}
+
+var shaderCounters ShaderCounters
+
+// CurrentShaderCounters returns the counters of the shader programs
+func CurrentShaderCounters() ShaderCounters {
+	return shaderCounters
 }
 
 // NewDefaultShaderProgram creates a base shader that can render solid color pixels
 func NewDefaultShaderProgram() *ShaderProgram {
-	s := ShaderProgram{}
+	return MustNewShaderProgram(VertexShaderBase, "", FragmentShaderSolidColor)
+}
+
+// NewShaderProgram creates a new program using the shaders source code passed as plain text.
+// Every call compiles a new program, use a ShaderCache to share the programs built from the same sources.
+// The error is a *ShaderCompileError or a *ShaderLinkError
+func NewShaderProgram(vertSource string, geomSource string, fragSource string) (*ShaderProgram, error) {
+	s := &ShaderProgram{}
 	s.id = gl.CreateProgram()
+	shaderCounters.Programs++
+	shaderCounters.Created++
+
+	sources := []struct {
+		source     string
+		shaderType ShaderType
+	}{{vertSource, VERTEX}, {geomSource, GEOMETRY}, {fragSource, FRAGMENT}}
+	for _, shader := range sources {
+		if shader.source == "" {
+			continue
+		}
+		if err := s.AttachShader(shader.source, shader.shaderType); err != nil {
+			s.release()
+			return nil, err
+		}
+	}
+
+	if err := s.Link(); err != nil {
+		s.release()
+		return nil, err
+	}
+	return s, nil
+}
 
-	s.AttachShader(VertexShaderBase, VERTEX)
-	s.AttachShader(FragmentShaderSolidColor, FRAGMENT)
+// MustNewShaderProgram is like NewShaderProgram but panics if the program can't be built
+func MustNewShaderProgram(vertSource string, geomSource string, fragSource string) *ShaderProgram {
+	s, err := NewShaderProgram(vertSource, geomSource, fragSource)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
 
-	s.Link()
-	return &s
+// NewShaderProgramFromFiles creates a new program from shader files, pass an empty path to skip a shader.
+// The files can include other files with `#include "path"`, relative to the including file, and the defines are
+// added after the #version directive. The compilation errors report the file and the line where they occurred.
+// The program is watched by DefaultShaderWatcher, that recompiles it when the files change
+func NewShaderProgramFromFiles(vertPath string, geomPath string, fragPath string, defines map[string]string) (*ShaderProgram, error) {
+	return newShaderProgramFromFiles(nil, vertPath, geomPath, fragPath, defines)
 }
 
-// NewShaderProgram creates a new program using the shaders source code passed as plain text
-func NewShaderProgram(vertSource string, geomSource string, fragSource string) *ShaderProgram {
-	s := ShaderProgram{}
-	s.id = gl.CreateProgram()
+// NewShaderProgramFromFS is like NewShaderProgramFromFiles but reads the files from a file system.
+// The files are watched too, the ones without a modification time are never reloaded
+func NewShaderProgramFromFS(fsys fs.FS, vertPath string, geomPath string, fragPath string, defines map[string]string) (*ShaderProgram, error) {
+	return newShaderProgramFromFiles(fsys, vertPath, geomPath, fragPath, defines)
+}
 
-	if vertSource != "" {
-		s.AttachShader(vertSource, VERTEX)
+func newShaderProgramFromFiles(fsys fs.FS, vertPath string, geomPath string, fragPath string, defines map[string]string) (*ShaderProgram, error) {
+	files := &shaderFiles{
+		fsys:    fsys,
+		paths:   [3]string{vertPath, geomPath, fragPath},
+		defines: defines,
 	}
-	if geomSource != "" {
-		s.AttachShader(geomSource, GEOMETRY)
+	id, err := files.build()
+	if err != nil {
+		return nil, err
 	}
-	if fragSource != "" {
-		s.AttachShader(fragSource, FRAGMENT)
-	}
-
-	s.Link()
-	return &s
+	s := &ShaderProgram{id: id, files: files}
+	s.introspect()
+	DefaultShaderWatcher.Watch(s)
+	return s, nil
 }
 
-// Release releases all the resources associated with this program
+// Release releases all the resources associated with this program, the attached shaders included.
+// A program obtained from a ShaderCache is given back to the cache instead
 func (s *ShaderProgram) Release() {
+	if s.watcher != nil {
+		s.watcher.Unwatch(s)
+	}
+	if s.cache != nil {
+		s.cache.Release(s)
+		return
+	}
+	s.release()
+}
+
+func (s *ShaderProgram) release() {
 	if s.id == 0 {
-		fmt.Printf("Error: Trying to release a non initialized shader program")
+		log.Errorf("Trying to release a non initialized shader program")
+		return
 	}
-	// TODO
-	//var shadersId [8]uint32
-	//shaders_id := gl.GetAttachedShaders(s.id, 8, 8, &shadersId )
-	//for id in  shaders_id:
-	//	gl.DetachShader(self._program_id, shader_id)
-	//	gl.DeleteShader(shader_id)
+	deleteProgram(s.id)
+	s.id = 0
+	s.uniforms = nil
+	s.activeUniforms = nil
+	s.attributes = nil
+	s.uniformBlocks = nil
+}
+
+// deleteProgram deletes a program and the shaders attached to it
+func deleteProgram(programID uint32) {
+	var count int32
+	var shaderIDs [8]uint32
+	gl.GetAttachedShaders(programID, int32(len(shaderIDs)), &count, &shaderIDs[0])
+	for _, shaderID := range shaderIDs[:count] {
+		gl.DetachShader(programID, shaderID)
+		gl.DeleteShader(shaderID)
+		shaderCounters.Shaders--
+	}
+
+	gl.DeleteProgram(programID)
+	forgetProgram(programID)
+	shaderCounters.Programs--
+	shaderCounters.Released++
+}
 
-	gl.DeleteProgram(s.id)
+// AttachShader compiles a shader and attaches it to this program. The shader is attached even if it doesn't
+// compile, the error is a *ShaderCompileError
+func (s *ShaderProgram) AttachShader(source string, shaderType ShaderType) error {
+	shaderID, err := compileShader(source, shaderType)
+	gl.AttachShader(s.id, shaderID)
+	return err
+}
+
+// Link links together all the shaders into a shader program, the error is a *ShaderLinkError.
+// After the link the active uniforms, attributes and uniform blocks can be queried
+func (s *ShaderProgram) Link() error {
+	if err := linkProgram(s.id); err != nil {
+		return &ShaderLinkError{Log: err.Error()}
+	}
+	s.introspect()
+	return nil
 }
 
-// AttachShader attaches a shader to this program
-func (s *ShaderProgram) AttachShader(source string, shaderType ShaderType) {
+// compileShader creates and compiles a shader. The shader is returned even if the compilation fails,
+// the error is a *ShaderCompileError
+func compileShader(source string, shaderType ShaderType) (uint32, error) {
 	shaderID := gl.CreateShader(uint32(shaderType))
 	cSource, free := gl.Strs(source)
 	gl.ShaderSource(shaderID, 1, cSource, nil)
 	free()
 	gl.CompileShader(shaderID)
+	shaderCounters.Shaders++
 
 	var status int32
 	gl.GetShaderiv(shaderID, gl.COMPILE_STATUS, &status)
@@ -87,26 +208,26 @@ func (s *ShaderProgram) AttachShader(source string, shaderType ShaderType) {
 
 		logStr := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(shaderID, logLength, nil, gl.Str(logStr))
-
-		fmt.Printf("Error: failed to compile %v: %v", source, logStr)
+		infoLog := strings.TrimRight(logStr, "\x00")
+		return shaderID, &ShaderCompileError{Type: shaderType, Line: shaderLogFirstLine(infoLog), Log: infoLog}
 	}
-	gl.AttachShader(s.id, shaderID)
+	return shaderID, nil
 }
 
-// Link links together all the shaders into a shader program
-func (s *ShaderProgram) Link() {
-	gl.LinkProgram(s.id)
+// linkProgram links a program, the error contains the info log
+func linkProgram(programID uint32) error {
+	gl.LinkProgram(programID)
 	var status int32
-	gl.GetProgramiv(s.id, gl.LINK_STATUS, &status)
+	gl.GetProgramiv(programID, gl.LINK_STATUS, &status)
 	if status == gl.FALSE {
 		var logLength int32
-		gl.GetProgramiv(s.id, gl.INFO_LOG_LENGTH, &logLength)
+		gl.GetProgramiv(programID, gl.INFO_LOG_LENGTH, &logLength)
 
 		logStr := strings.Repeat("\x00", int(logLength+1))
-		gl.GetProgramInfoLog(s.id, logLength, nil, gl.Str(logStr))
-
-		fmt.Printf("Error: failed to link program: %v", logStr)
+		gl.GetProgramInfoLog(programID, logLength, nil, gl.Str(logStr))
+		return errors.New(strings.TrimRight(logStr, "\x00"))
 	}
+	return nil
 }
 
 // ID returns the OpenGL ID assigned to this shader program
@@ -114,72 +235,59 @@ func (s *ShaderProgram) ID() uint32 {
 	return s.id
 }
 
-// GetUniform returns a uniform from the shader. Uses a uniform's location cache to speed up the look up
-func (s *ShaderProgram) GetUniform(name string) int32 {
-	if s.uniforms == nil {
-		s.uniforms = make(map[string]int32)
-	}
-
-	uniform, found := s.uniforms[name]
-	if !found {
-		cname := gl.Str(name + "\x00")
-		uniform = gl.GetUniformLocation(s.id, cname)
-		s.uniforms[name] = uniform
+// Files returns the files a program loaded from files has been built from, the included ones too, sorted.
+// It's nil for the programs built from sources
+func (s *ShaderProgram) Files() []string {
+	if s.files == nil {
+		return nil
 	}
-
-	return uniform
-}
-
-// SetUniform sets the shader's uniforms based on the type of the value passed
-func (s *ShaderProgram) SetUniform(name string, val interface{}) {
-	uniform := s.GetUniform(name)
-	switch v := val.(type) {
-	case *float32:
-		gl.Uniform1fv(uniform, 1, v)
-	case *mgl32.Vec2:
-		gl.Uniform2fv(uniform, 1, &(*v)[0])
-	case *mgl32.Vec3:
-		gl.Uniform3fv(uniform, 1, &(*v)[0])
-	case *mgl32.Vec4:
-		gl.Uniform4fv(uniform, 1, &(*v)[0])
-	case *mgl32.Mat2:
-		gl.UniformMatrix2fv(uniform, 1, false, &(*v)[0])
-	case *mgl32.Mat3:
-		gl.UniformMatrix3fv(uniform, 1, false, &(*v)[0])
-	case *mgl32.Mat4:
-		gl.UniformMatrix4fv(uniform, 1, false, &(*v)[0])
-	case *Color:
-		gl.Uniform4fv(uniform, 1, &(*v)[0])
-	case *float64:
-	case *mgl64.Vec2:
-	case *mgl64.Vec3:
-	case *mgl64.Vec4:
-	case *mgl64.Mat2:
-	case *mgl64.Mat3:
-	case *mgl64.Mat4:
-		fmt.Printf("Error: this method accepts only float32 values. Value type: %T %+v", val, val)
-	default:
-		fmt.Printf("Error: unknown value type: %T %+v", val, val)
+	files := make([]string, 0, len(s.files.modTimes))
+	for file := range s.files.modTimes {
+		files = append(files, file)
 	}
+	sort.Strings(files)
+	return files
 }
 
 const (
-	// VertexShaderBase is the simplest vertex shader you can have. It uses only the model and the projection matrix
+	// VertexShaderBase is the simplest vertex shader you can have. It uses only the model matrix and the projection
+	// of the camera block.
+	// The vertex Z defaults to 0 for 2D vertices, a SpriteBatch uses it to pass the depth of pre-transformed vertices
 	VertexShaderBase = `
         #version 410 core
+        ` + ShaderCameraBlock + `
+        uniform mat4 model;
+
+        layout(location=0) in vec3 vertex;
+        layout(location=1) in vec2 uv;
+
+        out vec2 uv_out;
+
+        void main() {
+            vec4 vertex_world = model * vec4(vertex, 1);
+            gl_Position = projection * vertex_world;
+            uv_out = uv;
+        }
+        ` + "\x00"
 
+	// VertexShaderColor passes the color of each vertex to the fragment shader, used by ColoredMesh
+	VertexShaderColor = `
+        #version 410 core
+        ` + ShaderCameraBlock + `
         uniform mat4 model;
-        uniform mat4 projection;
 
-        layout(location=0) in vec2 vertex;
+        layout(location=0) in vec3 vertex;
         layout(location=1) in vec2 uv;
+        layout(location=2) in vec4 vertex_color;
 
         out vec2 uv_out;
+        out vec4 color_out;
 
         void main() {
-            vec4 vertex_world = model * vec4(vertex, 0, 1);
+            vec4 vertex_world = model * vec4(vertex, 1);
             gl_Position = projection * vertex_world;
             uv_out = uv;
+            color_out = vertex_color;
         }
         ` + "\x00"
 
@@ -211,4 +319,48 @@ const (
             color = texture(tex, uv_out);
         }
         ` + "\x00"
+
+	// FragmentShaderTextureColor implements a texture mapping tinted by the color, alpha included
+	FragmentShaderTextureColor = `
+        #version 410 core
+
+        in vec2 uv_out;
+        out vec4 out_color;
+        uniform vec4 color;
+
+        uniform sampler2D tex;
+
+        void main() {
+            out_color = texture(tex, uv_out) * color;
+        }
+        ` + "\x00"
+
+	// FragmentShaderVertexColor uses the color of the vertices, to be paired with VertexShaderColor
+	FragmentShaderVertexColor = `
+        #version 410 core
+
+        in vec2 uv_out;
+        in vec4 color_out;
+        out vec4 out_color;
+
+        void main() {
+            out_color = color_out;
+        }
+        ` + "\x00"
+
+	// FragmentShaderTextureVertexColor implements a texture mapping tinted by the color of the vertices,
+	// to be paired with VertexShaderColor
+	FragmentShaderTextureVertexColor = `
+        #version 410 core
+
+        in vec2 uv_out;
+        in vec4 color_out;
+        out vec4 out_color;
+
+        uniform sampler2D tex;
+
+        void main() {
+            out_color = texture(tex, uv_out) * color_out;
+        }
+        ` + "\x00"
 )
@@ -0,0 +1,279 @@
+package graphics
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ShaderSource the source of a shader loaded from a file, after the #include directives have been replaced by the
+// included files. It remembers where every line comes from, to report the errors on the original files
+type ShaderSource struct {
+	Code string
+	// Files read, the main one first
+	Files []string
+	// File and line (1 based) of every line of Code
+	lines []sourceLine
+}
+
+type sourceLine struct {
+	file int
+	line int
+}
+
+var includeDirective = regexp.MustCompile(`^\s*#\s*include\s+["<]([^">]+)[">]\s*$`)
+var versionDirective = regexp.MustCompile(`^\s*#\s*version\b`)
+
+// LoadShaderSource reads a shader file resolving its #include directives, paths are relative to the including file.
+// The defines are added right after the #version directive, as "#define name value"
+func LoadShaderSource(filePath string, defines map[string]string) (*ShaderSource, error) {
+	return loadShaderSource(nil, filePath, defines)
+}
+
+// LoadShaderSourceFS is like LoadShaderSource but reads the files from a file system
+func LoadShaderSourceFS(fsys fs.FS, filePath string, defines map[string]string) (*ShaderSource, error) {
+	return loadShaderSource(fsys, filePath, defines)
+}
+
+// loadShaderSource reads the files from fsys, or from the OS when it's nil
+func loadShaderSource(fsys fs.FS, filePath string, defines map[string]string) (*ShaderSource, error) {
+	s := &ShaderSource{}
+	var code []string
+	if err := s.load(fsys, filePath, nil, &code); err != nil {
+		return nil, err
+	}
+
+	if len(defines) > 0 {
+		names := make([]string, 0, len(defines))
+		for name := range defines {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		injected := make([]string, 0, len(names))
+		for _, name := range names {
+			injected = append(injected, strings.TrimSpace("#define "+name+" "+defines[name]))
+		}
+		at := 0
+		for i, line := range code {
+			if versionDirective.MatchString(line) {
+				at = i + 1
+				break
+			}
+		}
+		// The defines have no line of their own, they are reported on the #version directive
+		injectedLines := make([]sourceLine, len(injected))
+		for i := range injectedLines {
+			injectedLines[i] = sourceLine{0, 1}
+			if at > 0 {
+				injectedLines[i] = s.lines[at-1]
+			}
+		}
+		code = append(code[:at], append(injected, code[at:]...)...)
+		s.lines = append(s.lines[:at], append(injectedLines, s.lines[at:]...)...)
+	}
+
+	s.Code = strings.Join(code, "\n") + "\n"
+	return s, nil
+}
+
+// load appends the lines of a file to code, stack contains the files being included to detect the cycles
+func (s *ShaderSource) load(fsys fs.FS, filePath string, stack []string, code *[]string) error {
+	for _, f := range stack {
+		if f == filePath {
+			return fmt.Errorf("%s: recursive #include", filePath)
+		}
+	}
+	var data []byte
+	var err error
+	if fsys == nil {
+		data, err = ioutil.ReadFile(filePath)
+	} else {
+		data, err = fs.ReadFile(fsys, filePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	fileIndex := -1
+	for i, f := range s.Files {
+		if f == filePath {
+			fileIndex = i
+		}
+	}
+	if fileIndex < 0 {
+		fileIndex = len(s.Files)
+		s.Files = append(s.Files, filePath)
+	}
+
+	stack = append(stack, filePath)
+	lines := strings.Split(strings.TrimRight(strings.Replace(string(data), "\r\n", "\n", -1), "\n"), "\n")
+	for i, line := range lines {
+		if match := includeDirective.FindStringSubmatch(line); match != nil {
+			included := filepath.Join(filepath.Dir(filePath), match[1])
+			if fsys != nil {
+				included = path.Join(path.Dir(filePath), match[1])
+			}
+			if err := s.load(fsys, included, stack, code); err != nil {
+				return fmt.Errorf("%s:%d: %v", filePath, i+1, err)
+			}
+			continue
+		}
+		*code = append(*code, line)
+		s.lines = append(s.lines, sourceLine{fileIndex, i + 1})
+	}
+	return nil
+}
+
+// Location returns the file and the line of a line of Code (1 based)
+func (s *ShaderSource) Location(line int) (string, int) {
+	if line < 1 || line > len(s.lines) {
+		return "", 0
+	}
+	l := s.lines[line-1]
+	return s.Files[l.file], l.line
+}
+
+// TranslateLog rewrites the line numbers of a compilation log as "file:line"
+func (s *ShaderSource) TranslateLog(log string) string {
+	lines := strings.Split(strings.TrimRight(log, "\x00\n"), "\n")
+	for i, line := range lines {
+		for _, format := range shaderLogLine {
+			match := format.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(match[2])
+			if file, fileLine := s.Location(n); file != "" {
+				lines[i] = fmt.Sprintf("%s%s:%d%s", match[1], file, fileLine, match[3])
+			}
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// shaderFiles the files a program has been built from
+type shaderFiles struct {
+	// Where the files are read from, the OS when nil
+	fsys fs.FS
+	// Vertex, geometry and fragment shaders, empty when not used
+	paths   [3]string
+	defines map[string]string
+	// Modification time of all the files read, the included ones too
+	modTimes map[string]time.Time
+}
+
+var shaderFileTypes = [3]ShaderType{VERTEX, GEOMETRY, FRAGMENT}
+
+// build compiles and links a new program from the files, nothing is left behind if it fails
+func (f *shaderFiles) build() (uint32, error) {
+	modTimes := make(map[string]time.Time)
+	sources := make([]*ShaderSource, 0, len(f.paths))
+	types := make([]ShaderType, 0, len(f.paths))
+	for i, filePath := range f.paths {
+		if filePath == "" {
+			continue
+		}
+		modTimes[filePath] = f.modTime(filePath)
+		source, err := loadShaderSource(f.fsys, filePath, f.defines)
+		if err != nil {
+			f.modTimes = mergeModTimes(f.modTimes, modTimes)
+			return 0, err
+		}
+		for _, file := range source.Files {
+			if _, found := modTimes[file]; !found {
+				modTimes[file] = f.modTime(file)
+			}
+		}
+		sources = append(sources, source)
+		types = append(types, shaderFileTypes[i])
+	}
+	// Failed builds are retried only when the files change again
+	f.modTimes = mergeModTimes(f.modTimes, modTimes)
+
+	id := gl.CreateProgram()
+	shaderCounters.Programs++
+	shaderCounters.Created++
+	for i, source := range sources {
+		shaderID, err := compileShader(source.Code+"\x00", types[i])
+		gl.AttachShader(id, shaderID)
+		if compileErr, ok := err.(*ShaderCompileError); ok {
+			deleteProgram(id)
+			compileErr.File, compileErr.Line = source.Location(compileErr.Line)
+			if compileErr.File == "" {
+				compileErr.File = source.Files[0]
+			}
+			compileErr.Log = source.TranslateLog(compileErr.Log)
+			return 0, compileErr
+		}
+	}
+	if err := linkProgram(id); err != nil {
+		deleteProgram(id)
+		return 0, &ShaderLinkError{Files: f.usedPaths(), Log: err.Error()}
+	}
+	f.modTimes = modTimes
+	return id, nil
+}
+
+func (f *shaderFiles) usedPaths() []string {
+	var paths []string
+	for _, path := range f.paths {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// changed tells if any of the files has been modified since the last build. Files that can't be read are ignored,
+// editors often delete and write again the files when saving
+func (f *shaderFiles) changed() bool {
+	for file, modTime := range f.modTimes {
+		current := f.modTime(file)
+		if !current.IsZero() && !current.Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// modTime returns the modification time of one of the files, the zero time if it doesn't exist
+func (f *shaderFiles) modTime(filePath string) time.Time {
+	if f.fsys == nil {
+		return fileModTime(filePath)
+	}
+	info, err := fs.Stat(f.fsys, filePath)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// fileModTime returns the modification time of a file, the zero time if it doesn't exist
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func mergeModTimes(dst map[string]time.Time, src map[string]time.Time) map[string]time.Time {
+	if dst == nil {
+		dst = make(map[string]time.Time, len(src))
+	}
+	for file, modTime := range src {
+		dst[file] = modTime
+	}
+	return dst
+}
@@ -0,0 +1,210 @@
+package graphics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func writeShaderFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "shaders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, code := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadShaderSource(t *testing.T) {
+	dir := writeShaderFiles(t, map[string]string{
+		"main.frag":        "#version 410 core\n#include \"lib/light.glsl\"\nvoid main() {\n}\n",
+		"lib/light.glsl":   "#include \"common.glsl\"\nfloat light;\n",
+		"lib/common.glsl":  "float common;\n",
+		"recursive.frag":   "#include \"recursive.frag\"\n",
+		"missing.frag":     "void main() {}\n#include \"nothing.glsl\"\n",
+		"noversion.frag":   "void main() {}\n",
+		"windows_eol.frag": "#version 410 core\r\nvoid main() {}\r\n",
+	})
+	defer os.RemoveAll(dir)
+
+	source, err := LoadShaderSource(filepath.Join(dir, "main.frag"), map[string]string{"QUALITY": "2", "DEBUG": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "#version 410 core\n#define DEBUG\n#define QUALITY 2\nfloat common;\nfloat light;\nvoid main() {\n}\n"
+	if source.Code != expected {
+		t.Errorf("expected\n%v received\n%v", expected, source.Code)
+	}
+
+	locations := []struct {
+		line int
+		file string
+		at   int
+	}{
+		{1, "main.frag", 1},
+		{2, "main.frag", 1},
+		{3, "main.frag", 1},
+		{4, "lib/common.glsl", 1},
+		{5, "lib/light.glsl", 2},
+		{6, "main.frag", 3},
+		{7, "main.frag", 4},
+	}
+	for _, l := range locations {
+		file, at := source.Location(l.line)
+		if file != filepath.Join(dir, l.file) || at != l.at {
+			t.Errorf("line %d: expected\n%v:%v received\n%v:%v", l.line, l.file, l.at, file, at)
+		}
+	}
+	if file, _ := source.Location(8); file != "" {
+		t.Errorf("expected no file after the last line, received %v", file)
+	}
+
+	source, err = LoadShaderSource(filepath.Join(dir, "noversion.frag"), map[string]string{"A": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected = "#define A 1\nvoid main() {}\n"; source.Code != expected {
+		t.Errorf("expected\n%v received\n%v", expected, source.Code)
+	}
+
+	source, err = LoadShaderSource(filepath.Join(dir, "windows_eol.frag"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected = "#version 410 core\nvoid main() {}\n"; source.Code != expected {
+		t.Errorf("expected\n%v received\n%v", expected, source.Code)
+	}
+
+	if _, err = LoadShaderSource(filepath.Join(dir, "recursive.frag"), nil); err == nil || !strings.Contains(err.Error(), "recursive") {
+		t.Errorf("expected a recursion error, received %v", err)
+	}
+	_, err = LoadShaderSource(filepath.Join(dir, "missing.frag"), nil)
+	if err == nil || !strings.HasPrefix(err.Error(), filepath.Join(dir, "missing.frag")+":2:") {
+		t.Errorf("expected an error on missing.frag:2, received %v", err)
+	}
+}
+
+func TestShaderSourceTranslateLog(t *testing.T) {
+	source := &ShaderSource{
+		Files: []string{"main.frag", "lib.glsl"},
+		lines: []sourceLine{{0, 1}, {1, 1}, {1, 2}, {0, 3}},
+	}
+	logs := []struct {
+		log      string
+		expected string
+	}{
+		{"0(3) : error C1008: undefined variable \"x\"\n", "lib.glsl:2 : error C1008: undefined variable \"x\""},
+		{"ERROR: 0:4: 'y' : undeclared identifier\x00", "ERROR: main.frag:3: 'y' : undeclared identifier"},
+		{"WARNING: 0:2: unused\nERROR: 0:9: out of range", "WARNING: lib.glsl:1: unused\nERROR: 0:9: out of range"},
+		{"ERROR: too many errors", "ERROR: too many errors"},
+	}
+	for _, l := range logs {
+		if translated := source.TranslateLog(l.log); translated != l.expected {
+			t.Errorf("expected\n%v received\n%v", l.expected, translated)
+		}
+	}
+}
+
+func TestShaderWatcherCheck(t *testing.T) {
+	dir := writeShaderFiles(t, map[string]string{
+		"main.frag": "#include \"lib.glsl\"\n",
+		"lib.glsl":  "float lib;\n",
+	})
+	defer os.RemoveAll(dir)
+	main := filepath.Join(dir, "main.frag")
+	lib := filepath.Join(dir, "lib.glsl")
+
+	source, err := LoadShaderSource(main, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := &shaderFiles{paths: [3]string{"", "", main}, modTimes: make(map[string]time.Time)}
+	for _, file := range source.Files {
+		files.modTimes[file] = fileModTime(file)
+	}
+	program := &ShaderProgram{id: 1, files: files}
+
+	w := NewShaderWatcher()
+	reloads := 0
+	w.reload = func(p *ShaderProgram) error {
+		reloads++
+		p.files.modTimes[lib] = fileModTime(lib)
+		return nil
+	}
+	w.Watch(program)
+
+	w.Update(1)
+	if reloads != 0 {
+		t.Errorf("A disabled watcher should not check the files")
+	}
+	w.SetEnabled(true)
+	w.Update(1)
+	if reloads != 0 {
+		t.Errorf("No file changed, expected no reloads, received %v", reloads)
+	}
+
+	// An included file changes
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(lib, future, future); err != nil {
+		t.Fatal(err)
+	}
+	w.Update(DefaultShaderWatchInterval / 2)
+	if reloads != 0 {
+		t.Errorf("The files should be checked only every interval")
+	}
+	w.Update(DefaultShaderWatchInterval / 2)
+	if reloads != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, reloads)
+	}
+	w.Update(DefaultShaderWatchInterval)
+	if reloads != 1 {
+		t.Errorf("A reloaded program should not be reloaded again, received %v reloads", reloads)
+	}
+
+	// A file being saved is ignored until it's back
+	os.Remove(lib)
+	if w.Check() != 0 || reloads != 1 {
+		t.Errorf("Missing files should be ignored")
+	}
+
+	w.Unwatch(program)
+	if len(w.programs) != 0 || program.watcher != nil {
+		t.Errorf("An unwatched program should not be in the watcher anymore")
+	}
+}
+
+func TestLoadShaderSourceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"shaders/main.frag":      {Data: []byte("#version 410 core\n#include \"lib/light.glsl\"\nvoid main() {}\n")},
+		"shaders/lib/light.glsl": {Data: []byte("#include \"../common.glsl\"\nfloat light;\n")},
+		"shaders/common.glsl":    {Data: []byte("float common;\n")},
+		"shaders/recursive.frag": {Data: []byte("#include \"recursive.frag\"\n")},
+	}
+
+	source, err := LoadShaderSourceFS(fsys, "shaders/main.frag", map[string]string{"A": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "#version 410 core\n#define A 1\nfloat common;\nfloat light;\nvoid main() {}\n"
+	if source.Code != expected {
+		t.Errorf("expected\n%v received\n%v", expected, source.Code)
+	}
+	if file, line := source.Location(4); file != "shaders/lib/light.glsl" || line != 2 {
+		t.Errorf("expected\n%v received\n%v:%v", "shaders/lib/light.glsl:2", file, line)
+	}
+	if _, err = LoadShaderSourceFS(fsys, "shaders/recursive.frag", nil); err == nil {
+		t.Errorf("expected an error for a recursive include")
+	}
+}
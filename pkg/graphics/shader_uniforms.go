@@ -0,0 +1,511 @@
+package graphics
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/log"
+)
+
+// UniformInfo an active uniform of a program, as reported by OpenGL after the link
+type UniformInfo struct {
+	// Name without the "[0]" suffix of the arrays
+	Name string
+	// OpenGL type, like gl.FLOAT_VEC4 or gl.SAMPLER_2D
+	Type uint32
+	// Number of elements, 1 for the uniforms which are not arrays
+	Size     int32
+	Location int32
+}
+
+// AttributeInfo an active vertex attribute of a program
+type AttributeInfo struct {
+	Name     string
+	Type     uint32
+	Size     int32
+	Location int32
+}
+
+// UniformBlockInfo an active uniform block of a program
+type UniformBlockInfo struct {
+	Name  string
+	Index uint32
+	// Size of the data of the block, in bytes
+	Size    int32
+	Binding uint32
+}
+
+type uniformKind int
+
+const (
+	uniformFloat uniformKind = iota
+	uniformInt
+	uniformMatrix
+	// Only used by the OpenGL types, the values are floats or ints
+	uniformBool
+	uniformSampler
+)
+
+type uniformType struct {
+	kind uniformKind
+	// Components of each element, 4, 9 or 16 for the matrices
+	components int
+}
+
+// OpenGL types of the uniforms that can be set, the others are not validated
+var uniformTypes = map[uint32]uniformType{
+	gl.FLOAT:                   {uniformFloat, 1},
+	gl.FLOAT_VEC2:              {uniformFloat, 2},
+	gl.FLOAT_VEC3:              {uniformFloat, 3},
+	gl.FLOAT_VEC4:              {uniformFloat, 4},
+	gl.INT:                     {uniformInt, 1},
+	gl.INT_VEC2:                {uniformInt, 2},
+	gl.INT_VEC3:                {uniformInt, 3},
+	gl.INT_VEC4:                {uniformInt, 4},
+	gl.BOOL:                    {uniformBool, 1},
+	gl.BOOL_VEC2:               {uniformBool, 2},
+	gl.BOOL_VEC3:               {uniformBool, 3},
+	gl.BOOL_VEC4:               {uniformBool, 4},
+	gl.FLOAT_MAT2:              {uniformMatrix, 4},
+	gl.FLOAT_MAT3:              {uniformMatrix, 9},
+	gl.FLOAT_MAT4:              {uniformMatrix, 16},
+	gl.SAMPLER_1D:              {uniformSampler, 1},
+	gl.SAMPLER_2D:              {uniformSampler, 1},
+	gl.SAMPLER_3D:              {uniformSampler, 1},
+	gl.SAMPLER_CUBE:            {uniformSampler, 1},
+	gl.SAMPLER_2D_SHADOW:       {uniformSampler, 1},
+	gl.SAMPLER_2D_ARRAY:        {uniformSampler, 1},
+	gl.SAMPLER_2D_RECT:         {uniformSampler, 1},
+	gl.SAMPLER_2D_MULTISAMPLE:  {uniformSampler, 1},
+	gl.SAMPLER_BUFFER:          {uniformSampler, 1},
+	gl.INT_SAMPLER_2D:          {uniformSampler, 1},
+	gl.UNSIGNED_INT_SAMPLER_2D: {uniformSampler, 1},
+}
+
+// uniformValue a value converted to the data passed to OpenGL
+type uniformValue struct {
+	kind       uniformKind
+	components int
+	floats     []float32
+	ints       []int32
+}
+
+// count returns the number of elements of the value, more than 1 for the arrays
+func (v uniformValue) count() int {
+	if v.kind == uniformInt {
+		return len(v.ints) / v.components
+	}
+	return len(v.floats) / v.components
+}
+
+var matrixTypes = map[reflect.Type]bool{
+	reflect.TypeOf(mgl32.Mat2{}): true,
+	reflect.TypeOf(mgl32.Mat3{}): true,
+	reflect.TypeOf(mgl32.Mat4{}): true,
+	reflect.TypeOf(mgl64.Mat2{}): true,
+	reflect.TypeOf(mgl64.Mat3{}): true,
+	reflect.TypeOf(mgl64.Mat4{}): true,
+}
+
+// convertUniformValue converts a value, or a pointer to it, to the data of a uniform. It accepts numbers, booleans,
+// vectors, matrices and colors, in both float32 and float64 versions, and slices of them for the arrays
+func convertUniformValue(val interface{}) (uniformValue, error) {
+	if u, ok := pointerUniformValue(val); ok {
+		return u, nil
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return uniformValue{}, fmt.Errorf("nil value")
+		}
+		v = v.Elem()
+	}
+	elements := []reflect.Value{v}
+	if v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return uniformValue{}, fmt.Errorf("empty array")
+		}
+		elements = make([]reflect.Value, v.Len())
+		for i := range elements {
+			elements[i] = v.Index(i)
+		}
+	}
+
+	var u uniformValue
+	for i, e := range elements {
+		kind, components := uniformFloat, 1
+		switch e.Kind() {
+		case reflect.Float32, reflect.Float64:
+			u.floats = append(u.floats, float32(e.Float()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			kind = uniformInt
+			u.ints = append(u.ints, int32(e.Int()))
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			kind = uniformInt
+			u.ints = append(u.ints, int32(e.Uint()))
+		case reflect.Bool:
+			kind = uniformInt
+			u.ints = append(u.ints, int32(boolToInt(e.Bool())))
+		case reflect.Array:
+			components = e.Len()
+			if matrixTypes[e.Type()] {
+				kind = uniformMatrix
+			} else if components < 2 || components > 4 {
+				return uniformValue{}, fmt.Errorf("unsupported type %T", val)
+			}
+			elemKind := e.Type().Elem().Kind()
+			if elemKind != reflect.Float32 && elemKind != reflect.Float64 {
+				return uniformValue{}, fmt.Errorf("unsupported type %T", val)
+			}
+			for j := 0; j < components; j++ {
+				u.floats = append(u.floats, float32(e.Index(j).Float()))
+			}
+		default:
+			return uniformValue{}, fmt.Errorf("unsupported type %T", val)
+		}
+		if i == 0 {
+			u.kind, u.components = kind, components
+		}
+	}
+	return u, nil
+}
+
+// pointerUniformValue converts without any allocation the values set at every draw call
+func pointerUniformValue(val interface{}) (uniformValue, bool) {
+	switch v := val.(type) {
+	case *mgl32.Vec2:
+		if v != nil {
+			return uniformValue{kind: uniformFloat, components: 2, floats: v[:]}, true
+		}
+	case *mgl32.Vec3:
+		if v != nil {
+			return uniformValue{kind: uniformFloat, components: 3, floats: v[:]}, true
+		}
+	case *mgl32.Vec4:
+		if v != nil {
+			return uniformValue{kind: uniformFloat, components: 4, floats: v[:]}, true
+		}
+	case *Color:
+		if v != nil {
+			return uniformValue{kind: uniformFloat, components: 4, floats: v[:]}, true
+		}
+	case *mgl32.Mat4:
+		if v != nil {
+			return uniformValue{kind: uniformMatrix, components: 16, floats: v[:]}, true
+		}
+	}
+	return uniformValue{}, false
+}
+
+// checkUniformType tells if a value can be assigned to a uniform, starting from the element index of the arrays
+func checkUniformType(info UniformInfo, index int, value uniformValue) error {
+	t, known := uniformTypes[info.Type]
+	if !known {
+		return nil
+	}
+	compatible := value.components == t.components
+	switch t.kind {
+	case uniformFloat, uniformInt, uniformMatrix:
+		compatible = compatible && value.kind == t.kind
+	case uniformBool:
+		compatible = compatible && value.kind != uniformMatrix
+	case uniformSampler:
+		compatible = compatible && value.kind == uniformInt
+	}
+	if !compatible {
+		return fmt.Errorf("value of %d %s components doesn't match the type 0x%x", value.components, value.kind, info.Type)
+	}
+	if index+value.count() > int(info.Size) {
+		return fmt.Errorf("%d values don't fit in %d elements starting at %d", value.count(), info.Size, index)
+	}
+	return nil
+}
+
+func (k uniformKind) String() string {
+	switch k {
+	case uniformFloat:
+		return "float"
+	case uniformInt:
+		return "int"
+	case uniformMatrix:
+		return "matrix"
+	case uniformBool:
+		return "bool"
+	}
+	return "sampler"
+}
+
+// splitUniformName splits "name[index]" in name and index, the index is 0 for the names without it
+func splitUniformName(name string) (string, int) {
+	if !strings.HasSuffix(name, "]") {
+		return name, 0
+	}
+	open := strings.LastIndex(name, "[")
+	if open < 0 {
+		return name, 0
+	}
+	index, err := strconv.Atoi(name[open+1 : len(name)-1])
+	if err != nil || index < 0 {
+		return name, 0
+	}
+	return name[:open], index
+}
+
+// lookupUniform returns the active uniform a name refers to, and the index of the array element
+func (s *ShaderProgram) lookupUniform(name string) (UniformInfo, int, bool) {
+	if info, found := s.activeUniforms[name]; found {
+		return info, 0, true
+	}
+	base, index := splitUniformName(name)
+	info, found := s.activeUniforms[base]
+	return info, index, found
+}
+
+// introspect queries the active uniforms, attributes and uniform blocks after a successful link
+func (s *ShaderProgram) introspect() {
+	s.uniforms = nil
+	s.loggedErrors = nil
+	s.activeUniforms = make(map[string]UniformInfo)
+	s.attributes = nil
+	s.uniformBlocks = nil
+
+	var count, maxLength int32
+	gl.GetProgramiv(s.id, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	gl.GetProgramiv(s.id, gl.ACTIVE_UNIFORMS, &count)
+	for i := uint32(0); i < uint32(count); i++ {
+		var size int32
+		var xtype uint32
+		name := activeName(maxLength, func(length *int32, buffer *uint8) {
+			gl.GetActiveUniform(s.id, i, maxLength, length, &size, &xtype, buffer)
+		})
+		location := gl.GetUniformLocation(s.id, gl.Str(name+"\x00"))
+		// The members of the uniform blocks have no location
+		if location < 0 {
+			continue
+		}
+		name = strings.TrimSuffix(name, "[0]")
+		s.activeUniforms[name] = UniformInfo{Name: name, Type: xtype, Size: size, Location: location}
+	}
+
+	gl.GetProgramiv(s.id, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+	gl.GetProgramiv(s.id, gl.ACTIVE_ATTRIBUTES, &count)
+	for i := uint32(0); i < uint32(count); i++ {
+		var size int32
+		var xtype uint32
+		name := activeName(maxLength, func(length *int32, buffer *uint8) {
+			gl.GetActiveAttrib(s.id, i, maxLength, length, &size, &xtype, buffer)
+		})
+		location := gl.GetAttribLocation(s.id, gl.Str(name+"\x00"))
+		s.attributes = append(s.attributes, AttributeInfo{Name: name, Type: xtype, Size: size, Location: location})
+	}
+	sort.Slice(s.attributes, func(i, j int) bool { return s.attributes[i].Location < s.attributes[j].Location })
+
+	gl.GetProgramiv(s.id, gl.ACTIVE_UNIFORM_BLOCK_MAX_NAME_LENGTH, &maxLength)
+	gl.GetProgramiv(s.id, gl.ACTIVE_UNIFORM_BLOCKS, &count)
+	for i := uint32(0); i < uint32(count); i++ {
+		name := activeName(maxLength, func(length *int32, buffer *uint8) {
+			gl.GetActiveUniformBlockName(s.id, i, maxLength, length, buffer)
+		})
+		var size, binding int32
+		gl.GetActiveUniformBlockiv(s.id, i, gl.UNIFORM_BLOCK_DATA_SIZE, &size)
+		gl.GetActiveUniformBlockiv(s.id, i, gl.UNIFORM_BLOCK_BINDING, &binding)
+		s.uniformBlocks = append(s.uniformBlocks, UniformBlockInfo{Name: name, Index: i, Size: size, Binding: uint32(binding)})
+	}
+
+	// The camera block is shared by all the programs
+	if _, found := s.UniformBlock(CameraBlockName); found {
+		s.BindUniformBlock(CameraBlockName, CameraBlockBinding)
+	}
+}
+
+// activeName reads the name of an active uniform, attribute or block
+func activeName(maxLength int32, get func(length *int32, buffer *uint8)) string {
+	if maxLength < 1 {
+		maxLength = 1
+	}
+	buffer := make([]uint8, maxLength)
+	var length int32
+	get(&length, &buffer[0])
+	return string(buffer[:length])
+}
+
+// Uniforms returns the active uniforms, sorted by name. It's empty until the program is linked
+func (s *ShaderProgram) Uniforms() []UniformInfo {
+	uniforms := make([]UniformInfo, 0, len(s.activeUniforms))
+	for _, info := range s.activeUniforms {
+		uniforms = append(uniforms, info)
+	}
+	sort.Slice(uniforms, func(i, j int) bool { return uniforms[i].Name < uniforms[j].Name })
+	return uniforms
+}
+
+// ActiveUniform returns an active uniform by name. The uniforms not used by the shaders are removed by the compiler
+func (s *ShaderProgram) ActiveUniform(name string) (UniformInfo, bool) {
+	info, _, found := s.lookupUniform(name)
+	return info, found
+}
+
+// Attributes returns the active vertex attributes, sorted by location
+func (s *ShaderProgram) Attributes() []AttributeInfo {
+	return s.attributes
+}
+
+// AttributeLocation returns the location of a vertex attribute, -1 if it's not active
+func (s *ShaderProgram) AttributeLocation(name string) int32 {
+	for _, a := range s.attributes {
+		if a.Name == name {
+			return a.Location
+		}
+	}
+	return -1
+}
+
+// UniformBlocks returns the active uniform blocks
+func (s *ShaderProgram) UniformBlocks() []UniformBlockInfo {
+	return s.uniformBlocks
+}
+
+// UniformBlock returns an active uniform block by name
+func (s *ShaderProgram) UniformBlock(name string) (UniformBlockInfo, bool) {
+	for _, b := range s.uniformBlocks {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return UniformBlockInfo{}, false
+}
+
+// BindUniformBlock connects a uniform block to a binding point, where a UniformBuffer provides its data
+func (s *ShaderProgram) BindUniformBlock(name string, binding uint32) error {
+	for i, b := range s.uniformBlocks {
+		if b.Name == name {
+			gl.UniformBlockBinding(s.id, b.Index, binding)
+			s.uniformBlocks[i].Binding = binding
+			return nil
+		}
+	}
+	return &UniformError{Name: name, Reason: "not an active uniform block"}
+}
+
+// GetUniform returns a uniform from the shader. Uses a uniform's location cache to speed up the look up
+func (s *ShaderProgram) GetUniform(name string) int32 {
+	if s.uniforms == nil {
+		s.uniforms = make(map[string]int32)
+	}
+
+	uniform, found := s.uniforms[name]
+	if !found {
+		cname := gl.Str(name + "\x00")
+		uniform = gl.GetUniformLocation(s.id, cname)
+		s.uniforms[name] = uniform
+	}
+
+	return uniform
+}
+
+// SetUniform sets the shader's uniforms based on the type of the value passed. It accepts numbers, booleans,
+// vectors, matrices and colors, or pointers to them, and slices for the arrays. The float64 values are converted.
+// The name and the type are checked against the active uniforms, the error is a *UniformError.
+// To set the elements of an array starting from the Nth use "name[N]" as name
+func (s *ShaderProgram) SetUniform(name string, val interface{}) error {
+	value, err := convertUniformValue(val)
+	if err != nil {
+		return &UniformError{Name: name, Reason: err.Error()}
+	}
+	if s.activeUniforms != nil {
+		info, index, found := s.lookupUniform(name)
+		if !found {
+			return &UniformError{Name: name, Reason: "not an active uniform"}
+		}
+		if err := checkUniformType(info, index, value); err != nil {
+			return &UniformError{Name: name, Reason: err.Error()}
+		}
+	}
+	setUniformValue(s.GetUniform(name), value)
+	return nil
+}
+
+// LogUniformError logs an error returned while setting the uniforms of a draw, nil is ignored. Each error is logged
+// once for the program, so that a mismatch doesn't flood the log every frame
+func (s *ShaderProgram) LogUniformError(err error) {
+	if err == nil || s.loggedErrors[err.Error()] {
+		return
+	}
+	if s.loggedErrors == nil {
+		s.loggedErrors = make(map[string]bool)
+	}
+	s.loggedErrors[err.Error()] = true
+	log.Errorf("shader program %d: %v", s.id, err)
+}
+
+// SetUniformInt sets an int uniform
+func (s *ShaderProgram) SetUniformInt(name string, value int32) error {
+	return s.SetUniform(name, &value)
+}
+
+// SetUniformFloat sets a float uniform
+func (s *ShaderProgram) SetUniformFloat(name string, value float32) error {
+	return s.SetUniform(name, &value)
+}
+
+// SetUniformBool sets a bool uniform
+func (s *ShaderProgram) SetUniformBool(name string, value bool) error {
+	return s.SetUniform(name, &value)
+}
+
+// SetUniformSampler sets the texture unit used by a sampler uniform
+func (s *ShaderProgram) SetUniformSampler(name string, textureUnit int) error {
+	unit := int32(textureUnit)
+	return s.SetUniform(name, &unit)
+}
+
+func setUniformValue(location int32, v uniformValue) {
+	count := int32(v.count())
+	switch v.kind {
+	case uniformFloat:
+		switch v.components {
+		case 1:
+			gl.Uniform1fv(location, count, &v.floats[0])
+		case 2:
+			gl.Uniform2fv(location, count, &v.floats[0])
+		case 3:
+			gl.Uniform3fv(location, count, &v.floats[0])
+		case 4:
+			gl.Uniform4fv(location, count, &v.floats[0])
+		}
+	case uniformInt:
+		switch v.components {
+		case 1:
+			gl.Uniform1iv(location, count, &v.ints[0])
+		case 2:
+			gl.Uniform2iv(location, count, &v.ints[0])
+		case 3:
+			gl.Uniform3iv(location, count, &v.ints[0])
+		case 4:
+			gl.Uniform4iv(location, count, &v.ints[0])
+		}
+	case uniformMatrix:
+		switch v.components {
+		case 4:
+			gl.UniformMatrix2fv(location, count, false, &v.floats[0])
+		case 9:
+			gl.UniformMatrix3fv(location, count, false, &v.floats[0])
+		case 16:
+			gl.UniformMatrix4fv(location, count, false, &v.floats[0])
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+package graphics
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/log"
+)
+
+func TestConvertUniformValue(t *testing.T) {
+	f32 := float32(1.5)
+	i32 := int32(3)
+	values := []struct {
+		val      interface{}
+		expected uniformValue
+	}{
+		{&f32, uniformValue{kind: uniformFloat, components: 1, floats: []float32{1.5}}},
+		{2.5, uniformValue{kind: uniformFloat, components: 1, floats: []float32{2.5}}},
+		{&i32, uniformValue{kind: uniformInt, components: 1, ints: []int32{3}}},
+		{7, uniformValue{kind: uniformInt, components: 1, ints: []int32{7}}},
+		{true, uniformValue{kind: uniformInt, components: 1, ints: []int32{1}}},
+		{&mgl32.Vec3{1, 2, 3}, uniformValue{kind: uniformFloat, components: 3, floats: []float32{1, 2, 3}}},
+		{mgl64.Vec2{1, 2}, uniformValue{kind: uniformFloat, components: 2, floats: []float32{1, 2}}},
+		{&Color{1, 0, 0, 1}, uniformValue{kind: uniformFloat, components: 4, floats: []float32{1, 0, 0, 1}}},
+		{mgl64.Mat2{1, 2, 3, 4}, uniformValue{kind: uniformMatrix, components: 4, floats: []float32{1, 2, 3, 4}}},
+		{&mgl64.Mat4{}, uniformValue{kind: uniformMatrix, components: 16, floats: make([]float32, 16)}},
+		{[]float64{1, 2, 3}, uniformValue{kind: uniformFloat, components: 1, floats: []float32{1, 2, 3}}},
+		{[]mgl32.Vec2{{1, 2}, {3, 4}}, uniformValue{kind: uniformFloat, components: 2, floats: []float32{1, 2, 3, 4}}},
+		{[]bool{false, true}, uniformValue{kind: uniformInt, components: 1, ints: []int32{0, 1}}},
+	}
+	for _, v := range values {
+		converted, err := convertUniformValue(v.val)
+		if err != nil {
+			t.Errorf("%T: %v", v.val, err)
+			continue
+		}
+		if !reflect.DeepEqual(converted, v.expected) {
+			t.Errorf("%T: expected\n%+v received\n%+v", v.val, v.expected, converted)
+		}
+	}
+	if v, _ := convertUniformValue([]mgl32.Vec2{{1, 2}, {3, 4}}); v.count() != 2 {
+		t.Errorf("expected\n%v received\n%v", 2, v.count())
+	}
+
+	var nilVec *mgl32.Vec2
+	for _, invalid := range []interface{}{"text", nilVec, []float32{}, [5]float32{}, [2]int{}} {
+		if _, err := convertUniformValue(invalid); err == nil {
+			t.Errorf("%T: expected an error", invalid)
+		}
+	}
+}
+
+func TestCheckUniformType(t *testing.T) {
+	float3, _ := convertUniformValue(mgl32.Vec3{})
+	float1, _ := convertUniformValue(1.0)
+	int1, _ := convertUniformValue(1)
+	mat4, _ := convertUniformValue(mgl32.Mat4{})
+	floats, _ := convertUniformValue([]float32{1, 2, 3})
+	checks := []struct {
+		info       UniformInfo
+		index      int
+		value      uniformValue
+		compatible bool
+	}{
+		{UniformInfo{Type: gl.FLOAT_VEC3, Size: 1}, 0, float3, true},
+		{UniformInfo{Type: gl.FLOAT_VEC4, Size: 1}, 0, float3, false},
+		{UniformInfo{Type: gl.FLOAT, Size: 1}, 0, int1, false},
+		{UniformInfo{Type: gl.INT, Size: 1}, 0, int1, true},
+		{UniformInfo{Type: gl.BOOL, Size: 1}, 0, int1, true},
+		{UniformInfo{Type: gl.BOOL, Size: 1}, 0, float1, true},
+		{UniformInfo{Type: gl.SAMPLER_2D, Size: 1}, 0, int1, true},
+		{UniformInfo{Type: gl.SAMPLER_2D, Size: 1}, 0, float1, false},
+		{UniformInfo{Type: gl.FLOAT_MAT4, Size: 1}, 0, mat4, true},
+		{UniformInfo{Type: gl.FLOAT_VEC4, Size: 4}, 0, mat4, false},
+		{UniformInfo{Type: gl.FLOAT, Size: 3}, 0, floats, true},
+		{UniformInfo{Type: gl.FLOAT, Size: 3}, 1, floats, false},
+		{UniformInfo{Type: gl.FLOAT, Size: 4}, 1, floats, true},
+		// Unknown types are not validated
+		{UniformInfo{Type: gl.UNSIGNED_INT_VEC2, Size: 1}, 0, float3, true},
+	}
+	for i, c := range checks {
+		err := checkUniformType(c.info, c.index, c.value)
+		if (err == nil) != c.compatible {
+			t.Errorf("check %d: expected compatible=%v received %v", i, c.compatible, err)
+		}
+	}
+}
+
+func TestSplitUniformName(t *testing.T) {
+	names := []struct {
+		name  string
+		base  string
+		index int
+	}{
+		{"color", "color", 0},
+		{"lights[3]", "lights", 3},
+		{"lights[0].color", "lights[0].color", 0},
+		{"weird[x]", "weird[x]", 0},
+	}
+	for _, n := range names {
+		base, index := splitUniformName(n.name)
+		if base != n.base || index != n.index {
+			t.Errorf("expected\n%v %v received\n%v %v", n.base, n.index, base, index)
+		}
+	}
+}
+
+func TestSetUniformValidation(t *testing.T) {
+	s := &ShaderProgram{activeUniforms: map[string]UniformInfo{
+		"color":   {Name: "color", Type: gl.FLOAT_VEC4, Size: 1},
+		"offsets": {Name: "offsets", Type: gl.FLOAT_VEC2, Size: 4},
+	}}
+	var uniformErr *UniformError
+	if err := s.SetUniform("missing", 1.0); !errors.As(err, &uniformErr) || uniformErr.Name != "missing" {
+		t.Errorf("expected an error for an unknown uniform, received %v", err)
+	}
+	if err := s.SetUniform("color", mgl32.Vec3{}); !errors.As(err, &uniformErr) {
+		t.Errorf("expected an error for the wrong type, received %v", err)
+	}
+	if err := s.SetUniform("offsets[3]", []mgl32.Vec2{{}, {}}); !errors.As(err, &uniformErr) {
+		t.Errorf("expected an error writing past the end of the array, received %v", err)
+	}
+	if info, found := s.ActiveUniform("offsets[2]"); !found || info.Name != "offsets" {
+		t.Errorf("expected to find the array uniform, received %+v", info)
+	}
+}
+
+func TestUniformBufferBounds(t *testing.T) {
+	b := &UniformBuffer{size: 64}
+	if err := b.SetData(16, make([]float32, 16)); err == nil {
+		t.Errorf("expected an error writing past the end of the buffer")
+	}
+	if err := b.SetData(-4, []float32{1}); err == nil {
+		t.Errorf("expected an error writing before the start of the buffer")
+	}
+}
+
+func TestLogUniformErrorOnce(t *testing.T) {
+	var output bytes.Buffer
+	defer log.SetLogger(log.GetLogger())
+	log.SetLogger(log.NewWriterLogger(&output, log.LevelError))
+
+	s := &ShaderProgram{id: 3, activeUniforms: map[string]UniformInfo{}}
+	s.LogUniformError(nil)
+	for i := 0; i < 3; i++ {
+		// A misspelled parameter, set on every frame
+		s.LogUniformError(s.SetUniform("intensty", 1.0))
+	}
+	if lines := strings.Count(output.String(), "\n"); lines != 1 || !strings.Contains(output.String(), "intensty") {
+		t.Errorf("expected\n%v received\n%v", "one error for intensty", output.String())
+	}
+}
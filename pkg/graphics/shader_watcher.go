@@ -0,0 +1,126 @@
+package graphics
+
+import "github.com/maxfish/gojira2d/pkg/log"
+
+// DefaultShaderWatchInterval seconds between two checks of the shader files
+const DefaultShaderWatchInterval = 0.5
+
+// DefaultShaderWatcher watches the programs created with NewShaderProgramFromFiles, it's disabled by default
+var DefaultShaderWatcher = NewShaderWatcher()
+
+// ShaderWatcher recompiles the programs loaded from files when the files change, to be used during development.
+// A program that compiles is swapped in place, keeping its ShaderProgram, otherwise the previous one stays in use
+type ShaderWatcher struct {
+	programs []*ShaderProgram
+	enabled  bool
+	interval float64
+	elapsed  float64
+	onReload func(program *ShaderProgram, err error)
+	// Replaced by the tests
+	reload func(program *ShaderProgram) error
+}
+
+// NewShaderWatcher creates a disabled watcher checking the files every DefaultShaderWatchInterval seconds
+func NewShaderWatcher() *ShaderWatcher {
+	return &ShaderWatcher{
+		interval: DefaultShaderWatchInterval,
+		reload:   reloadShaderProgram,
+	}
+}
+
+// SetEnabled enables or disables the checks done by Update
+func (w *ShaderWatcher) SetEnabled(enabled bool) {
+	w.enabled = enabled
+	w.elapsed = 0
+}
+
+// Enabled tells if the files are checked by Update
+func (w *ShaderWatcher) Enabled() bool {
+	return w.enabled
+}
+
+// SetInterval sets the seconds between two checks
+func (w *ShaderWatcher) SetInterval(interval float64) {
+	w.interval = interval
+}
+
+// SetOnReload sets a function called after every reload, err is nil if the new program is in use.
+// Without it the errors are printed
+func (w *ShaderWatcher) SetOnReload(onReload func(program *ShaderProgram, err error)) {
+	w.onReload = onReload
+}
+
+// Watch adds a program loaded from files to the watcher
+func (w *ShaderWatcher) Watch(program *ShaderProgram) {
+	if program.files == nil {
+		log.Errorf("only the shader programs loaded from files can be watched")
+		return
+	}
+	if program.watcher != nil {
+		program.watcher.Unwatch(program)
+	}
+	program.watcher = w
+	w.programs = append(w.programs, program)
+}
+
+// Unwatch removes a program from the watcher, the released programs are removed automatically
+func (w *ShaderWatcher) Unwatch(program *ShaderProgram) {
+	for i, p := range w.programs {
+		if p == program {
+			w.programs = append(w.programs[:i], w.programs[i+1:]...)
+			program.watcher = nil
+			return
+		}
+	}
+}
+
+// Update checks the files every interval when the watcher is enabled, call it once per frame
+func (w *ShaderWatcher) Update(deltaTime float64) {
+	if !w.enabled {
+		return
+	}
+	w.elapsed += deltaTime
+	if w.elapsed < w.interval {
+		return
+	}
+	w.elapsed = 0
+	w.Check()
+}
+
+// Check reloads the programs whose files changed, whether the watcher is enabled or not.
+// It returns the number of programs reloaded successfully
+func (w *ShaderWatcher) Check() int {
+	reloaded := 0
+	// The callback may release programs
+	programs := append([]*ShaderProgram(nil), w.programs...)
+	for _, program := range programs {
+		if !program.files.changed() {
+			continue
+		}
+		err := w.reload(program)
+		if err == nil {
+			reloaded++
+		}
+		if w.onReload != nil {
+			w.onReload(program, err)
+		} else if err != nil {
+			log.Errorf("shader reload failed, keeping the previous program: %v", err)
+		}
+	}
+	return reloaded
+}
+
+// reloadShaderProgram builds the program again and, if it succeeds, replaces the old one
+func reloadShaderProgram(program *ShaderProgram) error {
+	id, err := program.files.build()
+	if err != nil {
+		return err
+	}
+	if program.id != 0 {
+		deleteProgram(program.id)
+	}
+	program.id = id
+	// The uniforms and their locations may have changed
+	program.introspect()
+	return nil
+}
@@ -0,0 +1,188 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// DefaultSpriteBatchSize is the default number of vertices a SpriteBatch holds before being flushed (2048 quads)
+	DefaultSpriteBatchSize = 2048 * 6
+	// batchVertexSize number of floats per vertex: X,Y,Z,U,V
+	batchVertexSize = 5
+)
+
+var batchModelMatrix = mgl32.Ident4()
+
+// SpriteBatch collects primitives sharing the same texture, shader and color into a single dynamic VBO.
+// The model matrix of each primitive is applied on the CPU, so a whole batch is drawn with one draw call.
+// Only primitives drawn as triangle fans (quads and filled polygons) can be batched
+type SpriteBatch struct {
+	vaoId         uint32
+	vboId         uint32
+	maxVertices   int
+	vertices      []float32
+	texture       *Texture
+	shaderProgram *ShaderProgram
+	color         Color
+	context       *Context
+	drawCalls     int
+}
+
+// NewSpriteBatch creates a batch able to hold up to maxVertices vertices between two flushes
+func NewSpriteBatch(maxVertices int) *SpriteBatch {
+	b := &SpriteBatch{
+		maxVertices: maxVertices,
+		vertices:    make([]float32, 0, maxVertices*batchVertexSize),
+	}
+
+	stride := int32(batchVertexSize * Float32Size)
+	gl.GenVertexArrays(1, &b.vaoId)
+	gl.BindVertexArray(b.vaoId)
+	gl.GenBuffers(1, &b.vboId)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vboId)
+	gl.BufferData(gl.ARRAY_BUFFER, maxVertices*batchVertexSize*Float32Size, nil, gl.DYNAMIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, stride, gl.PtrOffset(3*Float32Size))
+	gl.BindVertexArray(0)
+	return b
+}
+
+// Begin makes this the active batch of the context. Batchable primitives drawn on the context are collected from now on
+func (b *SpriteBatch) Begin(context *Context) {
+	b.context = context
+	b.drawCalls = 0
+	context.batch = b
+}
+
+// End flushes the pending vertices and detaches the batch from its context
+func (b *SpriteBatch) End() {
+	if b.context == nil {
+		return
+	}
+	b.Flush()
+	if b.context.batch == b {
+		b.context.batch = nil
+	}
+	b.context = nil
+}
+
+// Add queues a primitive. The batch is flushed first if the primitive needs a different state or if it's full
+func (b *SpriteBatch) Add(p *Primitive2D) {
+	numVertices := batchVertexCount(p)
+	if numVertices > b.maxVertices {
+		// It would never fit, draw it on its own
+		b.Flush()
+		p.drawDirect(b.context)
+		return
+	}
+
+	colorChanged := p.color != b.color && hasColorUniform(p.shaderProgram)
+	if len(b.vertices) > 0 && (p.texture != b.texture || p.shaderProgram != b.shaderProgram || colorChanged) {
+		b.Flush()
+	}
+	if b.NumVertices()+numVertices > b.maxVertices {
+		b.Flush()
+	}
+
+	b.texture = p.texture
+	b.shaderProgram = p.shaderProgram
+	b.color = p.color
+	b.vertices = appendBatchVertices(b.vertices, p)
+	pendingBatch = b
+}
+
+// Flush draws all the pending vertices with a single draw call
+func (b *SpriteBatch) Flush() {
+	if len(b.vertices) == 0 || b.context == nil {
+		return
+	}
+
+	b.context.BindShader(b.shaderProgram)
+	b.context.BindTexture(b.texture)
+	b.context.ApplyProjection(b.shaderProgram)
+	b.shaderProgram.LogUniformError(b.shaderProgram.SetUniform("model", &batchModelMatrix))
+	if hasColorUniform(b.shaderProgram) {
+		b.shaderProgram.LogUniformError(b.shaderProgram.SetUniform("color", &b.color))
+	}
+
+	gl.BindVertexArray(b.vaoId)
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.vboId)
+	// Orphans the previous storage so the driver doesn't have to wait for the last draw call
+	gl.BufferData(gl.ARRAY_BUFFER, b.maxVertices*batchVertexSize*Float32Size, nil, gl.DYNAMIC_DRAW)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(b.vertices)*Float32Size, gl.Ptr(b.vertices))
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(b.NumVertices()))
+	gl.BindVertexArray(0)
+
+	b.vertices = b.vertices[:0]
+	b.drawCalls++
+	b.context.drawCalls++
+	if pendingBatch == b {
+		pendingBatch = nil
+	}
+}
+
+// hasColorUniform tells if the color of the primitives is used by a program, e.g. FragmentShaderTexture ignores it
+func hasColorUniform(program *ShaderProgram) bool {
+	_, found := program.ActiveUniform("color")
+	return found
+}
+
+// NumVertices returns the number of vertices waiting to be flushed
+func (b *SpriteBatch) NumVertices() int {
+	return len(b.vertices) / batchVertexSize
+}
+
+// DrawCalls returns the number of draw calls issued since the last Begin
+func (b *SpriteBatch) DrawCalls() int {
+	return b.drawCalls
+}
+
+// Release releases the OpenGL buffers used by the batch
+func (b *SpriteBatch) Release() {
+	gl.DeleteBuffers(1, &b.vboId)
+	gl.DeleteVertexArrays(1, &b.vaoId)
+	b.vboId = 0
+	b.vaoId = 0
+}
+
+// isBatchable tells if the primitive can be added to a SpriteBatch
+func (p *Primitive2D) isBatchable() bool {
+	return p.arrayMode == gl.TRIANGLE_FAN && len(p.vertices) >= 6 && p.shaderProgram != nil
+}
+
+// batchVertexCount returns the number of vertices needed to draw the primitive as a list of triangles
+func batchVertexCount(p *Primitive2D) int {
+	return (len(p.vertices)/2 - 2) * 3
+}
+
+// appendBatchVertices transforms the vertices of the primitive with its model matrix and appends them to dst,
+// converting the triangle fan into a list of triangles
+func appendBatchVertices(dst []float32, p *Primitive2D) []float32 {
+	m := p.ModelMatrix32()
+	appendVertex := func(index int) {
+		x := p.vertices[index*2]
+		y := p.vertices[index*2+1]
+		var u, v float32
+		if len(p.uvCoords) >= index*2+2 {
+			u = p.uvCoords[index*2]
+			v = p.uvCoords[index*2+1]
+		}
+		dst = append(dst,
+			m[0]*x+m[4]*y+m[12],
+			m[1]*x+m[5]*y+m[13],
+			m[2]*x+m[6]*y+m[14],
+			u, v,
+		)
+	}
+
+	numVertices := len(p.vertices) / 2
+	for i := 1; i < numVertices-1; i++ {
+		appendVertex(0)
+		appendVertex(i)
+		appendVertex(i + 1)
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSpriteBatchVertices(t *testing.T) {
+	p := &Primitive2D{}
+	p.position = mgl64.Vec3{10, 20, -1}
+	p.size = mgl64.Vec2{2, 4}
+	p.scale = mgl64.Vec2{1, 1}
+	p.arrayMode = gl.TRIANGLE_FAN
+	p.vertices = []float32{0, 0, 0, 1, 1, 1, 1, 0}
+	p.uvCoords = []float32{0, 0, 0, 1, 1, 1, 1, 0}
+	p.rebuildMatrices()
+
+	if p.isBatchable() {
+		t.Errorf("A primitive without shader should not be batchable")
+	}
+	p.shaderProgram = &ShaderProgram{}
+	if !p.isBatchable() {
+		t.Errorf("A quad should be batchable")
+	}
+
+	if batchVertexCount(p) != 6 {
+		t.Errorf("Got %d vertices, expecting 6", batchVertexCount(p))
+	}
+
+	expected := []float32{
+		10, 20, -1, 0, 0,
+		10, 24, -1, 0, 1,
+		12, 24, -1, 1, 1,
+		10, 20, -1, 0, 0,
+		12, 24, -1, 1, 1,
+		12, 20, -1, 1, 0,
+	}
+	vertices := appendBatchVertices(nil, p)
+	if len(vertices) != len(expected) {
+		t.Fatalf("Got %d floats, expecting %d", len(vertices), len(expected))
+	}
+	for i := range expected {
+		if vertices[i] != expected[i] {
+			t.Errorf("appendBatchVertices failed\nexpected\n%v received\n%v", expected, vertices)
+			break
+		}
+	}
+
+	// Polylines are drawn one by one
+	p.arrayMode = gl.LINE_STRIP
+	if p.isBatchable() {
+		t.Errorf("A polyline should not be batchable")
+	}
+}
+
+func TestSpriteBatchColorUniform(t *testing.T) {
+	textured := &ShaderProgram{activeUniforms: map[string]UniformInfo{
+		"model": {Name: "model", Type: gl.FLOAT_MAT4, Size: 1},
+	}}
+	tinted := &ShaderProgram{activeUniforms: map[string]UniformInfo{
+		"model": {Name: "model", Type: gl.FLOAT_MAT4, Size: 1},
+		"color": {Name: "color", Type: gl.FLOAT_VEC4, Size: 1},
+	}}
+	if hasColorUniform(textured) {
+		t.Errorf("A program without color should not receive it")
+	}
+	if !hasColorUniform(tinted) {
+		t.Errorf("A program with color should receive it")
+	}
+}
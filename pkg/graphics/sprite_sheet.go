@@ -0,0 +1,246 @@
+package graphics
+
+// Sprite sheet loader. It reads the JSON exported by TexturePacker (hash and array formats)
+// https://www.codeandweb.com/texturepacker and Aseprite https://www.aseprite.org/docs/cli/#format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// SpriteSheetFrame a single image of a sprite sheet
+type SpriteSheetFrame struct {
+	Name string
+	// Rectangle in the texture, width and height are the ones before the rotation
+	X      int
+	Y      int
+	Width  int
+	Height int
+	// Rotated by 90 degrees clockwise in the texture
+	Rotated bool
+	Trimmed bool
+	// Position of the trimmed image inside the original one
+	OffsetX int
+	OffsetY int
+	// Size of the original image
+	SourceWidth  int
+	SourceHeight int
+	// Pivot relative to the original size
+	Pivot mgl64.Vec2
+	// Duration in seconds, only available in Aseprite sheets
+	Duration float64
+}
+
+// Animation directions of the sprite sheet tags
+const (
+	TagDirectionForward  = "forward"
+	TagDirectionReverse  = "reverse"
+	TagDirectionPingPong = "pingpong"
+)
+
+// SpriteSheetTag a named range of frames, as defined by Aseprite
+type SpriteSheetTag struct {
+	Name      string
+	From      int
+	To        int
+	Direction string
+}
+
+// SpriteSheet the content of a sprite sheet file
+type SpriteSheet struct {
+	// Image file name, relative to the sheet file
+	Image  string
+	Frames []SpriteSheetFrame
+	Tags   []SpriteSheetTag
+	// Regions of the frames by name, available once the texture is loaded
+	Regions map[string]*TextureRegion
+}
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonFrame struct {
+	Filename         string                  `json:"filename"`
+	Frame            jsonRect                `json:"frame"`
+	Rotated          bool                    `json:"rotated"`
+	Trimmed          bool                    `json:"trimmed"`
+	SpriteSourceSize jsonRect                `json:"spriteSourceSize"`
+	SourceSize       jsonRect                `json:"sourceSize"`
+	Pivot            *struct{ X, Y float64 } `json:"pivot"`
+	Duration         *float64                `json:"duration"`
+}
+
+type jsonSheet struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+// NewSpriteSheetFromFile parses a TexturePacker or Aseprite JSON file
+func NewSpriteSheetFromFile(filePath string) (*SpriteSheet, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := ParseSpriteSheet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return sheet, nil
+}
+
+// ParseSpriteSheet parses the JSON of a TexturePacker or Aseprite sprite sheet, in both hash and array formats
+func ParseSpriteSheet(data []byte) (*SpriteSheet, error) {
+	var js jsonSheet
+	if err := json.Unmarshal(data, &js); err != nil {
+		return nil, err
+	}
+
+	frames, err := parseJSONFrames(js.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &SpriteSheet{
+		Image:  js.Meta.Image,
+		Frames: make([]SpriteSheetFrame, 0, len(frames)),
+	}
+	for _, f := range frames {
+		frame := SpriteSheetFrame{
+			Name:         f.Filename,
+			X:            f.Frame.X,
+			Y:            f.Frame.Y,
+			Width:        f.Frame.W,
+			Height:       f.Frame.H,
+			Rotated:      f.Rotated,
+			Trimmed:      f.Trimmed,
+			OffsetX:      f.SpriteSourceSize.X,
+			OffsetY:      f.SpriteSourceSize.Y,
+			SourceWidth:  f.SourceSize.W,
+			SourceHeight: f.SourceSize.H,
+		}
+		if frame.SourceWidth == 0 || frame.SourceHeight == 0 {
+			frame.SourceWidth = frame.Width
+			frame.SourceHeight = frame.Height
+		}
+		if f.Pivot != nil {
+			frame.Pivot = mgl64.Vec2{f.Pivot.X, f.Pivot.Y}
+		}
+		if f.Duration != nil {
+			// Aseprite stores milliseconds
+			frame.Duration = *f.Duration / 1000
+		}
+		sheet.Frames = append(sheet.Frames, frame)
+	}
+
+	for _, t := range js.Meta.FrameTags {
+		if t.From < 0 || t.To >= len(sheet.Frames) || t.From > t.To {
+			return nil, fmt.Errorf("tag '%s' has an invalid range %d-%d", t.Name, t.From, t.To)
+		}
+		direction := t.Direction
+		if direction == "" {
+			direction = TagDirectionForward
+		}
+		sheet.Tags = append(sheet.Tags, SpriteSheetTag{Name: t.Name, From: t.From, To: t.To, Direction: direction})
+	}
+
+	return sheet, nil
+}
+
+// parseJSONFrames reads the frames from an array or from a hash. The order of the hash keys is preserved,
+// Aseprite tags refer to the frames by index
+func parseJSONFrames(data json.RawMessage) ([]jsonFrame, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no frames found")
+	}
+	if data[0] == '[' {
+		var frames []jsonFrame
+		err := json.Unmarshal(data, &frames)
+		return frames, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var frames []jsonFrame
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected frame key %v", token)
+		}
+		var frame jsonFrame
+		if err := decoder.Decode(&frame); err != nil {
+			return nil, fmt.Errorf("frame '%s': %v", name, err)
+		}
+		frame.Filename = name
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// CreateRegions creates a region for each frame, using the texture loaded from the sheet image
+func (s *SpriteSheet) CreateRegions(texture *Texture) {
+	s.Regions = make(map[string]*TextureRegion, len(s.Frames))
+	for _, f := range s.Frames {
+		region := &TextureRegion{texture: texture}
+		region.SetRect(f.X, f.Y, f.Width, f.Height)
+		region.SetRotated(f.Rotated)
+		region.SetTrim(f.OffsetX, f.OffsetY, f.SourceWidth, f.SourceHeight)
+		region.SetPivot(f.Pivot)
+		s.Regions[f.Name] = region
+	}
+}
+
+// Region returns the region of a frame by name
+func (s *SpriteSheet) Region(name string) *TextureRegion {
+	return s.Regions[name]
+}
+
+// Tag returns a tag by name, nil if not found
+func (s *SpriteSheet) Tag(name string) *SpriteSheetTag {
+	for i := range s.Tags {
+		if s.Tags[i].Name == name {
+			return &s.Tags[i]
+		}
+	}
+	return nil
+}
+
+// TagFrames returns the frames of a tag, in the order defined by the tag
+func (s *SpriteSheet) TagFrames(name string) []SpriteSheetFrame {
+	tag := s.Tag(name)
+	if tag == nil {
+		return nil
+	}
+	frames := make([]SpriteSheetFrame, 0, tag.To-tag.From+1)
+	for i := tag.From; i <= tag.To; i++ {
+		frames = append(frames, s.Frames[i])
+	}
+	if tag.Direction == TagDirectionReverse {
+		for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+			frames[i], frames[j] = frames[j], frames[i]
+		}
+	}
+	return frames
+}
@@ -0,0 +1,116 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const texturePackerHash = `{"frames": {
+	"walk_02.png": {"frame": {"x":40,"y":0,"w":20,"h":30}, "rotated": true, "trimmed": true,
+		"spriteSourceSize": {"x":2,"y":1,"w":20,"h":30}, "sourceSize": {"w":24,"h":32}, "pivot": {"x":0.5,"y":1}},
+	"walk_01.png": {"frame": {"x":0,"y":0,"w":40,"h":20}, "rotated": false, "trimmed": false,
+		"spriteSourceSize": {"x":0,"y":0,"w":40,"h":20}, "sourceSize": {"w":40,"h":20}}
+	},
+	"meta": {"image": "walk.png", "size": {"w":100,"h":50}}
+}`
+
+const texturePackerArray = `{"frames": [
+	{"filename": "a", "frame": {"x":0,"y":0,"w":10,"h":10}},
+	{"filename": "b", "frame": {"x":10,"y":0,"w":10,"h":10}}
+	], "meta": {"image": "ab.png"}}`
+
+const asepriteSheet = `{"frames": {
+	"hero 0.aseprite": {"frame": {"x":0,"y":0,"w":16,"h":16}, "sourceSize": {"w":16,"h":16}, "duration": 100},
+	"hero 1.aseprite": {"frame": {"x":16,"y":0,"w":16,"h":16}, "sourceSize": {"w":16,"h":16}, "duration": 150},
+	"hero 2.aseprite": {"frame": {"x":32,"y":0,"w":16,"h":16}, "sourceSize": {"w":16,"h":16}, "duration": 200}
+	},
+	"meta": {"image": "hero.png", "frameTags": [
+		{"name": "run", "from": 0, "to": 2, "direction": "reverse"},
+		{"name": "idle", "from": 1, "to": 1}
+	]}
+}`
+
+func TestParseSpriteSheetTexturePacker(t *testing.T) {
+	sheet, err := ParseSpriteSheet([]byte(texturePackerHash))
+	if err != nil {
+		t.Fatalf("Not expecting error %v", err)
+	}
+	if sheet.Image != "walk.png" || len(sheet.Frames) != 2 {
+		t.Fatalf("Got image %s and %d frames", sheet.Image, len(sheet.Frames))
+	}
+	// The order of the hash is preserved
+	if sheet.Frames[0].Name != "walk_02.png" || sheet.Frames[1].Name != "walk_01.png" {
+		t.Errorf("Wrong frames order %s, %s", sheet.Frames[0].Name, sheet.Frames[1].Name)
+	}
+
+	sheet.CreateRegions(&Texture{width: 100, height: 50})
+	r := sheet.Region("walk_02.png")
+	if !r.Rotated() || !r.Trimmed() {
+		t.Errorf("Region should be rotated and trimmed")
+	}
+	if !r.OriginalSize().ApproxEqual(mgl64.Vec2{24, 32}) || !r.Pivot().ApproxEqual(mgl64.Vec2{0.5, 1}) {
+		t.Errorf("Got original size %v and pivot %v", r.OriginalSize(), r.Pivot())
+	}
+	if r.Rect().Dx() != 30 || r.Rect().Dy() != 20 {
+		t.Errorf("A rotated region should be 30x20 in the texture, got %v", r.Rect())
+	}
+	expectedUV := []float32{0.7, 0, 0.4, 0, 0.4, 0.4, 0.7, 0.4}
+	for i, uv := range r.UVCoords() {
+		if !mgl64.FloatEqualThreshold(float64(uv), float64(expectedUV[i]), 1e-6) {
+			t.Errorf("UVCoords failed\nexpected\n%v received\n%v", expectedUV, r.UVCoords())
+			break
+		}
+	}
+	expectedVertices := []float32{2.0 / 24, 1.0 / 32, 2.0 / 24, 31.0 / 32, 22.0 / 24, 31.0 / 32, 22.0 / 24, 1.0 / 32}
+	for i, v := range r.QuadVertices() {
+		if !mgl64.FloatEqualThreshold(float64(v), float64(expectedVertices[i]), 1e-6) {
+			t.Errorf("QuadVertices failed\nexpected\n%v received\n%v", expectedVertices, r.QuadVertices())
+			break
+		}
+	}
+
+	if sheet.Region("walk_01.png").Trimmed() {
+		t.Errorf("Region should not be trimmed")
+	}
+}
+
+func TestParseSpriteSheetArray(t *testing.T) {
+	sheet, err := ParseSpriteSheet([]byte(texturePackerArray))
+	if err != nil {
+		t.Fatalf("Not expecting error %v", err)
+	}
+	if len(sheet.Frames) != 2 || sheet.Frames[1].Name != "b" || sheet.Frames[1].X != 10 {
+		t.Errorf("Wrong frames %+v", sheet.Frames)
+	}
+	// Without sourceSize, the original size is the frame size
+	if sheet.Frames[0].SourceWidth != 10 || sheet.Frames[0].SourceHeight != 10 {
+		t.Errorf("Wrong source size %+v", sheet.Frames[0])
+	}
+}
+
+func TestParseSpriteSheetAseprite(t *testing.T) {
+	sheet, err := ParseSpriteSheet([]byte(asepriteSheet))
+	if err != nil {
+		t.Fatalf("Not expecting error %v", err)
+	}
+	if len(sheet.Tags) != 2 {
+		t.Fatalf("Got %d tags, expecting 2", len(sheet.Tags))
+	}
+	if sheet.Tag("idle").Direction != TagDirectionForward || sheet.Tag("jump") != nil {
+		t.Errorf("Tag lookup failed")
+	}
+
+	frames := sheet.TagFrames("run")
+	expectedNames := []string{"hero 2.aseprite", "hero 1.aseprite", "hero 0.aseprite"}
+	expectedDurations := []float64{0.2, 0.15, 0.1}
+	for i, f := range frames {
+		if f.Name != expectedNames[i] || !mgl64.FloatEqual(f.Duration, expectedDurations[i]) {
+			t.Errorf("Frame #%d is %s %f, expecting %s %f", i, f.Name, f.Duration, expectedNames[i], expectedDurations[i])
+		}
+	}
+
+	if _, err := ParseSpriteSheet([]byte(`{"frames": [], "meta": {"frameTags": [{"name": "x", "from": 0, "to": 3}]}}`)); err == nil {
+		t.Errorf("Was expecting an error for an invalid tag range, none returned")
+	}
+}
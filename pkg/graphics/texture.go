@@ -15,64 +15,99 @@ import (
 
 // Texture a representation of an image file in memory
 type Texture struct {
-	id     uint32
-	width  int32
-	height int32
+	id      uint32
+	width   int32
+	height  int32
+	options TextureOptions
 }
 
-// NewTextureFromFile loads the image from a file into a texture
-func NewTextureFromFile(filePath string) *Texture {
+// NewTextureFromFile loads the image from a file into a texture, using DefaultTextureOptions.
+// The error is an *AssetNotFoundError or an *ImageDecodeError
+func NewTextureFromFile(filePath string) (*Texture, error) {
+	return NewTextureFromFileWithOptions(filePath, DefaultTextureOptions)
+}
+
+// NewTextureFromFileWithOptions loads the image from a file into a texture, see NewTextureFromFile
+func NewTextureFromFileWithOptions(filePath string, options TextureOptions) (*Texture, error) {
+	decodedImage, err := decodeImageFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewTextureFromImageWithOptions(decodedImage, options), nil
+}
+
+// decodeImageFile reads an image without touching OpenGL, it can be called from any goroutine
+func decodeImageFile(filePath string) (image.Image, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		fmt.Printf("Error loading texture. %s\n", err)
-		return nil
+		return nil, &AssetNotFoundError{Path: filePath, Err: err}
 	}
 	defer file.Close()
 
-	decodedImage, format, err := image.Decode(file)
+	decodedImage, _, err := image.Decode(file)
 	if err != nil {
-		fmt.Printf("Error decoding <%s> image: '%s'\n", format, filePath)
-		return nil
+		return nil, &ImageDecodeError{Path: filePath, Err: err}
 	}
-	return NewTextureFromImage(decodedImage)
+	return decodedImage, nil
 }
 
-// NewTextureFromImage uses the data from an Image struct to create a texture
-func NewTextureFromImage(imageData image.Image) *Texture {
-	switch imageData.(type) {
-	case *image.RGBA:
-	default:
-		rgba := image.NewRGBA(imageData.Bounds())
-		if rgba.Stride != rgba.Rect.Size().X*4 {
-			fmt.Println("Error creating texture: unsupported stride")
-			return nil
-		}
-		draw.Draw(rgba, rgba.Bounds(), imageData, image.Point{0, 0}, draw.Src)
-		imageData = rgba
+// MustNewTextureFromFile is like NewTextureFromFile but panics if the texture can't be loaded
+func MustNewTextureFromFile(filePath string) *Texture {
+	texture, err := NewTextureFromFile(filePath)
+	if err != nil {
+		panic(err)
 	}
+	return texture
+}
 
+// NewTextureFromImage uses the data from an Image struct to create a texture, using DefaultTextureOptions
+func NewTextureFromImage(imageData image.Image) *Texture {
+	return NewTextureFromImageWithOptions(imageData, DefaultTextureOptions)
+}
+
+// NewTextureFromImageWithOptions uses the data from an Image struct to create a texture
+func NewTextureFromImageWithOptions(imageData image.Image, options TextureOptions) *Texture {
+	options = options.normalized()
 	texture := &Texture{
-		width:  int32(imageData.Bounds().Dx()),
-		height: int32(imageData.Bounds().Dy()),
+		width:   int32(imageData.Bounds().Dx()),
+		height:  int32(imageData.Bounds().Dy()),
+		options: options,
 	}
-	pixelData := imageData.(*image.RGBA).Pix
+	pixelData := texturePixels(imageData, options.PremultipliedAlpha)
 
 	gl.GenTextures(1, &texture.id)
 	gl.ActiveTexture(gl.TEXTURE0)
 	gl.BindTexture(gl.TEXTURE_2D, texture.id)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 	gl.TexImage2D(
 		gl.TEXTURE_2D, 0, gl.RGBA, texture.width, texture.height,
 		0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixelData),
 	)
+	options.apply()
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 
 	return texture
 }
 
+// texturePixels returns the pixels of an image as tightly packed RGBA bytes, with the colors multiplied by
+// the alpha or not
+func texturePixels(imageData image.Image, premultiplied bool) []uint8 {
+	bounds := imageData.Bounds()
+	if premultiplied {
+		if rgba, ok := imageData.(*image.RGBA); ok && rgba.Stride == bounds.Dx()*4 {
+			return rgba.Pix
+		}
+		rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), imageData, bounds.Min, draw.Src)
+		return rgba.Pix
+	}
+	if nrgba, ok := imageData.(*image.NRGBA); ok && nrgba.Stride == bounds.Dx()*4 {
+		return nrgba.Pix
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), imageData, bounds.Min, draw.Src)
+	return nrgba.Pix
+}
+
 // NewEmptyTexture creates an empty texture with a specified size
 func NewEmptyTexture(width int, height int) (*Texture, error) {
 	bounds := image.Rectangle{
@@ -82,20 +117,18 @@ func NewEmptyTexture(width int, height int) (*Texture, error) {
 	imageData := image.NewRGBA(bounds)
 
 	texture := &Texture{
-		width:  int32(imageData.Bounds().Dx()),
-		height: int32(imageData.Bounds().Dy()),
+		width:   int32(imageData.Bounds().Dx()),
+		height:  int32(imageData.Bounds().Dy()),
+		options: TextureOptions{}.normalized(),
 	}
 	gl.GenTextures(1, &texture.id)
 	gl.ActiveTexture(gl.TEXTURE0)
 	gl.BindTexture(gl.TEXTURE_2D, texture.id)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
 	gl.TexImage2D(
 		gl.TEXTURE_2D, 0, gl.RGBA, texture.width, texture.height,
 		0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(imageData.Pix),
 	)
+	texture.options.apply()
 	gl.BindTexture(gl.TEXTURE_2D, 0)
 
 	return texture, nil
@@ -115,3 +148,110 @@ func (t *Texture) Width() int32 {
 func (t *Texture) Height() int32 {
 	return t.height
 }
+
+// Options returns the sampling options of the texture
+func (t *Texture) Options() TextureOptions {
+	return t.options
+}
+
+// SetOptions changes how the texture is sampled. The mipmaps are generated if needed, the alpha can't be
+// premultiplied after the creation and PremultipliedAlpha is ignored
+func (t *Texture) SetOptions(options TextureOptions) {
+	options.PremultipliedAlpha = t.options.PremultipliedAlpha
+	t.options = options.normalized()
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	t.options.apply()
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// Release deletes the texture from the GPU, it can't be used afterwards
+func (t *Texture) Release() {
+	if t.id != 0 {
+		gl.DeleteTextures(1, &t.id)
+		t.id = 0
+	}
+}
+
+// UpdateRegion replaces the pixels of the texture starting at x, y (top-left) with the ones of the image.
+// The image must fit inside the texture, the mipmaps are regenerated if the texture has them
+func (t *Texture) UpdateRegion(x int, y int, imageData image.Image) error {
+	bounds := imageData.Bounds()
+	if err := t.checkRegion(x, y, bounds.Dx(), bounds.Dy()); err != nil {
+		return err
+	}
+	if bounds.Empty() {
+		return nil
+	}
+	pixelData := texturePixels(imageData, t.options.PremultipliedAlpha)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	gl.TexSubImage2D(
+		gl.TEXTURE_2D, 0, int32(x), int32(y), int32(bounds.Dx()), int32(bounds.Dy()),
+		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixelData),
+	)
+	if t.options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return nil
+}
+
+// checkRegion tells if a region can be written in the texture
+func (t *Texture) checkRegion(x int, y int, width int, height int) error {
+	if t.id == 0 {
+		return fmt.Errorf("texture released")
+	}
+	if x < 0 || y < 0 || x+width > int(t.width) || y+height > int(t.height) {
+		return fmt.Errorf(
+			"region %dx%d at %d,%d outside of the texture %dx%d", width, height, x, y, t.width, t.height,
+		)
+	}
+	return nil
+}
+
+// Resize changes the size of the texture, keeping its id and options. The content is cleared
+func (t *Texture) Resize(width int, height int) error {
+	if t.id == 0 {
+		return fmt.Errorf("texture released")
+	}
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid texture size %dx%d", width, height)
+	}
+	t.width = int32(width)
+	t.height = int32(height)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	gl.TexImage2D(
+		gl.TEXTURE_2D, 0, gl.RGBA, t.width, t.height,
+		0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(make([]uint8, width*height*4)),
+	)
+	if t.options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return nil
+}
+
+// ToImage reads the pixels of the texture back from the GPU. The rows are in the same order they were uploaded,
+// the ones of a RenderTarget texture start from the top of what has been drawn
+func (t *Texture) ToImage() (*image.RGBA, error) {
+	if t.id == 0 {
+		return nil, fmt.Errorf("texture released")
+	}
+	pixelData := make([]uint8, t.width*t.height*4)
+	gl.BindTexture(gl.TEXTURE_2D, t.id)
+	gl.GetTexImage(gl.TEXTURE_2D, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixelData))
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return imageFromPixels(pixelData, int(t.width), int(t.height), t.options.PremultipliedAlpha), nil
+}
+
+// imageFromPixels wraps tightly packed RGBA bytes in an image, premultiplying the colors if they aren't
+func imageFromPixels(pixelData []uint8, width int, height int, premultiplied bool) *image.RGBA {
+	rect := image.Rect(0, 0, width, height)
+	if premultiplied {
+		return &image.RGBA{Pix: pixelData, Stride: width * 4, Rect: rect}
+	}
+	rgba := image.NewRGBA(rect)
+	draw.Draw(rgba, rect, &image.NRGBA{Pix: pixelData, Stride: width * 4, Rect: rect}, image.Point{}, draw.Src)
+	return rgba
+}
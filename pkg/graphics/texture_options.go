@@ -0,0 +1,120 @@
+package graphics
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// TextureFilter how the texels are sampled when the texture is scaled
+type TextureFilter int32
+
+// Filters of the textures. The mipmap ones can only be used as min filter
+const (
+	FilterLinear               TextureFilter = gl.LINEAR
+	FilterNearest              TextureFilter = gl.NEAREST
+	FilterNearestMipmapNearest TextureFilter = gl.NEAREST_MIPMAP_NEAREST
+	FilterLinearMipmapNearest  TextureFilter = gl.LINEAR_MIPMAP_NEAREST
+	FilterNearestMipmapLinear  TextureFilter = gl.NEAREST_MIPMAP_LINEAR
+	FilterLinearMipmapLinear   TextureFilter = gl.LINEAR_MIPMAP_LINEAR
+)
+
+// TextureWrap what is sampled outside the [0,1] texture coordinates
+type TextureWrap int32
+
+// Wrap modes of the textures
+const (
+	WrapClamp          TextureWrap = gl.CLAMP_TO_EDGE
+	WrapRepeat         TextureWrap = gl.REPEAT
+	WrapMirroredRepeat TextureWrap = gl.MIRRORED_REPEAT
+)
+
+// From EXT_texture_filter_anisotropic, supported by almost every driver but not part of the 4.1 core profile
+const (
+	textureMaxAnisotropy    = 0x84FE
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
+// TextureOptions how a texture is sampled and stored. The zero value is linear filtering and clamping to the edges
+type TextureOptions struct {
+	// Zero means FilterLinear
+	MinFilter TextureFilter
+	MagFilter TextureFilter
+	// Zero means WrapClamp
+	WrapS TextureWrap
+	WrapT TextureWrap
+	// Generates the mipmaps, the min filter becomes a mipmap one if it's not already
+	Mipmaps bool
+	// Max anisotropy of the filtering, values up to 1 disable it. It's limited to what the driver supports
+	Anisotropy float32
+	// Stores the colors multiplied by the alpha, the textures are then blended with (ONE, ONE_MINUS_SRC_ALPHA)
+	PremultipliedAlpha bool
+}
+
+// DefaultTextureOptions the options of the textures loaded without specifying them
+var DefaultTextureOptions = TextureOptions{}
+
+// PixelArtTextureOptions options keeping the pixels sharp when scaled
+var PixelArtTextureOptions = TextureOptions{MinFilter: FilterNearest, MagFilter: FilterNearest}
+
+// normalized returns the options with the defaults in place of the zero values
+func (o TextureOptions) normalized() TextureOptions {
+	if o.MinFilter == 0 {
+		o.MinFilter = FilterLinear
+	}
+	if o.MagFilter == 0 {
+		o.MagFilter = FilterLinear
+	}
+	// Magnification never uses the mipmaps
+	switch o.MagFilter {
+	case FilterNearestMipmapNearest, FilterNearestMipmapLinear:
+		o.MagFilter = FilterNearest
+	case FilterLinearMipmapNearest, FilterLinearMipmapLinear:
+		o.MagFilter = FilterLinear
+	}
+	if o.WrapS == 0 {
+		o.WrapS = WrapClamp
+	}
+	if o.WrapT == 0 {
+		o.WrapT = WrapClamp
+	}
+	switch o.MinFilter {
+	case FilterLinear:
+		if o.Mipmaps {
+			o.MinFilter = FilterLinearMipmapLinear
+		}
+	case FilterNearest:
+		if o.Mipmaps {
+			o.MinFilter = FilterNearestMipmapNearest
+		}
+	default:
+		o.Mipmaps = true
+	}
+	return o
+}
+
+// apply sets the options on the texture bound to TEXTURE_2D, after its pixels have been uploaded
+func (o TextureOptions) apply() {
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(o.MinFilter))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(o.MagFilter))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, int32(o.WrapS))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, int32(o.WrapT))
+	if o.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+	if o.Anisotropy > 1 {
+		var maxAnisotropy float32
+		gl.GetFloatv(maxTextureMaxAnisotropy, &maxAnisotropy)
+		// Without the extension the query fails and leaves 0
+		if maxAnisotropy > 1 {
+			gl.TexParameterf(gl.TEXTURE_2D, textureMaxAnisotropy, minFloat32(o.Anisotropy, maxAnisotropy))
+		}
+		// Clears the error of the query, if any
+		gl.GetError()
+	}
+}
+
+func minFloat32(a float32, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
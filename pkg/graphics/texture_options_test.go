@@ -0,0 +1,63 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestTextureOptionsNormalized(t *testing.T) {
+	options := []struct {
+		options  TextureOptions
+		expected TextureOptions
+	}{
+		{
+			TextureOptions{},
+			TextureOptions{MinFilter: FilterLinear, MagFilter: FilterLinear, WrapS: WrapClamp, WrapT: WrapClamp},
+		},
+		{
+			TextureOptions{MinFilter: FilterNearest, MagFilter: FilterNearest, WrapS: WrapRepeat, Mipmaps: true},
+			TextureOptions{MinFilter: FilterNearestMipmapNearest, MagFilter: FilterNearest, WrapS: WrapRepeat, WrapT: WrapClamp, Mipmaps: true},
+		},
+		{
+			TextureOptions{Mipmaps: true},
+			TextureOptions{MinFilter: FilterLinearMipmapLinear, MagFilter: FilterLinear, WrapS: WrapClamp, WrapT: WrapClamp, Mipmaps: true},
+		},
+		{
+			// A mipmap filter needs the mipmaps, the mag filter can't use them
+			TextureOptions{MinFilter: FilterLinearMipmapNearest, MagFilter: FilterNearestMipmapLinear},
+			TextureOptions{MinFilter: FilterLinearMipmapNearest, MagFilter: FilterNearest, WrapS: WrapClamp, WrapT: WrapClamp, Mipmaps: true},
+		},
+	}
+	for _, o := range options {
+		if normalized := o.options.normalized(); normalized != o.expected {
+			t.Errorf("expected\n%+v received\n%+v", o.expected, normalized)
+		}
+	}
+}
+
+func TestTexturePixels(t *testing.T) {
+	half := color.NRGBA{200, 100, 50, 128}
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(1, 1, half)
+
+	// A sub image has a stride larger than its width
+	sub := img.SubImage(image.Rect(1, 1, 3, 2))
+	straight := texturePixels(sub, false)
+	expected := []uint8{200, 100, 50, 128, 0, 0, 0, 0}
+	if !reflect.DeepEqual(straight, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, straight)
+	}
+
+	premultiplied := texturePixels(sub, true)
+	expected = []uint8{100, 50, 25, 128, 0, 0, 0, 0}
+	if !reflect.DeepEqual(premultiplied, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, premultiplied)
+	}
+
+	// Tightly packed images are used as they are
+	if pixels := texturePixels(img, false); &pixels[0] != &img.Pix[0] {
+		t.Errorf("The pixels of a packed NRGBA image should not be copied")
+	}
+}
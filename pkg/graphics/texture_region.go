@@ -0,0 +1,170 @@
+package graphics
+
+import (
+	"image"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// TextureRegion a rectangular area of a texture, used to share a texture among many primitives.
+// Regions coming from sprite sheets can be rotated by 90 degrees clockwise inside the texture and trimmed:
+// the transparent border of the original image is removed and its size is kept in OriginalSize
+type TextureRegion struct {
+	texture        *Texture
+	x              int
+	y              int
+	width          int
+	height         int
+	rotated        bool
+	offsetX        int
+	offsetY        int
+	originalWidth  int
+	originalHeight int
+	pivot          mgl64.Vec2
+	u1             float32
+	v1             float32
+	u2             float32
+	v2             float32
+}
+
+// NewTextureRegion creates a region from a rectangle, in pixels, of the texture
+func NewTextureRegion(texture *Texture, x int, y int, width int, height int) *TextureRegion {
+	r := &TextureRegion{texture: texture}
+	r.SetRect(x, y, width, height)
+	return r
+}
+
+// SetRect changes the rectangle, in pixels, covered by the region
+func (r *TextureRegion) SetRect(x int, y int, width int, height int) {
+	r.x = x
+	r.y = y
+	r.width = width
+	r.height = height
+	r.originalWidth = width
+	r.originalHeight = height
+	r.offsetX = 0
+	r.offsetY = 0
+	r.updateUV()
+}
+
+// SetRotated marks the region as stored rotated by 90 degrees clockwise in the texture.
+// Width and height are always the ones of the image before the rotation
+func (r *TextureRegion) SetRotated(rotated bool) {
+	r.rotated = rotated
+	r.updateUV()
+}
+
+// Rotated tells if the region is stored rotated by 90 degrees clockwise in the texture
+func (r *TextureRegion) Rotated() bool {
+	return r.rotated
+}
+
+// SetTrim sets the position of the region inside the original, untrimmed, image and the size of the latter
+func (r *TextureRegion) SetTrim(offsetX int, offsetY int, originalWidth int, originalHeight int) {
+	r.offsetX = offsetX
+	r.offsetY = offsetY
+	r.originalWidth = originalWidth
+	r.originalHeight = originalHeight
+}
+
+// Trimmed tells if the region is smaller than the original image
+func (r *TextureRegion) Trimmed() bool {
+	return r.offsetX != 0 || r.offsetY != 0 || r.width != r.originalWidth || r.height != r.originalHeight
+}
+
+// Offset returns the position of the region inside the original image, in pixels
+func (r *TextureRegion) Offset() (int, int) {
+	return r.offsetX, r.offsetY
+}
+
+// OriginalSize returns the size of the original image before trimming, in pixels
+func (r *TextureRegion) OriginalSize() mgl64.Vec2 {
+	return mgl64.Vec2{float64(r.originalWidth), float64(r.originalHeight)}
+}
+
+// SetPivot sets the pivot point, relative to the original size: 0,0 is the top left corner and 1,1 the bottom right one
+func (r *TextureRegion) SetPivot(pivot mgl64.Vec2) {
+	r.pivot = pivot
+}
+
+// Pivot returns the pivot point, relative to the original size
+func (r *TextureRegion) Pivot() mgl64.Vec2 {
+	return r.pivot
+}
+
+func (r *TextureRegion) updateUV() {
+	width, height := r.width, r.height
+	if r.rotated {
+		width, height = height, width
+	}
+	textureWidth := float32(r.texture.width)
+	textureHeight := float32(r.texture.height)
+	r.u1 = float32(r.x) / textureWidth
+	r.v1 = float32(r.y) / textureHeight
+	r.u2 = float32(r.x+width) / textureWidth
+	r.v2 = float32(r.y+height) / textureHeight
+}
+
+// Texture returns the texture the region belongs to
+func (r *TextureRegion) Texture() *Texture {
+	return r.texture
+}
+
+// X returns the left side of the region, in pixels
+func (r *TextureRegion) X() int {
+	return r.x
+}
+
+// Y returns the top side of the region, in pixels
+func (r *TextureRegion) Y() int {
+	return r.y
+}
+
+// Width returns the width of the region, in pixels. For a rotated region it's the width before the rotation
+func (r *TextureRegion) Width() int {
+	return r.width
+}
+
+// Height returns the height of the region, in pixels. For a rotated region it's the height before the rotation
+func (r *TextureRegion) Height() int {
+	return r.height
+}
+
+// Size returns the size of the region, in pixels
+func (r *TextureRegion) Size() mgl64.Vec2 {
+	return mgl64.Vec2{float64(r.width), float64(r.height)}
+}
+
+// Rect returns the rectangle covered by the region in the texture, in pixels
+func (r *TextureRegion) Rect() image.Rectangle {
+	if r.rotated {
+		return image.Rect(r.x, r.y, r.x+r.height, r.y+r.width)
+	}
+	return image.Rect(r.x, r.y, r.x+r.width, r.y+r.height)
+}
+
+// UV returns the top left and bottom right texture coordinates of the region
+func (r *TextureRegion) UV() (u1, v1, u2, v2 float32) {
+	return r.u1, r.v1, r.u2, r.v2
+}
+
+// UVCoords returns the texture coordinates of the 4 corners of the region, in the same order as the quad vertices
+func (r *TextureRegion) UVCoords() []float32 {
+	if r.rotated {
+		// The top left corner of the image is the top right one in the texture
+		return []float32{r.u2, r.v1, r.u1, r.v1, r.u1, r.v2, r.u2, r.v2}
+	}
+	return []float32{r.u1, r.v1, r.u1, r.v2, r.u2, r.v2, r.u2, r.v1}
+}
+
+// QuadVertices returns the vertices of a unit quad covering the region inside the original image.
+// For a region which is not trimmed, it's the whole unit quad
+func (r *TextureRegion) QuadVertices() []float32 {
+	originalWidth := float32(r.originalWidth)
+	originalHeight := float32(r.originalHeight)
+	left := float32(r.offsetX) / originalWidth
+	top := float32(r.offsetY) / originalHeight
+	right := float32(r.offsetX+r.width) / originalWidth
+	bottom := float32(r.offsetY+r.height) / originalHeight
+	return []float32{left, top, left, bottom, right, bottom, right, top}
+}
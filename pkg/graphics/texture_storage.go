@@ -2,30 +2,382 @@ package graphics
 
 import (
 	"fmt"
+	"image"
+	"path/filepath"
+	"runtime"
+
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
-// TextureStorage handles multiple textures, each one with its own id
+// DefaultTextureGroup group of the textures loaded synchronously
+const DefaultTextureGroup = ""
+
+// Limits the images decoded at the same time, shared by all the storages
+var textureDecoders = make(chan struct{}, runtime.NumCPU())
+
+// TextureStorage handles multiple textures, each one with its own id.
+// Each load takes a reference to the texture for its group, dropped by UnloadGroup, while Acquire and Release let
+// other users keep it alive. The texture is deleted when no reference is left
 type TextureStorage struct {
-	path     string
-	textures map[string]*Texture
+	path    string
+	entries map[string]*textureEntry
+	regions map[string]*TextureRegion
+	options TextureOptions
+	onLoad  func(textureId string, err error)
+	// Replaced by the tests
+	decode  func(filePath string) (image.Image, error)
+	upload  func(imageData image.Image, options TextureOptions) *Texture
+	release func(texture *Texture)
+}
+
+type textureEntry struct {
+	// References taken by the loads of each group, and by Acquire
+	loads    map[string]int
+	acquired int
+	loading  bool
+	texture  *Texture
+	sheet    *SpriteSheet
+	err      error
+}
+
+func newTextureEntry(group string, loading bool) *textureEntry {
+	return &textureEntry{loads: map[string]int{group: 1}, loading: loading}
+}
+
+// TextureLoadProgress counts the loads of a group, to be shown by loading screens
+type TextureLoadProgress struct {
+	Total  int
+	Loaded int
+	Failed int
+}
+
+// Done tells if all the loads are finished, successfully or not
+func (p TextureLoadProgress) Done() bool {
+	return p.Loaded+p.Failed == p.Total
+}
+
+// Fraction returns the part of the loads finished, from 0 to 1. It's 1 when there's nothing to load
+func (p TextureLoadProgress) Fraction() float64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return float64(p.Loaded+p.Failed) / float64(p.Total)
 }
 
 // MakeTextureStorage creates a new initialized instance
 func MakeTextureStorage(path string) *TextureStorage {
 	return &TextureStorage{
-		path:     path,
-		textures: make(map[string]*Texture),
+		path:    path,
+		entries: make(map[string]*textureEntry),
+		regions: make(map[string]*TextureRegion),
+		options: DefaultTextureOptions,
+		decode:  decodeImageFile,
+		upload:  NewTextureFromImageWithOptions,
+		release: (*Texture).Release,
 	}
 }
 
-// LoadTexture loads a texture from file and assigns an id to it
-func (ts *TextureStorage) LoadTexture(fileName string, textureId string) {
-	fullPath := fmt.Sprintf("%s/%s", ts.path, fileName)
-	t := NewTextureFromFile(fullPath)
-	ts.textures[textureId] = t
+// SetDefaultOptions sets the options used by the loads not specifying them
+func (ts *TextureStorage) SetDefaultOptions(options TextureOptions) {
+	ts.options = options
+}
+
+// DefaultOptions returns the options used by the loads not specifying them
+func (ts *TextureStorage) DefaultOptions() TextureOptions {
+	return ts.options
+}
+
+// SetOnLoad sets a function called when an asynchronous load finishes, err is nil if the texture is ready.
+// Without it the errors are printed
+func (ts *TextureStorage) SetOnLoad(onLoad func(textureId string, err error)) {
+	ts.onLoad = onLoad
+}
+
+// LoadTexture loads a texture from file and assigns an id to it, in DefaultTextureGroup
+func (ts *TextureStorage) LoadTexture(fileName string, textureId string) error {
+	return ts.LoadTextureWithOptions(fileName, textureId, ts.options)
+}
+
+// LoadTextureWithOptions loads a texture from file with the given sampling options and assigns an id to it.
+// If the id is already loaded it only takes another reference. If it's still loading asynchronously the texture is
+// loaded right away instead, the pending load is dropped and its references are kept
+func (ts *TextureStorage) LoadTextureWithOptions(fileName string, textureId string, options TextureOptions) error {
+	if ts.retainLoaded(textureId, DefaultTextureGroup) {
+		return nil
+	}
+	imageData, err := ts.decode(ts.fullPath(fileName))
+	if err != nil {
+		return err
+	}
+	entry := ts.replaceEntry(textureId, DefaultTextureGroup)
+	entry.texture = ts.upload(imageData, options)
+	return nil
 }
 
-// TextureForId returns the texture associated with the id
+// LoadTextureAsync decodes the image on a background goroutine, the texture is created on the main thread by
+// RunMainThreadCalls. Until then TextureForId returns nil, use Progress or SetOnLoad to know when it's ready
+func (ts *TextureStorage) LoadTextureAsync(fileName string, textureId string, group string) {
+	ts.LoadTextureAsyncWithOptions(fileName, textureId, group, ts.options)
+}
+
+// LoadTextureAsyncWithOptions is like LoadTextureAsync but with the given sampling options
+func (ts *TextureStorage) LoadTextureAsyncWithOptions(fileName string, textureId string, group string, options TextureOptions) {
+	if ts.retain(textureId, group) {
+		return
+	}
+	entry := newTextureEntry(group, true)
+	ts.entries[textureId] = entry
+
+	fullPath := ts.fullPath(fileName)
+	decode := ts.decode
+	go func() {
+		textureDecoders <- struct{}{}
+		imageData, err := decode(fullPath)
+		<-textureDecoders
+		CallOnMainThread(func() {
+			ts.finishLoad(textureId, entry, imageData, nil, options, err)
+		})
+	}()
+}
+
+// TextureForId returns the texture associated with the id, nil if it's not loaded
 func (ts *TextureStorage) TextureForId(textureId string) *Texture {
-	return ts.textures[textureId]
+	entry, found := ts.entries[textureId]
+	if !found {
+		return nil
+	}
+	return entry.texture
+}
+
+// LoadSpriteSheet loads a TexturePacker or Aseprite sheet and its image, in DefaultTextureGroup. The texture is
+// stored with textureId, the sheet too, while each frame is stored as a region using its name as id
+func (ts *TextureStorage) LoadSpriteSheet(fileName string, textureId string) (*SpriteSheet, error) {
+	return ts.LoadSpriteSheetWithOptions(fileName, textureId, ts.options)
+}
+
+// LoadSpriteSheetWithOptions loads a sheet like LoadSpriteSheet, creating its texture with the given options.
+// A sheet still loading asynchronously is loaded right away, like LoadTextureWithOptions does
+func (ts *TextureStorage) LoadSpriteSheetWithOptions(fileName string, textureId string, options TextureOptions) (*SpriteSheet, error) {
+	if ts.retainLoaded(textureId, DefaultTextureGroup) {
+		return ts.entries[textureId].sheet, nil
+	}
+	sheet, imageData, err := ts.decodeSpriteSheet(ts.fullPath(fileName), ts.decode)
+	if err != nil {
+		return nil, err
+	}
+	entry := ts.replaceEntry(textureId, DefaultTextureGroup)
+	entry.sheet = sheet
+	ts.createTexture(entry, imageData, options)
+	return sheet, nil
+}
+
+// LoadSpriteSheetAsync reads the sheet and decodes its image on a background goroutine, like LoadTextureAsync
+func (ts *TextureStorage) LoadSpriteSheetAsync(fileName string, textureId string, group string) {
+	ts.LoadSpriteSheetAsyncWithOptions(fileName, textureId, group, ts.options)
+}
+
+// LoadSpriteSheetAsyncWithOptions is like LoadSpriteSheetAsync but with the given sampling options
+func (ts *TextureStorage) LoadSpriteSheetAsyncWithOptions(fileName string, textureId string, group string, options TextureOptions) {
+	if ts.retain(textureId, group) {
+		return
+	}
+	entry := newTextureEntry(group, true)
+	ts.entries[textureId] = entry
+
+	fullPath := ts.fullPath(fileName)
+	decode := ts.decode
+	go func() {
+		textureDecoders <- struct{}{}
+		sheet, imageData, err := ts.decodeSpriteSheet(fullPath, decode)
+		<-textureDecoders
+		CallOnMainThread(func() {
+			ts.finishLoad(textureId, entry, imageData, sheet, options, err)
+		})
+	}()
+}
+
+// SpriteSheetForId returns the sprite sheet associated with the id
+func (ts *TextureStorage) SpriteSheetForId(textureId string) *SpriteSheet {
+	entry, found := ts.entries[textureId]
+	if !found || entry.texture == nil {
+		return nil
+	}
+	return entry.sheet
+}
+
+// RegionForId returns the region of a sprite sheet frame by name
+func (ts *TextureStorage) RegionForId(regionId string) *TextureRegion {
+	return ts.regions[regionId]
+}
+
+// Error returns the error of a failed load, nil if the id is loaded, loading or unknown
+func (ts *TextureStorage) Error(textureId string) error {
+	entry, found := ts.entries[textureId]
+	if !found {
+		return nil
+	}
+	return entry.err
+}
+
+// Progress counts the textures loaded by a group, the ones already unloaded excluded. A texture loaded by several
+// groups is counted by each of them
+func (ts *TextureStorage) Progress(group string) TextureLoadProgress {
+	var progress TextureLoadProgress
+	for _, entry := range ts.entries {
+		if entry.loads[group] == 0 {
+			continue
+		}
+		progress.Total++
+		if entry.err != nil {
+			progress.Failed++
+		} else if !entry.loading {
+			progress.Loaded++
+		}
+	}
+	return progress
+}
+
+// Acquire takes a reference to a texture, so that it stays loaded until Release even if its group is unloaded.
+// It returns nil if the texture is still loading
+func (ts *TextureStorage) Acquire(textureId string) *Texture {
+	entry, found := ts.entries[textureId]
+	if !found {
+		log.Errorf("acquiring texture %q not in the storage", textureId)
+		return nil
+	}
+	entry.acquired++
+	return entry.texture
+}
+
+// Release drops a reference taken by Acquire, the texture is deleted when no reference is left
+func (ts *TextureStorage) Release(textureId string) {
+	entry, found := ts.entries[textureId]
+	if !found {
+		log.Errorf("releasing texture %q not in the storage", textureId)
+		return
+	}
+	if entry.acquired == 0 {
+		log.Errorf("releasing texture %q more times than acquired", textureId)
+		return
+	}
+	entry.acquired--
+	ts.removeUnused(textureId, entry)
+}
+
+// UnloadGroup drops the references taken by the loads of a group. The textures still acquired, or loaded by other
+// groups, stay loaded. The loads not finished yet and not needed anymore are cancelled
+func (ts *TextureStorage) UnloadGroup(group string) {
+	for textureId, entry := range ts.entries {
+		if entry.loads[group] == 0 {
+			continue
+		}
+		delete(entry.loads, group)
+		ts.removeUnused(textureId, entry)
+	}
+}
+
+func (ts *TextureStorage) fullPath(fileName string) string {
+	return fmt.Sprintf("%s/%s", ts.path, fileName)
+}
+
+// retain takes another load reference, for the group, to an entry already loaded or loading. The failed ones are
+// replaced
+func (ts *TextureStorage) retain(textureId string, group string) bool {
+	entry, found := ts.entries[textureId]
+	if !found || entry.err != nil {
+		return false
+	}
+	entry.loads[group]++
+	return true
+}
+
+// retainLoaded is like retain, but only for the entries already loaded
+func (ts *TextureStorage) retainLoaded(textureId string, group string) bool {
+	entry, found := ts.entries[textureId]
+	if found && entry.loading {
+		return false
+	}
+	return ts.retain(textureId, group)
+}
+
+// replaceEntry stores a new entry for a synchronous load, taking over the references of the one it replaces.
+// A load still pending for the old entry is discarded when it finishes
+func (ts *TextureStorage) replaceEntry(textureId string, group string) *textureEntry {
+	entry := newTextureEntry(group, false)
+	if old, found := ts.entries[textureId]; found {
+		for oldGroup, loads := range old.loads {
+			entry.loads[oldGroup] += loads
+		}
+		entry.acquired = old.acquired
+	}
+	ts.entries[textureId] = entry
+	return entry
+}
+
+// decodeSpriteSheet reads a sheet and its image, the image path being relative to the sheet
+func (ts *TextureStorage) decodeSpriteSheet(
+	fullPath string, decode func(filePath string) (image.Image, error),
+) (*SpriteSheet, image.Image, error) {
+	sheet, err := NewSpriteSheetFromFile(fullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	imageData, err := decode(filepath.Join(filepath.Dir(fullPath), sheet.Image))
+	if err != nil {
+		return nil, nil, err
+	}
+	return sheet, imageData, nil
+}
+
+// createTexture uploads the image of an entry, creating the regions of its sheet if any
+func (ts *TextureStorage) createTexture(entry *textureEntry, imageData image.Image, options TextureOptions) {
+	entry.texture = ts.upload(imageData, options)
+	if entry.sheet == nil {
+		return
+	}
+	entry.sheet.CreateRegions(entry.texture)
+	for name, region := range entry.sheet.Regions {
+		ts.regions[name] = region
+	}
+}
+
+// finishLoad completes an asynchronous load on the main thread, unless it has been unloaded meanwhile
+func (ts *TextureStorage) finishLoad(
+	textureId string, entry *textureEntry, imageData image.Image, sheet *SpriteSheet, options TextureOptions, err error,
+) {
+	if ts.entries[textureId] != entry {
+		return
+	}
+	entry.loading = false
+	if err != nil {
+		entry.err = err
+	} else {
+		entry.sheet = sheet
+		ts.createTexture(entry, imageData, options)
+	}
+
+	if ts.onLoad != nil {
+		ts.onLoad(textureId, err)
+	} else if err != nil {
+		log.Errorf("%v", err)
+	}
+}
+
+// removeUnused deletes an entry, and its texture, once no reference is left
+func (ts *TextureStorage) removeUnused(textureId string, entry *textureEntry) {
+	if entry.acquired > 0 || len(entry.loads) > 0 {
+		return
+	}
+	delete(ts.entries, textureId)
+	if entry.texture == nil {
+		return
+	}
+	if entry.sheet != nil {
+		for name, region := range entry.sheet.Regions {
+			if ts.regions[name] == region {
+				delete(ts.regions, name)
+			}
+		}
+	}
+	ts.release(entry.texture)
 }
@@ -0,0 +1,255 @@
+package graphics
+
+import (
+	"errors"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestTextureStorage creates a storage decoding and uploading fake images, the released textures are counted
+func newTestTextureStorage(released *int) *TextureStorage {
+	ts := MakeTextureStorage("assets")
+	ts.decode = func(filePath string) (image.Image, error) {
+		if filepath.Base(filePath) == "missing.png" {
+			return nil, &AssetNotFoundError{Path: filePath, Err: os.ErrNotExist}
+		}
+		return image.NewNRGBA(image.Rect(0, 0, 8, 4)), nil
+	}
+	ts.upload = func(imageData image.Image, options TextureOptions) *Texture {
+		return &Texture{id: 1, width: int32(imageData.Bounds().Dx()), height: int32(imageData.Bounds().Dy())}
+	}
+	ts.release = func(texture *Texture) {
+		*released++
+	}
+	return ts
+}
+
+// waitForGroup runs the main thread calls until the loads of the group are done
+func waitForGroup(t *testing.T, ts *TextureStorage, group string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !ts.Progress(group).Done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for the group %q", group)
+		}
+		RunMainThreadCalls()
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTextureStorageReferences(t *testing.T) {
+	released := 0
+	ts := newTestTextureStorage(&released)
+
+	if err := ts.LoadTexture("hero.png", "hero"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.LoadTexture("missing.png", "missing"); err == nil {
+		t.Errorf("expected an error loading a missing file")
+	}
+	if ts.TextureForId("missing") != nil {
+		t.Errorf("A failed load should not store a texture")
+	}
+
+	texture := ts.Acquire("hero")
+	if texture == nil || texture != ts.TextureForId("hero") {
+		t.Errorf("expected\n%v received\n%v", ts.TextureForId("hero"), texture)
+	}
+
+	// Acquired, it survives the unload of its group
+	ts.UnloadGroup(DefaultTextureGroup)
+	if ts.TextureForId("hero") != texture || released != 0 {
+		t.Errorf("An acquired texture should not be released by UnloadGroup")
+	}
+	ts.Release("hero")
+	if ts.TextureForId("hero") != nil || released != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, released)
+	}
+
+	// Loading twice takes two references
+	ts.LoadTexture("hero.png", "hero")
+	ts.LoadTexture("hero.png", "hero")
+	if progress := ts.Progress(DefaultTextureGroup); progress.Total != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, progress.Total)
+	}
+	ts.UnloadGroup(DefaultTextureGroup)
+	if ts.TextureForId("hero") != nil || released != 2 {
+		t.Errorf("expected\n%v received\n%v", 2, released)
+	}
+}
+
+func TestTextureStorageAsync(t *testing.T) {
+	released := 0
+	ts := newTestTextureStorage(&released)
+	var loaded []string
+	var failed []error
+	ts.SetOnLoad(func(textureId string, err error) {
+		if err != nil {
+			failed = append(failed, err)
+			return
+		}
+		loaded = append(loaded, textureId)
+	})
+
+	ts.LoadTextureAsync("a.png", "a", "level")
+	ts.LoadTextureAsync("b.png", "b", "level")
+	ts.LoadTextureAsync("missing.png", "c", "level")
+	ts.LoadTextureAsync("ui.png", "ui", "ui")
+	if progress := ts.Progress("level"); progress.Total != 3 {
+		t.Errorf("expected\n%v received\n%v", 3, progress.Total)
+	}
+
+	waitForGroup(t, ts, "level")
+	waitForGroup(t, ts, "ui")
+	expected := TextureLoadProgress{Total: 3, Loaded: 2, Failed: 1}
+	if progress := ts.Progress("level"); progress != expected || progress.Fraction() != 1 {
+		t.Errorf("expected\n%v received\n%v", expected, progress)
+	}
+	if len(loaded) != 3 || len(failed) != 1 {
+		t.Errorf("expected\n%v received\n%v %v", "3 loaded 1 failed", loaded, failed)
+	}
+	var notFound *AssetNotFoundError
+	if !errors.As(ts.Error("c"), &notFound) {
+		t.Errorf("expected\n%v received\n%v", "*AssetNotFoundError", ts.Error("c"))
+	}
+	if ts.TextureForId("a") == nil || ts.TextureForId("a").Width() != 8 {
+		t.Errorf("expected the texture a to be loaded")
+	}
+
+	ts.UnloadGroup("level")
+	if released != 2 || ts.TextureForId("a") != nil || ts.Progress("level").Total != 0 {
+		t.Errorf("expected\n%v received\n%v", 2, released)
+	}
+	if ts.TextureForId("ui") == nil {
+		t.Errorf("Unloading a group should not touch the others")
+	}
+}
+
+func TestTextureStorageSharedByGroups(t *testing.T) {
+	released := 0
+	ts := newTestTextureStorage(&released)
+
+	ts.LoadTextureAsync("tiles.png", "tiles", "level1")
+	ts.LoadTextureAsync("hero.png", "hero", "level1")
+	// The next level needs the tiles too, they're already loading
+	ts.LoadTextureAsync("tiles.png", "tiles", "level2")
+	expected := TextureLoadProgress{Total: 1}
+	if progress := ts.Progress("level2"); progress != expected {
+		t.Errorf("expected\n%v received\n%v", expected, progress)
+	}
+	waitForGroup(t, ts, "level1")
+	waitForGroup(t, ts, "level2")
+
+	ts.UnloadGroup("level1")
+	if ts.TextureForId("tiles") == nil || ts.TextureForId("hero") != nil || released != 1 {
+		t.Errorf("expected\n%v received\n%v", "only hero released", released)
+	}
+	expected = TextureLoadProgress{Total: 1, Loaded: 1}
+	if progress := ts.Progress("level2"); progress != expected {
+		t.Errorf("expected\n%v received\n%v", expected, progress)
+	}
+	if progress := ts.Progress("level1"); progress.Total != 0 {
+		t.Errorf("expected\n%v received\n%v", 0, progress.Total)
+	}
+
+	ts.UnloadGroup("level2")
+	if ts.TextureForId("tiles") != nil || released != 2 {
+		t.Errorf("expected\n%v received\n%v", 2, released)
+	}
+}
+
+func TestTextureStorageLoadWhileLoading(t *testing.T) {
+	released := 0
+	ts := newTestTextureStorage(&released)
+	uploads := 0
+	upload := ts.upload
+	ts.upload = func(imageData image.Image, options TextureOptions) *Texture {
+		uploads++
+		return upload(imageData, options)
+	}
+
+	ts.LoadTextureAsync("a.png", "a", "level")
+	// Needed right away, it can't wait for the pending load
+	if err := ts.LoadTexture("a.png", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ts.TextureForId("a") == nil {
+		t.Errorf("A synchronous load should return with the texture loaded")
+	}
+	expected := TextureLoadProgress{Total: 1, Loaded: 1}
+	if progress := ts.Progress("level"); progress != expected {
+		t.Errorf("expected\n%v received\n%v", expected, progress)
+	}
+	// The pending load finishes without replacing the texture
+	time.Sleep(10 * time.Millisecond)
+	RunMainThreadCalls()
+	if uploads != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, uploads)
+	}
+
+	ts.UnloadGroup("level")
+	if ts.TextureForId("a") == nil {
+		t.Errorf("The texture should stay loaded for the default group")
+	}
+	ts.UnloadGroup(DefaultTextureGroup)
+	if ts.TextureForId("a") != nil || released != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, released)
+	}
+}
+
+func TestTextureStorageAsyncCancelled(t *testing.T) {
+	released := 0
+	ts := newTestTextureStorage(&released)
+	uploads := 0
+	upload := ts.upload
+	ts.upload = func(imageData image.Image, options TextureOptions) *Texture {
+		uploads++
+		return upload(imageData, options)
+	}
+
+	ts.LoadTextureAsync("a.png", "a", "level")
+	ts.UnloadGroup("level")
+	// Loaded again, the first result must not replace the second load
+	ts.LoadTextureAsync("a.png", "a", "level")
+	waitForGroup(t, ts, "level")
+	// Lets the cancelled load finish too
+	time.Sleep(10 * time.Millisecond)
+	RunMainThreadCalls()
+
+	if uploads != 1 || ts.TextureForId("a") == nil {
+		t.Errorf("expected\n%v received\n%v", 1, uploads)
+	}
+}
+
+func TestTextureStorageSpriteSheetAsync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "texture_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sheet := `{"frames": {"walk_0": {"frame": {"x": 0, "y": 0, "w": 4, "h": 4}}}, "meta": {"image": "hero.png"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "hero.json"), []byte(sheet), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	released := 0
+	ts := newTestTextureStorage(&released)
+	ts.path = dir
+	ts.LoadSpriteSheetAsync("hero.json", "hero", "level")
+	if ts.SpriteSheetForId("hero") != nil {
+		t.Errorf("A sheet still loading should not be returned")
+	}
+	waitForGroup(t, ts, "level")
+
+	region := ts.RegionForId("walk_0")
+	if ts.SpriteSheetForId("hero") == nil || region == nil || region.texture != ts.TextureForId("hero") {
+		t.Fatalf("expected the sheet and its regions to be loaded, %v", ts.Error("hero"))
+	}
+	ts.UnloadGroup("level")
+	if ts.RegionForId("walk_0") != nil || released != 1 {
+		t.Errorf("expected\n%v received\n%v", 1, released)
+	}
+}
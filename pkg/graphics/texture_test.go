@@ -0,0 +1,71 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTextureCheckRegion(t *testing.T) {
+	texture := &Texture{id: 1, width: 64, height: 32}
+	regions := []struct {
+		x, y, width, height int
+		valid               bool
+	}{
+		{0, 0, 64, 32, true},
+		{10, 20, 54, 12, true},
+		{0, 0, 0, 0, true},
+		{-1, 0, 10, 10, false},
+		{0, -1, 10, 10, false},
+		{60, 0, 5, 10, false},
+		{0, 30, 10, 3, false},
+	}
+	for _, r := range regions {
+		err := texture.checkRegion(r.x, r.y, r.width, r.height)
+		if (err == nil) != r.valid {
+			t.Errorf("expected\n%v received\n%v (%v)", r.valid, err == nil, err)
+		}
+	}
+
+	// Released textures have no id
+	texture = &Texture{width: 64, height: 32}
+	if err := texture.UpdateRegion(0, 0, image.NewRGBA(image.Rect(0, 0, 1, 1))); err == nil {
+		t.Errorf("A released texture should not be updated")
+	}
+	if err := texture.Resize(10, 10); err == nil {
+		t.Errorf("A released texture should not be resized")
+	}
+	if _, err := texture.ToImage(); err == nil {
+		t.Errorf("A released texture should not be read")
+	}
+}
+
+func TestTextureResizeInvalid(t *testing.T) {
+	texture := &Texture{id: 1, width: 64, height: 32}
+	if err := texture.Resize(0, 10); err == nil {
+		t.Errorf("A texture should not be resized to zero")
+	}
+	if texture.Width() != 64 || texture.Height() != 32 {
+		t.Errorf("expected\n%v received\n%v", "64x32", []int32{texture.Width(), texture.Height()})
+	}
+}
+
+func TestImageFromPixels(t *testing.T) {
+	pixels := []uint8{200, 100, 50, 128, 10, 20, 30, 255}
+
+	straight := imageFromPixels(append([]uint8{}, pixels...), 2, 1, false)
+	expected := color.RGBA{100, 50, 25, 128}
+	if c := straight.RGBAAt(0, 0); c != expected {
+		t.Errorf("expected\n%v received\n%v", expected, c)
+	}
+	expected = color.RGBA{10, 20, 30, 255}
+	if c := straight.RGBAAt(1, 0); c != expected {
+		t.Errorf("expected\n%v received\n%v", expected, c)
+	}
+
+	premultiplied := imageFromPixels(pixels, 2, 1, true)
+	expected = color.RGBA{200, 100, 50, 128}
+	if c := premultiplied.RGBAAt(0, 0); c != expected {
+		t.Errorf("expected\n%v received\n%v", expected, c)
+	}
+}
@@ -0,0 +1,118 @@
+package graphics
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CameraBlockName the uniform block holding the projection of the camera, see ShaderCameraBlock
+const CameraBlockName = "Camera"
+
+// CameraBlockBinding the binding point of the camera block, the programs having it are bound automatically
+const CameraBlockBinding uint32 = 0
+
+// ShaderCameraBlock declares the camera block in a shader, in place of the projection uniform, like the built-in
+// vertex shaders do. All the programs having it share the same buffer, updated only when the projection changes,
+// so that it's uploaded once per context in a frame instead of once per draw call
+const ShaderCameraBlock = `
+        layout(std140) uniform Camera {
+            mat4 projection;
+        };
+        `
+
+// Shared by all the programs with the camera block
+var (
+	cameraBuffer     *UniformBuffer
+	cameraProjection mgl32.Mat4
+)
+
+// UniformBuffer a buffer providing the data of the uniform blocks bound to its binding point, see
+// ShaderProgram.BindUniformBlock. The data follows the std140 layout declared in the shaders
+type UniformBuffer struct {
+	id      uint32
+	size    int
+	binding uint32
+}
+
+// NewUniformBuffer creates a buffer of the specified size, in bytes, and attaches it to a binding point
+func NewUniformBuffer(size int, binding uint32) *UniformBuffer {
+	b := &UniformBuffer{size: size, binding: binding}
+	gl.GenBuffers(1, &b.id)
+	gl.BindBuffer(gl.UNIFORM_BUFFER, b.id)
+	gl.BufferData(gl.UNIFORM_BUFFER, size, nil, gl.DYNAMIC_DRAW)
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+	gl.BindBufferBase(gl.UNIFORM_BUFFER, binding, b.id)
+	return b
+}
+
+// ID returns the OpenGL ID of the buffer
+func (b *UniformBuffer) ID() uint32 {
+	return b.id
+}
+
+// Size returns the size of the buffer in bytes
+func (b *UniformBuffer) Size() int {
+	return b.size
+}
+
+// Binding returns the binding point the buffer is attached to
+func (b *UniformBuffer) Binding() uint32 {
+	return b.binding
+}
+
+// SetData writes floats into the buffer, offset is in bytes
+func (b *UniformBuffer) SetData(offset int, data []float32) error {
+	if len(data) == 0 {
+		return nil
+	}
+	size := len(data) * Float32Size
+	if offset < 0 || offset+size > b.size {
+		return fmt.Errorf("uniform buffer: writing %d bytes at %d, the size is %d", size, offset, b.size)
+	}
+	gl.BindBuffer(gl.UNIFORM_BUFFER, b.id)
+	gl.BufferSubData(gl.UNIFORM_BUFFER, offset, size, gl.Ptr(data))
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+	return nil
+}
+
+// SetMat4 writes a matrix into the buffer, offset is in bytes
+func (b *UniformBuffer) SetMat4(offset int, m *mgl32.Mat4) error {
+	return b.SetData(offset, m[:])
+}
+
+// Release deletes the buffer
+func (b *UniformBuffer) Release() {
+	gl.DeleteBuffers(1, &b.id)
+	b.id = 0
+}
+
+// Flips the projection while a RenderTarget is pushed
+var renderTargetFlip = mgl32.Scale3D(1, -1, 1)
+
+// ApplyProjection sets the projection of the camera on a program, the one bound to the context.
+// The programs with the camera block get it from the shared buffer, the others from the projection uniform
+func (c *Context) ApplyProjection(shader *ShaderProgram) {
+	projection := c.projection()
+	if _, found := shader.UniformBlock(CameraBlockName); !found {
+		shader.LogUniformError(shader.SetUniform("projection", &projection))
+		return
+	}
+	if cameraBuffer == nil {
+		cameraBuffer = NewUniformBuffer(16*Float32Size, CameraBlockBinding)
+	} else if cameraProjection == projection {
+		return
+	}
+	cameraBuffer.SetMat4(0, &projection)
+	cameraProjection = projection
+}
+
+// projection returns the projection of the camera, flipped while a RenderTarget is pushed
+func (c *Context) projection() mgl32.Mat4 {
+	projection := c.Camera2D.ProjectionMatrix32()
+	if target := c.RenderTarget(); target != nil && !target.bottomUp {
+		projection = renderTargetFlip.Mul4(projection)
+	}
+	return projection
+}
@@ -1,6 +1,8 @@
 package input
 
 import (
+	"fmt"
+
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
@@ -49,7 +51,7 @@ var (
 // GameController represents a physical input device
 type GameController interface {
 	Connected() bool
-	Open(deviceIndex int) bool
+	Open(deviceIndex int) error
 	Close()
 	Update()
 	NumButtons() int
@@ -63,6 +65,26 @@ type GameController interface {
 	Description() string
 }
 
+// ControllerInUseError a controller can't be opened because its device is used by another controller
+type ControllerInUseError struct {
+	// Index of the joystick, -1 for the keyboard
+	DeviceIndex int
+}
+
+func (e *ControllerInUseError) Error() string {
+	if e.DeviceIndex < 0 {
+		return "the keyboard is already in use"
+	}
+	return fmt.Sprintf("another joystick is associated to index #%d", e.DeviceIndex)
+}
+
+// MustOpen opens a controller and panics if it fails
+func MustOpen(controller GameController, deviceIndex int) {
+	if err := controller.Open(deviceIndex); err != nil {
+		panic(err)
+	}
+}
+
 // GameControllerMapping a axes/buttons mapping for a specific device
 type GameControllerMapping struct {
 	nameRegEx string
@@ -5,6 +5,7 @@ import (
 	"regexp"
 
 	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
 type JoystickController struct {
@@ -33,13 +34,15 @@ func init() {
 	glfw.SetJoystickCallback(func(joy, event int) {
 		if glfw.MonitorEvent(event) == glfw.Connected {
 			// The joystick was connected
-			fmt.Printf("Joystick #%d: plugged in", joy)
+			log.Infof("Joystick #%d: plugged in", joy)
 			if JoystickControllers[joy] != nil {
-				JoystickControllers[joy].Open(joy)
+				if err := JoystickControllers[joy].Open(joy); err != nil {
+					log.Errorf("Joystick #%d: %v", joy, err)
+				}
 			}
 		} else if glfw.MonitorEvent(event) == glfw.Disconnected {
 			// The joystick was disconnected
-			fmt.Printf("Joystick #%d: plugged out", joy)
+			log.Infof("Joystick #%d: plugged out", joy)
 			if JoystickControllers[joy] != nil && JoystickControllers[joy].Connected() {
 				JoystickControllers[joy].pluggedOut()
 			}
@@ -47,15 +50,16 @@ func init() {
 	})
 }
 
-func (c *JoystickController) Open(deviceIndex int) bool {
+// Open associates the controller to a joystick. The joystick doesn't need to be plugged in, the controller
+// connects when it is. The error is a *ControllerInUseError
+func (c *JoystickController) Open(deviceIndex int) error {
 	if c.connected {
-		fmt.Printf("Joystick already open on device #%d", c.joystick)
-		return true
+		log.Warnf("Joystick already open on device #%d", c.joystick)
+		return nil
 	}
 
 	if JoystickControllers[deviceIndex] != nil && JoystickControllers[deviceIndex] != c {
-		fmt.Printf("Another joystick is associated to index #%d", deviceIndex)
-		return false
+		return &ControllerInUseError{DeviceIndex: deviceIndex}
 	}
 
 	JoystickControllers[deviceIndex] = c
@@ -63,7 +67,7 @@ func (c *JoystickController) Open(deviceIndex int) bool {
 
 	// The joystick is currently not connected but it might be plugged in later
 	if !glfw.JoystickPresent(glfw.Joystick(deviceIndex)) {
-		return true
+		return nil
 	}
 
 	c.connected = true
@@ -77,14 +81,14 @@ func (c *JoystickController) Open(deviceIndex int) bool {
 	c.buttonsPressed = make([]bool, c.numButtons)
 	c.buttonsReleased = make([]bool, c.numButtons)
 
-	fmt.Printf("Joystick #%d: opened. %s", c.joystick, c.Description())
+	log.Infof("Joystick #%d: opened. %s", c.joystick, c.Description())
 	c.findMapping()
 
-	return true
+	return nil
 }
 
 func (c *JoystickController) Close() {
-	fmt.Printf("Joystick #%d: closed", c.joystick)
+	log.Infof("Joystick #%d: closed", c.joystick)
 	JoystickControllers[int(c.joystick)] = nil
 	c.pluggedOut()
 }
@@ -180,7 +184,7 @@ func (c *JoystickController) findMapping() {
 		r, _ := regexp.Compile(mapping.nameRegEx)
 		if r.MatchString(c.name) == true && len(mapping.buttons) == c.numButtons && len(mapping.axes) == c.numAxes {
 			c.SetMapping(mapping)
-			fmt.Printf("Joystick #%d: mapping found", c.joystick)
+			log.Infof("Joystick #%d: mapping found", c.joystick)
 			return
 		}
 	}
@@ -1,10 +1,9 @@
 package input
 
 import (
-	"fmt"
-
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/maxfish/gojira2d/pkg/app"
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
 var (
@@ -13,7 +12,7 @@ var (
 
 func RegisterKeyCallback(callback glfw.KeyCallback) {
 	if keyCallbackFunc != nil {
-		fmt.Println("Error: A keyboard key-callback is already registered!")
+		log.Errorf("A keyboard key-callback is already registered!")
 	}
 	keyCallbackFunc = callback
 	app.GetWindow().SetKeyCallback(callback)
@@ -21,11 +21,10 @@ type KeyboardController struct {
 	keyMapping      map[glfw.Key]int
 }
 
-// Open initializes the keyboard. The parameter is ignored
-func (c *KeyboardController) Open(_ int) bool {
+// Open initializes the keyboard. The parameter is ignored, the error is a *ControllerInUseError
+func (c *KeyboardController) Open(_ int) error {
 	if !IsKeyboardFree() {
-		fmt.Print("The keyboard is already in use")
-		return false
+		return &ControllerInUseError{DeviceIndex: -1}
 	}
 
 	c.connected = true
@@ -50,7 +49,7 @@ func (c *KeyboardController) Open(_ int) bool {
 		}
 	})
 
-	return true
+	return nil
 }
 
 // Close disables the keyboard callback and resets all the data
@@ -1,10 +1,9 @@
 package input
 
 import (
-	"fmt"
-
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/maxfish/gojira2d/pkg/app"
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
 var (
@@ -24,7 +23,7 @@ var (
 
 func registerMouseCallbacks(pCallback glfw.CursorPosCallback, bCallback glfw.MouseButtonCallback, sCallback glfw.ScrollCallback) {
 	if positionCallback != nil {
-		fmt.Printf("Error: Mouse callbacks have been already registered!")
+		log.Errorf("Mouse callbacks have been already registered!")
 	}
 	positionCallback = pCallback
 	buttonCallback = bCallback
@@ -0,0 +1,122 @@
+// Package log routes the diagnostic messages of the library to a pluggable Logger.
+// By default the messages are printed on the standard output
+package log
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// Level the importance of a message
+type Level int
+
+// Levels of the messages, in increasing order of importance
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+	// Disables all the messages when used as the minimum level
+	LevelNone
+)
+
+var levelPrefixes = map[Level]string{
+	LevelDebug:   "Debug: ",
+	LevelInfo:    "",
+	LevelWarning: "Warning: ",
+	LevelError:   "Error: ",
+}
+
+// Logger receives the diagnostic messages of the library. The messages don't end with a newline
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+var logger Logger = NewWriterLogger(os.Stdout, LevelInfo)
+
+// SetLogger replaces the logger used by the library, nil discards all the messages
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NewWriterLogger(ioutil.Discard, LevelNone)
+	}
+	logger = l
+}
+
+// GetLogger returns the logger used by the library
+func GetLogger() Logger {
+	return logger
+}
+
+// Debugf logs a message useful only when looking for a problem
+func Debugf(format string, args ...interface{}) {
+	logger.Debugf(format, args...)
+}
+
+// Infof logs a message about the normal operation of the library
+func Infof(format string, args ...interface{}) {
+	logger.Infof(format, args...)
+}
+
+// Warnf logs a message about something unexpected that has been handled
+func Warnf(format string, args ...interface{}) {
+	logger.Warnf(format, args...)
+}
+
+// Errorf logs a failure that couldn't be reported to the caller
+func Errorf(format string, args ...interface{}) {
+	logger.Errorf(format, args...)
+}
+
+// WriterLogger writes the messages to an io.Writer, one per line, prefixed by their level
+type WriterLogger struct {
+	mutex    sync.Mutex
+	writer   io.Writer
+	minLevel Level
+}
+
+// NewWriterLogger creates a logger writing the messages with at least the specified level
+func NewWriterLogger(writer io.Writer, minLevel Level) *WriterLogger {
+	return &WriterLogger{writer: writer, minLevel: minLevel}
+}
+
+// SetMinLevel changes the minimum level of the messages written
+func (l *WriterLogger) SetMinLevel(minLevel Level) {
+	l.mutex.Lock()
+	l.minLevel = minLevel
+	l.mutex.Unlock()
+}
+
+// Debugf see Logger
+func (l *WriterLogger) Debugf(format string, args ...interface{}) {
+	l.write(LevelDebug, format, args)
+}
+
+// Infof see Logger
+func (l *WriterLogger) Infof(format string, args ...interface{}) {
+	l.write(LevelInfo, format, args)
+}
+
+// Warnf see Logger
+func (l *WriterLogger) Warnf(format string, args ...interface{}) {
+	l.write(LevelWarning, format, args)
+}
+
+// Errorf see Logger
+func (l *WriterLogger) Errorf(format string, args ...interface{}) {
+	l.write(LevelError, format, args)
+}
+
+func (l *WriterLogger) write(level Level, format string, args []interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if level < l.minLevel {
+		return
+	}
+	fmt.Fprintf(l.writer, levelPrefixes[level]+format+"\n", args...)
+}
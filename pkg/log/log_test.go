@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriterLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	l := NewWriterLogger(&buffer, LevelWarning)
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warning %d", 3)
+	l.Errorf("error %s", "4")
+	expected := "Warning: warning 3\nError: error 4\n"
+	if buffer.String() != expected {
+		t.Errorf("expected\n%v received\n%v", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	l.SetMinLevel(LevelDebug)
+	l.Debugf("debug")
+	l.Infof("info")
+	expected = "Debug: debug\ninfo\n"
+	if buffer.String() != expected {
+		t.Errorf("expected\n%v received\n%v", expected, buffer.String())
+	}
+}
+
+type recorder struct {
+	messages []string
+}
+
+func (r *recorder) Debugf(format string, args ...interface{}) {
+	r.messages = append(r.messages, "D "+format)
+}
+func (r *recorder) Infof(format string, args ...interface{}) {
+	r.messages = append(r.messages, "I "+format)
+}
+func (r *recorder) Warnf(format string, args ...interface{}) {
+	r.messages = append(r.messages, "W "+format)
+}
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.messages = append(r.messages, "E "+format)
+}
+
+func TestSetLogger(t *testing.T) {
+	previous := GetLogger()
+	defer SetLogger(previous)
+
+	r := &recorder{}
+	SetLogger(r)
+	Debugf("a")
+	Infof("b")
+	Warnf("c")
+	Errorf("d")
+	expected := []string{"D a", "I b", "W c", "E d"}
+	if len(r.messages) != len(expected) {
+		t.Fatalf("expected\n%v received\n%v", expected, r.messages)
+	}
+	for i := range expected {
+		if r.messages[i] != expected[i] {
+			t.Errorf("expected\n%v received\n%v", expected, r.messages)
+		}
+	}
+
+	SetLogger(nil)
+	Errorf("discarded")
+	if GetLogger() == nil {
+		t.Errorf("A nil logger should be replaced by one discarding the messages")
+	}
+}
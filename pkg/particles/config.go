@@ -0,0 +1,105 @@
+package particles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DefaultMaxParticles number of particles alive at the same time when the config doesn't set it
+const DefaultMaxParticles = 500
+
+// Burst a number of particles emitted all at once
+type Burst struct {
+	// Time since the start of the emission cycle, in seconds
+	Time  float64 `json:"time"`
+	Count int     `json:"count"`
+}
+
+// EmitterConfig the definition of an emitter. Angles are in degrees, distances in pixels and times in seconds.
+// With the default camera the Y axis points down, so a Direction of 90 points down as well as a positive gravity
+type EmitterConfig struct {
+	Name         string `json:"name"`
+	MaxParticles int    `json:"maxParticles"`
+	// Length of an emission cycle, 0 means that the emitter never stops
+	Duration float64 `json:"duration"`
+	// Restarts the emission cycle when Duration is over
+	Loop bool `json:"loop"`
+	// Particles per second
+	Rate   float64 `json:"rate"`
+	Bursts []Burst `json:"bursts"`
+	// Size of the rectangle, centered on the emitter, where the particles are spawned
+	SpawnArea mgl64.Vec2 `json:"spawnArea"`
+
+	Lifetime Range `json:"lifetime"`
+	Speed    Range `json:"speed"`
+	// Direction of the initial velocity and width of the cone around it
+	Direction float64    `json:"direction"`
+	Spread    float64    `json:"spread"`
+	Gravity   mgl64.Vec2 `json:"gravity"`
+	// Fraction of the velocity lost every second
+	Damping float64 `json:"damping"`
+
+	// Initial size, multiplied by the size curve
+	Size      Range `json:"size"`
+	SizeCurve Curve `json:"sizeCurve"`
+	// Initial rotation and angular velocity, the rotation curve is added on top of them
+	Rotation        Range      `json:"rotation"`
+	AngularVelocity Range      `json:"angularVelocity"`
+	RotationCurve   Curve      `json:"rotationCurve"`
+	ColorCurve      ColorCurve `json:"colorCurve"`
+}
+
+// NewEmitterConfigFromFile loads an emitter definition from a JSON file
+func NewEmitterConfigFromFile(filePath string) (*EmitterConfig, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := ParseEmitterConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return config, nil
+}
+
+// ParseEmitterConfig parses the JSON definition of an emitter. The fields not set take their default value
+func ParseEmitterConfig(data []byte) (*EmitterConfig, error) {
+	config := &EmitterConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the config, fills the missing values with their defaults and sorts the keys of the curves
+func (c *EmitterConfig) Validate() error {
+	if c.MaxParticles <= 0 {
+		c.MaxParticles = DefaultMaxParticles
+	}
+	if c.Rate < 0 || c.Duration < 0 || c.Damping < 0 {
+		return fmt.Errorf("emitter '%s': rate, duration and damping can't be negative", c.Name)
+	}
+	if c.Lifetime.Max <= 0 {
+		return fmt.Errorf("emitter '%s': the lifetime must be positive", c.Name)
+	}
+	if c.Size.Max == 0 {
+		c.Size = Range{1, 1}
+	}
+	for _, b := range c.Bursts {
+		if b.Count < 0 || b.Time < 0 {
+			return fmt.Errorf("emitter '%s': invalid burst %+v", c.Name, b)
+		}
+	}
+	sort.SliceStable(c.Bursts, func(i, j int) bool { return c.Bursts[i].Time < c.Bursts[j].Time })
+	sort.SliceStable(c.SizeCurve, func(i, j int) bool { return c.SizeCurve[i].Time < c.SizeCurve[j].Time })
+	sort.SliceStable(c.RotationCurve, func(i, j int) bool { return c.RotationCurve[i].Time < c.RotationCurve[j].Time })
+	sort.SliceStable(c.ColorCurve, func(i, j int) bool { return c.ColorCurve[i].Time < c.ColorCurve[j].Time })
+	return nil
+}
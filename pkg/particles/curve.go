@@ -0,0 +1,92 @@
+package particles
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Range a value picked randomly between Min and Max. In JSON it can also be written as a single number
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Sample returns a random value of the range
+func (r Range) Sample(rng *rand.Rand) float64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rng.Float64()*(r.Max-r.Min)
+}
+
+// UnmarshalJSON accepts both {"min": 1, "max": 2} and 1
+func (r *Range) UnmarshalJSON(data []byte) error {
+	var value float64
+	if err := json.Unmarshal(data, &value); err == nil {
+		r.Min = value
+		r.Max = value
+		return nil
+	}
+	type plainRange Range
+	return json.Unmarshal(data, (*plainRange)(r))
+}
+
+// CurveKey a value of a curve at a given time, the time goes from 0 (birth) to 1 (death)
+type CurveKey struct {
+	Time  float64 `json:"t"`
+	Value float64 `json:"v"`
+}
+
+// Curve a value changing over the lifetime of a particle, linearly interpolated between its keys.
+// The keys must be sorted by time
+type Curve []CurveKey
+
+// Value returns the value at time t. An empty curve returns the default value
+func (c Curve) Value(t float64, defaultValue float64) float64 {
+	if len(c) == 0 {
+		return defaultValue
+	}
+	if t <= c[0].Time {
+		return c[0].Value
+	}
+	for i := 1; i < len(c); i++ {
+		if t < c[i].Time {
+			a, b := c[i-1], c[i]
+			return a.Value + (b.Value-a.Value)*(t-a.Time)/(b.Time-a.Time)
+		}
+	}
+	return c[len(c)-1].Value
+}
+
+// ColorKey a color of a curve at a given time, the time goes from 0 (birth) to 1 (death)
+type ColorKey struct {
+	Time  float64        `json:"t"`
+	Color graphics.Color `json:"c"`
+}
+
+// ColorCurve a color changing over the lifetime of a particle, alpha included. The keys must be sorted by time
+type ColorCurve []ColorKey
+
+// Value returns the color at time t. An empty curve returns white
+func (c ColorCurve) Value(t float64) graphics.Color {
+	if len(c) == 0 {
+		return graphics.Color{1, 1, 1, 1}
+	}
+	if t <= c[0].Time {
+		return c[0].Color
+	}
+	for i := 1; i < len(c); i++ {
+		if t < c[i].Time {
+			a, b := c[i-1], c[i]
+			f := float32((t - a.Time) / (b.Time - a.Time))
+			var color graphics.Color
+			for j := range color {
+				color[j] = a.Color[j] + (b.Color[j]-a.Color[j])*f
+			}
+			return color
+		}
+	}
+	return c[len(c)-1].Color
+}
@@ -0,0 +1,63 @@
+package particles
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+func TestCurveValue(t *testing.T) {
+	curve := Curve{{0, 1}, {0.5, 3}, {1, 0}}
+	tests := []struct {
+		t        float64
+		expected float64
+	}{
+		{-1, 1},
+		{0, 1},
+		{0.25, 2},
+		{0.5, 3},
+		{0.75, 1.5},
+		{1, 0},
+		{2, 0},
+	}
+	for _, test := range tests {
+		if v := curve.Value(test.t, 7); v != test.expected {
+			t.Errorf("Value(%v) expected\n%v received\n%v", test.t, test.expected, v)
+		}
+	}
+	if v := (Curve{}).Value(0.5, 7); v != 7 {
+		t.Errorf("Empty curve expected\n%v received\n%v", 7, v)
+	}
+}
+
+func TestColorCurveValue(t *testing.T) {
+	curve := ColorCurve{{0, graphics.Color{1, 0, 0, 1}}, {1, graphics.Color{0, 0, 1, 0}}}
+	expected := graphics.Color{0.5, 0, 0.5, 0.5}
+	if c := curve.Value(0.5); c != expected {
+		t.Errorf("expected\n%v received\n%v", expected, c)
+	}
+	expected = graphics.Color{1, 1, 1, 1}
+	if c := (ColorCurve{}).Value(0.5); c != expected {
+		t.Errorf("Empty curve expected\n%v received\n%v", expected, c)
+	}
+}
+
+func TestRangeUnmarshal(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected Range
+	}{
+		{`2.5`, Range{2.5, 2.5}},
+		{`{"min": 1, "max": 3}`, Range{1, 3}},
+	}
+	for _, test := range tests {
+		var r Range
+		if err := json.Unmarshal([]byte(test.json), &r); err != nil {
+			t.Fatal(err)
+		}
+		if r != test.expected {
+			t.Errorf("%s expected\n%v received\n%v", test.json, test.expected, r)
+		}
+	}
+}
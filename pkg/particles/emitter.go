@@ -0,0 +1,202 @@
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Particle the state of a single particle
+type Particle struct {
+	Position mgl64.Vec2
+	Velocity mgl64.Vec2
+	// Seconds since the birth of the particle
+	Age      float64
+	Lifetime float64
+	// Current size, rotation (in radians) and color, evaluated from the curves at every update
+	Size     float64
+	Rotation float64
+	Color    graphics.Color
+
+	startSize       float64
+	startRotation   float64
+	angularVelocity float64
+}
+
+// Emitter spawns and simulates particles following an EmitterConfig. It doesn't draw anything, see Renderer
+type Emitter struct {
+	config    *EmitterConfig
+	position  mgl64.Vec2
+	particles []Particle
+	rng       *rand.Rand
+	emitting  bool
+	// Time since the start of the current emission cycle
+	cycleTime float64
+	// Fraction of particle not yet emitted at the current rate
+	pending   float64
+	nextBurst int
+}
+
+// NewEmitter creates an emitter, the emission starts right away
+func NewEmitter(config *EmitterConfig, position mgl64.Vec2) *Emitter {
+	e := &Emitter{
+		config:    config,
+		position:  position,
+		particles: make([]Particle, 0, config.MaxParticles),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	e.Start()
+	return e
+}
+
+// SetSeed sets the seed of the random generator, to get a reproducible simulation
+func (e *Emitter) SetSeed(seed int64) {
+	e.rng.Seed(seed)
+}
+
+// Config returns the definition of the emitter
+func (e *Emitter) Config() *EmitterConfig {
+	return e.config
+}
+
+// SetPosition moves the emitter, the particles already emitted are not affected
+func (e *Emitter) SetPosition(position mgl64.Vec2) {
+	e.position = position
+}
+
+// Position returns the position of the emitter
+func (e *Emitter) Position() mgl64.Vec2 {
+	return e.position
+}
+
+// Start starts a new emission cycle
+func (e *Emitter) Start() {
+	e.emitting = true
+	e.cycleTime = 0
+	e.pending = 0
+	e.nextBurst = 0
+}
+
+// Stop stops the emission, the particles alive keep being simulated until they die
+func (e *Emitter) Stop() {
+	e.emitting = false
+}
+
+// Emitting tells if the emitter is spawning particles
+func (e *Emitter) Emitting() bool {
+	return e.emitting
+}
+
+// Finished tells if the emission is over and all the particles are dead
+func (e *Emitter) Finished() bool {
+	return !e.emitting && len(e.particles) == 0
+}
+
+// Clear kills all the particles
+func (e *Emitter) Clear() {
+	e.particles = e.particles[:0]
+}
+
+// Particles returns the particles alive, the slice is reused by the next Update
+func (e *Emitter) Particles() []Particle {
+	return e.particles
+}
+
+// NumParticles returns the number of particles alive
+func (e *Emitter) NumParticles() int {
+	return len(e.particles)
+}
+
+// Burst emits count particles immediately, even if the emitter is stopped
+func (e *Emitter) Burst(count int) {
+	for i := 0; i < count; i++ {
+		e.emit()
+	}
+}
+
+// Update advances the simulation by dt seconds: the particles age, move and new ones are emitted
+func (e *Emitter) Update(dt float64) {
+	c := e.config
+	damping := math.Max(0, 1-c.Damping*dt)
+	for i := 0; i < len(e.particles); {
+		p := &e.particles[i]
+		p.Age += dt
+		if p.Age >= p.Lifetime {
+			// The order of the particles doesn't matter, the last one takes the place of the dead one
+			last := len(e.particles) - 1
+			e.particles[i] = e.particles[last]
+			e.particles = e.particles[:last]
+			continue
+		}
+		p.Velocity = p.Velocity.Add(c.Gravity.Mul(dt)).Mul(damping)
+		p.Position = p.Position.Add(p.Velocity.Mul(dt))
+		e.evaluateCurves(p)
+		i++
+	}
+
+	remaining := dt
+	for e.emitting && remaining > 0 {
+		step := remaining
+		if c.Duration > 0 && e.cycleTime+step > c.Duration {
+			step = c.Duration - e.cycleTime
+		}
+		e.cycleTime += step
+		remaining -= step
+
+		for e.nextBurst < len(c.Bursts) && c.Bursts[e.nextBurst].Time <= e.cycleTime {
+			e.Burst(c.Bursts[e.nextBurst].Count)
+			e.nextBurst++
+		}
+		e.pending += c.Rate * step
+		for ; e.pending >= 1; e.pending-- {
+			e.emit()
+		}
+
+		if c.Duration > 0 && e.cycleTime >= c.Duration {
+			if c.Loop {
+				e.cycleTime = 0
+				e.nextBurst = 0
+			} else {
+				e.emitting = false
+			}
+		}
+	}
+}
+
+// emit spawns a particle, unless the maximum number of particles has been reached
+func (e *Emitter) emit() {
+	c := e.config
+	if len(e.particles) >= c.MaxParticles {
+		return
+	}
+	direction := mgl64.DegToRad(c.Direction + (e.rng.Float64()-0.5)*c.Spread)
+	speed := c.Speed.Sample(e.rng)
+	offset := mgl64.Vec2{
+		(e.rng.Float64() - 0.5) * c.SpawnArea.X(),
+		(e.rng.Float64() - 0.5) * c.SpawnArea.Y(),
+	}
+	e.particles = append(e.particles, Particle{
+		Position:        e.position.Add(offset),
+		Velocity:        mgl64.Vec2{math.Cos(direction) * speed, math.Sin(direction) * speed},
+		Lifetime:        c.Lifetime.Sample(e.rng),
+		startSize:       c.Size.Sample(e.rng),
+		startRotation:   mgl64.DegToRad(c.Rotation.Sample(e.rng)),
+		angularVelocity: mgl64.DegToRad(c.AngularVelocity.Sample(e.rng)),
+	})
+	e.evaluateCurves(&e.particles[len(e.particles)-1])
+}
+
+// evaluateCurves updates size, rotation and color of a particle according to its age
+func (e *Emitter) evaluateCurves(p *Particle) {
+	c := e.config
+	t := 0.0
+	if p.Lifetime > 0 {
+		t = p.Age / p.Lifetime
+	}
+	p.Size = p.startSize * c.SizeCurve.Value(t, 1)
+	p.Rotation = p.startRotation + p.angularVelocity*p.Age + mgl64.DegToRad(c.RotationCurve.Value(t, 0))
+	p.Color = c.ColorCurve.Value(t)
+}
@@ -0,0 +1,184 @@
+package particles
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func newTestConfig() *EmitterConfig {
+	config := &EmitterConfig{
+		MaxParticles: 100,
+		Lifetime:     Range{1, 1},
+		Speed:        Range{10, 10},
+	}
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func TestParseEmitterConfig(t *testing.T) {
+	data := []byte(`{
+		"name": "sparks",
+		"rate": 20,
+		"bursts": [{"time": 0.5, "count": 10}, {"time": 0, "count": 5}],
+		"lifetime": {"min": 0.5, "max": 1},
+		"speed": 100,
+		"direction": 270,
+		"spread": 30,
+		"gravity": [0, 98],
+		"sizeCurve": [{"t": 1, "v": 0}, {"t": 0, "v": 1}],
+		"colorCurve": [{"t": 0, "c": [1, 1, 0, 1]}, {"t": 1, "c": [1, 0, 0, 0]}]
+	}`)
+	config, err := ParseEmitterConfig(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxParticles != DefaultMaxParticles {
+		t.Errorf("MaxParticles expected\n%v received\n%v", DefaultMaxParticles, config.MaxParticles)
+	}
+	if config.Bursts[0].Time != 0 || config.SizeCurve[0].Time != 0 {
+		t.Errorf("Bursts and curves should be sorted by time")
+	}
+	if config.Gravity != (mgl64.Vec2{0, 98}) || config.Speed != (Range{100, 100}) || config.Size != (Range{1, 1}) {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	if _, err := ParseEmitterConfig([]byte(`{"rate": 10}`)); err == nil {
+		t.Errorf("A config without lifetime should be rejected")
+	}
+}
+
+func TestEmitterRate(t *testing.T) {
+	config := newTestConfig()
+	config.Rate = 10
+	e := NewEmitter(config, mgl64.Vec2{})
+	e.SetSeed(1)
+
+	// 10 particles per second, emitted across frames
+	for i := 0; i < 5; i++ {
+		e.Update(0.05)
+	}
+	if e.NumParticles() != 2 {
+		t.Errorf("expected\n%v received\n%v", 2, e.NumParticles())
+	}
+	// After one second the first particles start dying
+	for i := 0; i < 40; i++ {
+		e.Update(0.05)
+	}
+	if e.NumParticles() < 9 || e.NumParticles() > 11 {
+		t.Errorf("expected about 10 particles, received %v", e.NumParticles())
+	}
+}
+
+func TestEmitterBurstsAndDuration(t *testing.T) {
+	config := newTestConfig()
+	config.Duration = 1
+	config.Bursts = []Burst{{0, 5}, {0.5, 3}}
+
+	e := NewEmitter(config, mgl64.Vec2{})
+	e.Update(0.1)
+	if e.NumParticles() != 5 {
+		t.Errorf("expected\n%v received\n%v", 5, e.NumParticles())
+	}
+	e.Update(0.5)
+	if e.NumParticles() != 8 {
+		t.Errorf("expected\n%v received\n%v", 8, e.NumParticles())
+	}
+	e.Update(0.5)
+	if e.Emitting() {
+		t.Errorf("The emission should be over")
+	}
+	e.Update(1)
+	if !e.Finished() {
+		t.Errorf("All the particles should be dead, %d left", e.NumParticles())
+	}
+
+	// A looping emitter fires its bursts again at every cycle
+	config.Loop = true
+	e = NewEmitter(config, mgl64.Vec2{})
+	e.Update(0.1)
+	e.Update(0.95)
+	if e.NumParticles() != 13 {
+		t.Errorf("expected\n%v received\n%v", 13, e.NumParticles())
+	}
+}
+
+func TestEmitterMaxParticles(t *testing.T) {
+	config := newTestConfig()
+	config.MaxParticles = 10
+	e := NewEmitter(config, mgl64.Vec2{})
+	e.Burst(50)
+	if e.NumParticles() != 10 {
+		t.Errorf("expected\n%v received\n%v", 10, e.NumParticles())
+	}
+}
+
+func TestEmitterSimulation(t *testing.T) {
+	config := newTestConfig()
+	config.Lifetime = Range{2, 2}
+	config.Direction = 0
+	config.Gravity = mgl64.Vec2{0, 10}
+	config.Size = Range{4, 4}
+	config.SizeCurve = Curve{{0, 1}, {1, 0}}
+	config.AngularVelocity = Range{90, 90}
+	e := NewEmitter(config, mgl64.Vec2{100, 50})
+	e.Stop()
+	e.Burst(1)
+
+	e.Update(1)
+	p := e.Particles()[0]
+	// Semi-implicit Euler: the velocity is updated first
+	expectedPosition := mgl64.Vec2{110, 60}
+	if !p.Position.ApproxEqual(expectedPosition) {
+		t.Errorf("Position expected\n%v received\n%v", expectedPosition, p.Position)
+	}
+	if p.Size != 2 {
+		t.Errorf("Size expected\n%v received\n%v", 2, p.Size)
+	}
+	if math.Abs(p.Rotation-math.Pi/2) > 1e-9 {
+		t.Errorf("Rotation expected\n%v received\n%v", math.Pi/2, p.Rotation)
+	}
+}
+
+func TestEmitterSeed(t *testing.T) {
+	config := newTestConfig()
+	config.Speed = Range{10, 100}
+	config.Spread = 360
+	run := func() []Particle {
+		e := NewEmitter(config, mgl64.Vec2{})
+		e.SetSeed(42)
+		e.Burst(10)
+		e.Update(0.5)
+		return append([]Particle(nil), e.Particles()...)
+	}
+	a, b := run(), run()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("The same seed should give the same particles")
+		}
+	}
+}
+
+func TestAppendParticleVertices(t *testing.T) {
+	particles := []Particle{{
+		Position: mgl64.Vec2{10, 20},
+		Size:     4,
+		Rotation: math.Pi / 2,
+		Color:    [4]float32{1, 0.5, 0, 1},
+	}}
+	vertices := appendParticleVertices(nil, particles, defaultUVCoords, 0.5, -1)
+	if len(vertices) != 6*9 {
+		t.Fatalf("expected\n%v received\n%v", 6*9, len(vertices))
+	}
+	// The top left corner (-2,-1) rotated by 90 degrees
+	expected := []float32{11, 18, -1, 0, 0, 1, 0.5, 0, 1}
+	for i := range expected {
+		if math.Abs(float64(vertices[i]-expected[i])) > 1e-5 {
+			t.Errorf("expected\n%v received\n%v", expected, vertices[:9])
+			break
+		}
+	}
+}
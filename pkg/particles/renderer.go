@@ -0,0 +1,139 @@
+package particles
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Vertices of a particle quad: two triangles TL,BL,BR and TL,BR,TR
+var quadCorners = [6]int{0, 1, 2, 0, 2, 3}
+
+// Unit quad centered on the origin, corners in the same order as the TextureRegion UV coordinates
+var quadOffsets = [4][2]float64{{-0.5, -0.5}, {-0.5, 0.5}, {0.5, 0.5}, {0.5, -0.5}}
+
+var defaultUVCoords = []float32{0, 0, 0, 1, 1, 1, 1, 0}
+
+var identityMatrix = mgl32.Ident4()
+
+// Renderer draws the particles of an emitter with a single draw call. Every particle is a quad, textured with
+// a texture or a region of it and tinted by the particle color
+type Renderer struct {
+	emitter  *Emitter
+	texture  *graphics.Texture
+	uvCoords []float32
+	// Height of the quads relative to their width, to keep the aspect ratio of the region
+	aspectRatio float64
+	z           float64
+	mesh        *graphics.ColoredMesh
+	shader      *graphics.ShaderProgram
+	vertices    []float32
+}
+
+// NewRenderer creates a renderer drawing the particles with the whole texture. With a nil texture
+// the particles are drawn as solid squares
+func NewRenderer(emitter *Emitter, texture *graphics.Texture) *Renderer {
+	r := &Renderer{
+		emitter:     emitter,
+		texture:     texture,
+		uvCoords:    defaultUVCoords,
+		aspectRatio: 1,
+	}
+	if texture != nil && texture.Width() > 0 {
+		r.aspectRatio = float64(texture.Height()) / float64(texture.Width())
+	}
+	r.init()
+	return r
+}
+
+// NewRendererFromRegion creates a renderer drawing the particles with a region of a texture.
+// The trimming of the region is ignored
+func NewRendererFromRegion(emitter *Emitter, region *graphics.TextureRegion) *Renderer {
+	r := &Renderer{
+		emitter:     emitter,
+		texture:     region.Texture(),
+		uvCoords:    region.UVCoords(),
+		aspectRatio: 1,
+	}
+	if region.Width() > 0 {
+		r.aspectRatio = float64(region.Height()) / float64(region.Width())
+	}
+	r.init()
+	return r
+}
+
+func (r *Renderer) init() {
+	fragmentShader := graphics.FragmentShaderVertexColor
+	if r.texture != nil {
+		fragmentShader = graphics.FragmentShaderTextureVertexColor
+	}
+	r.shader = graphics.DefaultShaderCache.MustAcquire(graphics.VertexShaderColor, "", fragmentShader)
+	r.mesh = graphics.NewColoredMesh(r.emitter.config.MaxParticles * len(quadCorners))
+	r.vertices = make([]float32, 0, r.mesh.MaxVertices()*graphics.ColoredVertexSize)
+}
+
+// Emitter returns the emitter drawn
+func (r *Renderer) Emitter() *Emitter {
+	return r.emitter
+}
+
+// SetZ sets the Z of the particles, used for the drawing order
+func (r *Renderer) SetZ(z float64) {
+	r.z = z
+}
+
+// Z returns the Z of the particles
+func (r *Renderer) Z() float64 {
+	return r.z
+}
+
+// Texture returns the texture of the particles, nil if they are solid squares
+func (r *Renderer) Texture() *graphics.Texture {
+	return r.texture
+}
+
+// Shader returns the shader used to draw the particles
+func (r *Renderer) Shader() *graphics.ShaderProgram {
+	return r.shader
+}
+
+// Draw draws all the particles alive
+func (r *Renderer) Draw(context *graphics.Context) {
+	r.vertices = appendParticleVertices(r.vertices[:0], r.emitter.particles, r.uvCoords, r.aspectRatio, r.z)
+	r.mesh.SetVertices(r.vertices)
+	r.mesh.Draw(context, r.texture, r.shader, &identityMatrix)
+}
+
+// DrawInBatch draws the particles, they are already drawn with a single draw call
+func (r *Renderer) DrawInBatch(context *graphics.Context) {
+	r.Draw(context)
+}
+
+// Release releases the OpenGL resources used by the renderer
+func (r *Renderer) Release() {
+	r.mesh.Release()
+	r.shader.Release()
+}
+
+// appendParticleVertices appends two triangles for each particle, centered on its position, scaled and rotated
+func appendParticleVertices(dst []float32, particles []Particle, uvCoords []float32, aspectRatio float64, z float64) []float32 {
+	for i := range particles {
+		p := &particles[i]
+		sin, cos := math.Sincos(p.Rotation)
+		width := p.Size
+		height := p.Size * aspectRatio
+		for _, corner := range quadCorners {
+			x := quadOffsets[corner][0] * width
+			y := quadOffsets[corner][1] * height
+			dst = append(dst,
+				float32(p.Position.X()+x*cos-y*sin),
+				float32(p.Position.Y()+x*sin+y*cos),
+				float32(z),
+				uvCoords[corner*2], uvCoords[corner*2+1],
+				p.Color[0], p.Color[1], p.Color[2], p.Color[3],
+			)
+		}
+	}
+	return dst
+}
@@ -17,6 +17,8 @@ type Box2DDebugDraw struct {
 
 	b2World *box2d.B2World
 	PTM     float64
+	// Width of the outlines in pixels, 0 draws 1px lines
+	lineWidth float64
 	// Stores a primitive2D for each fixture
 	fixtureToPrimitive2D map[*box2d.B2Fixture]*graphics.Primitive2D
 }
@@ -37,11 +39,40 @@ func NewBox2DDebugDraw(w *box2d.B2World, PTM float64) *Box2DDebugDraw {
 	d.colorAsleep = graphics.Color{0.6, 0.6, 0.6, 1}
 	d.colorSensor = graphics.Color{0.6, 0.3, 0.6, 1}
 
+	d.buildShapes()
+
+	// TODO Draw joints
+
+	return d
+}
+
+// SetLineWidth sets the width of the outlines in pixels, 0 draws 1px lines. The shapes are built again
+func (d *Box2DDebugDraw) SetLineWidth(width float64) {
+	d.lineWidth = width
+	d.Release()
+	d.buildShapes()
+	d.Update()
+}
+
+// Release deletes the shapes built for the fixtures, the debug draw can't be drawn until SetLineWidth builds them again
+func (d *Box2DDebugDraw) Release() {
+	for _, primitive := range d.fixtureToPrimitive2D {
+		primitive.Release()
+	}
+	d.fixtureToPrimitive2D = make(map[*box2d.B2Fixture]*graphics.Primitive2D)
+}
+
+// LineWidth returns the width of the outlines in pixels
+func (d *Box2DDebugDraw) LineWidth() float64 {
+	return d.lineWidth
+}
+
+func (d *Box2DDebugDraw) buildShapes() {
 	// TODO: Debug flags??
 	drawShapes := true
 
 	if drawShapes {
-		body := w.GetBodyList()
+		body := d.b2World.GetBodyList()
 		for body != nil {
 			fixture := body.GetFixtureList()
 			for fixture != nil {
@@ -51,10 +82,19 @@ func NewBox2DDebugDraw(w *box2d.B2World, PTM float64) *Box2DDebugDraw {
 			body = body.GetNext()
 		}
 	}
+}
 
-	// TODO Draw joints
-
-	return d
+// newPolyline creates the outline of a shape, the vertices are in meters
+func (d *Box2DDebugDraw) newPolyline(body *box2d.B2Body, vertices []mgl64.Vec2, closed bool) *graphics.Primitive2D {
+	position := mgl64.Vec3{body.GetPosition().X * d.PTM, body.GetPosition().Y * d.PTM, 0}
+	var c *graphics.Primitive2D
+	if d.lineWidth > 0 {
+		c = graphics.NewThickPolylinePrimitive(position, vertices, closed, graphics.LineStyle{Width: d.lineWidth / d.PTM})
+	} else {
+		c = graphics.NewPolylinePrimitive(position, vertices, closed)
+	}
+	c.SetScale(mgl64.Vec2{d.PTM, d.PTM})
+	return c
 }
 
 func (d *Box2DDebugDraw) Update() {
@@ -106,9 +146,7 @@ func (d *Box2DDebugDraw) buildShape(body *box2d.B2Body, fixture *box2d.B2Fixture
 		for i := 0; i < len(circlePoints); i++ {
 			vertices = append(vertices, mgl64.Vec2{circlePoints[i].X(), circlePoints[i].Y()})
 		}
-		c := graphics.NewPolylinePrimitive(mgl64.Vec3{body.GetPosition().X * d.PTM, body.GetPosition().Y * d.PTM, 0}, vertices, true)
-		c.SetScale(mgl64.Vec2{d.PTM, d.PTM})
-		d.fixtureToPrimitive2D[fixture] = c
+		d.fixtureToPrimitive2D[fixture] = d.newPolyline(body, vertices, true)
 	case box2d.B2Shape_Type.E_polygon:
 		b2Shape := fixture.GetShape().(*box2d.B2PolygonShape)
 		numVertices := b2Shape.M_count
@@ -116,9 +154,7 @@ func (d *Box2DDebugDraw) buildShape(body *box2d.B2Body, fixture *box2d.B2Fixture
 		for i := 0; i < numVertices; i++ {
 			vertices = append(vertices, mgl64.Vec2{b2Shape.M_vertices[i].X, b2Shape.M_vertices[i].Y})
 		}
-		c := graphics.NewPolylinePrimitive(mgl64.Vec3{body.GetPosition().X * d.PTM, body.GetPosition().Y * d.PTM, 0}, vertices, true)
-		c.SetScale(mgl64.Vec2{d.PTM, d.PTM})
-		d.fixtureToPrimitive2D[fixture] = c
+		d.fixtureToPrimitive2D[fixture] = d.newPolyline(body, vertices, true)
 	case box2d.B2Shape_Type.E_chain:
 		b2Shape := fixture.GetShape().(*box2d.B2ChainShape)
 		numVertices := b2Shape.M_count
@@ -126,9 +162,7 @@ func (d *Box2DDebugDraw) buildShape(body *box2d.B2Body, fixture *box2d.B2Fixture
 		for i := 0; i < numVertices; i++ {
 			vertices = append(vertices, mgl64.Vec2{b2Shape.M_vertices[i].X, b2Shape.M_vertices[i].Y})
 		}
-		c := graphics.NewPolylinePrimitive(mgl64.Vec3{body.GetPosition().X * d.PTM, body.GetPosition().Y * d.PTM, 0}, vertices, false)
-		c.SetScale(mgl64.Vec2{d.PTM, d.PTM})
-		d.fixtureToPrimitive2D[fixture] = c
+		d.fixtureToPrimitive2D[fixture] = d.newPolyline(body, vertices, false)
 
 		// 			if (chain.m_hasPrevVertex)
 		// 			{
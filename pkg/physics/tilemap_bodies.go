@@ -0,0 +1,260 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ByteArena/box2d"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/tilemap"
+)
+
+// Number of vertices of the polygons approximating the ellipses
+const ellipseVertices = 8
+
+// NewBodyFromTilemapObject creates a body from an object of a Tiled map. The body is placed at the object position
+// and rotation, converted to meters with PTM, keeping the Y axis of the map pointing down.
+// Rectangles, ellipses, polygons and tile objects become solid fixtures, polylines become chains and points
+// have no fixtures at all. The object properties "density", "friction", "restitution" and "sensor" configure
+// the fixtures, the object itself is stored as user data of the body
+func NewBodyFromTilemapObject(world *box2d.B2World, object *tilemap.Object, bodyType uint8, PTM float64) (*box2d.B2Body, error) {
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = bodyType
+	bodyDef.Position = box2d.MakeB2Vec2(object.X/PTM, object.Y/PTM)
+	bodyDef.Angle = object.Rotation * math.Pi / 180
+	bodyDef.UserData = object
+
+	shapes, err := objectShapes(object, mgl64.Vec2{}, PTM)
+	if err != nil {
+		return nil, fmt.Errorf("object %d: %v", object.ID, err)
+	}
+	body := world.CreateBody(&bodyDef)
+	for _, shape := range shapes {
+		fixtureDef := fixtureDefFromProperties(object.Properties)
+		fixtureDef.Shape = shape
+		body.CreateFixtureFromDef(&fixtureDef)
+	}
+	return body, nil
+}
+
+// NewBodiesFromObjectGroup creates a body for each visible object of a group, see NewBodyFromTilemapObject
+func NewBodiesFromObjectGroup(world *box2d.B2World, group *tilemap.ObjectGroup, bodyType uint8, PTM float64) ([]*box2d.B2Body, error) {
+	bodies := make([]*box2d.B2Body, 0, len(group.Objects))
+	for _, o := range group.Objects {
+		if !o.Visible {
+			continue
+		}
+		body, err := NewBodyFromTilemapObject(world, o, bodyType, PTM)
+		if err != nil {
+			return bodies, fmt.Errorf("object group '%s': %v", group.Name, err)
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// NewBodyFromTileLayer creates a static body with the collision shapes, defined in the tilesets, of all the tiles
+// of a layer, mirrored like the flipped tiles. It returns nil if no tile has collision shapes
+func NewBodyFromTileLayer(world *box2d.B2World, m *tilemap.Map, layer *tilemap.TileLayer, PTM float64) (*box2d.B2Body, error) {
+	var shapes []box2d.B2ShapeInterface
+	var properties []tilemap.Properties
+	for y := 0; y < layer.Height; y++ {
+		for x := 0; x < layer.Width; x++ {
+			gid := layer.Tiles[y*layer.Width+x]
+			ts := m.TilesetForGID(gid)
+			if ts == nil {
+				continue
+			}
+			tile := ts.Tile(gid.ID() - ts.FirstGID)
+			if tile == nil {
+				continue
+			}
+			_, _, width, height := ts.TileRect(gid.ID() - ts.FirstGID)
+			flippedHeight := height
+			if gid.FlippedDiagonally() {
+				flippedHeight = width
+			}
+			// The shapes are relative to the top left corner of the tile, which is aligned to the bottom of the cell
+			origin := mgl64.Vec2{
+				float64((layer.X+x)*m.TileWidth+ts.OffsetX) + layer.OffsetX,
+				float64((layer.Y+y+1)*m.TileHeight-flippedHeight+ts.OffsetY) + layer.OffsetY,
+			}
+			for _, o := range tile.Objects {
+				if o.Rotation != 0 {
+					return nil, fmt.Errorf("layer '%s': rotated collision shapes are not supported", layer.Name)
+				}
+				o = flipObject(o, gid, float64(width), float64(height))
+				objectShapes, err := objectShapes(o, origin.Add(mgl64.Vec2{o.X, o.Y}), PTM)
+				if err != nil {
+					return nil, fmt.Errorf("layer '%s': %v", layer.Name, err)
+				}
+				for range objectShapes {
+					properties = append(properties, o.Properties)
+				}
+				shapes = append(shapes, objectShapes...)
+			}
+		}
+	}
+	if len(shapes) == 0 {
+		return nil, nil
+	}
+
+	bodyDef := box2d.MakeB2BodyDef()
+	bodyDef.Type = box2d.B2BodyType.B2_staticBody
+	bodyDef.UserData = layer
+	body := world.CreateBody(&bodyDef)
+	for i, shape := range shapes {
+		fixtureDef := fixtureDefFromProperties(properties[i])
+		fixtureDef.Shape = shape
+		body.CreateFixtureFromDef(&fixtureDef)
+	}
+	return body, nil
+}
+
+// flipObject returns a copy of a collision shape of a tile mirrored like the tile by the flip flags of the gid,
+// width and height are the size of the tile
+func flipObject(o *tilemap.Object, gid tilemap.GID, width, height float64) *tilemap.Object {
+	if !gid.FlippedHorizontally() && !gid.FlippedVertically() && !gid.FlippedDiagonally() {
+		return o
+	}
+	// Tiled flips diagonally first, then horizontally and vertically
+	flip := func(p mgl64.Vec2) mgl64.Vec2 {
+		w, h := width, height
+		if gid.FlippedDiagonally() {
+			p = mgl64.Vec2{p.Y(), p.X()}
+			w, h = h, w
+		}
+		if gid.FlippedHorizontally() {
+			p[0] = w - p[0]
+		}
+		if gid.FlippedVertically() {
+			p[1] = h - p[1]
+		}
+		return p
+	}
+
+	flipped := *o
+	switch o.Shape {
+	case tilemap.ShapeRectangle, tilemap.ShapeEllipse, tilemap.ShapeTile:
+		top := o.Y
+		if o.Shape == tilemap.ShapeTile {
+			top -= o.Height
+		}
+		a := flip(mgl64.Vec2{o.X, top})
+		b := flip(mgl64.Vec2{o.X + o.Width, top + o.Height})
+		flipped.X, flipped.Y = math.Min(a.X(), b.X()), math.Min(a.Y(), b.Y())
+		flipped.Width, flipped.Height = math.Abs(b.X()-a.X()), math.Abs(b.Y()-a.Y())
+		if o.Shape == tilemap.ShapeTile {
+			flipped.Y += flipped.Height
+		}
+	case tilemap.ShapePolygon, tilemap.ShapePolyline:
+		position := mgl64.Vec2{o.X, o.Y}
+		flippedPosition := flip(position)
+		flipped.X, flipped.Y = flippedPosition.X(), flippedPosition.Y()
+		flipped.Points = make([]mgl64.Vec2, len(o.Points))
+		for i, p := range o.Points {
+			flipped.Points[i] = flip(position.Add(p)).Sub(flippedPosition)
+		}
+	default:
+		position := flip(mgl64.Vec2{o.X, o.Y})
+		flipped.X, flipped.Y = position.X(), position.Y()
+	}
+	return &flipped
+}
+
+// objectShapes converts an object to Box2D shapes, offset is added to the local coordinates
+func objectShapes(o *tilemap.Object, offset mgl64.Vec2, PTM float64) ([]box2d.B2ShapeInterface, error) {
+	toMeters := func(p mgl64.Vec2) box2d.B2Vec2 {
+		return box2d.MakeB2Vec2((p.X()+offset.X())/PTM, (p.Y()+offset.Y())/PTM)
+	}
+
+	switch o.Shape {
+	case tilemap.ShapeRectangle, tilemap.ShapeTile:
+		if o.Width <= 0 || o.Height <= 0 {
+			return nil, nil
+		}
+		center := mgl64.Vec2{o.Width / 2, o.Height / 2}
+		if o.Shape == tilemap.ShapeTile {
+			// The origin of the tiles is the bottom left corner
+			center[1] = -center[1]
+		}
+		shape := box2d.MakeB2PolygonShape()
+		shape.SetAsBoxFromCenterAndAngle(o.Width/2/PTM, o.Height/2/PTM, toMeters(center), 0)
+		return []box2d.B2ShapeInterface{&shape}, nil
+
+	case tilemap.ShapeEllipse:
+		if o.Width <= 0 || o.Height <= 0 {
+			return nil, nil
+		}
+		if o.Width == o.Height {
+			shape := box2d.MakeB2CircleShape()
+			shape.M_p = toMeters(mgl64.Vec2{o.Width / 2, o.Height / 2})
+			shape.SetRadius(o.Width / 2 / PTM)
+			return []box2d.B2ShapeInterface{&shape}, nil
+		}
+		vertices := make([]box2d.B2Vec2, ellipseVertices)
+		for i := range vertices {
+			angle := 2 * math.Pi * float64(i) / ellipseVertices
+			vertices[i] = toMeters(mgl64.Vec2{
+				o.Width / 2 * (1 + math.Cos(angle)),
+				o.Height / 2 * (1 + math.Sin(angle)),
+			})
+		}
+		shape := box2d.MakeB2PolygonShape()
+		shape.Set(vertices, len(vertices))
+		return []box2d.B2ShapeInterface{&shape}, nil
+
+	case tilemap.ShapePolygon, tilemap.ShapePolyline:
+		if len(o.Points) < 2 || (o.Shape == tilemap.ShapePolygon && len(o.Points) < 3) {
+			return nil, fmt.Errorf("object %d has %d points", o.ID, len(o.Points))
+		}
+		vertices := make([]box2d.B2Vec2, len(o.Points))
+		for i, p := range o.Points {
+			vertices[i] = toMeters(p)
+		}
+		if o.Shape == tilemap.ShapePolyline {
+			shape := box2d.MakeB2ChainShape()
+			shape.CreateChain(vertices, len(vertices))
+			return []box2d.B2ShapeInterface{&shape}, nil
+		}
+		if len(vertices) <= box2d.B2_maxPolygonVertices && isConvex(o.Points) {
+			shape := box2d.MakeB2PolygonShape()
+			shape.Set(vertices, len(vertices))
+			return []box2d.B2ShapeInterface{&shape}, nil
+		}
+		// Concave or too complex for a polygon, only the outline collides
+		shape := box2d.MakeB2ChainShape()
+		shape.CreateLoop(vertices, len(vertices))
+		return []box2d.B2ShapeInterface{&shape}, nil
+	}
+	// Points and texts have no shape
+	return nil, nil
+}
+
+// isConvex tells if a polygon is convex, whatever its winding
+func isConvex(points []mgl64.Vec2) bool {
+	sign := 0.0
+	n := len(points)
+	for i := range points {
+		a, b, c := points[i], points[(i+1)%n], points[(i+2)%n]
+		cross := (b.X()-a.X())*(c.Y()-b.Y()) - (b.Y()-a.Y())*(c.X()-b.X())
+		if cross == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = cross
+		} else if (cross > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return sign != 0
+}
+
+func fixtureDefFromProperties(properties tilemap.Properties) box2d.B2FixtureDef {
+	fixtureDef := box2d.MakeB2FixtureDef()
+	fixtureDef.Density = properties.Float("density", 1)
+	fixtureDef.Friction = properties.Float("friction", fixtureDef.Friction)
+	fixtureDef.Restitution = properties.Float("restitution", fixtureDef.Restitution)
+	fixtureDef.IsSensor = properties.Bool("sensor", false)
+	return fixtureDef
+}
@@ -0,0 +1,89 @@
+package physics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ByteArena/box2d"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/tilemap"
+)
+
+func TestNewBodyFromTilemapObject(t *testing.T) {
+	tests := []struct {
+		object    tilemap.Object
+		shapeType uint8
+		fixtures  int
+	}{
+		{tilemap.Object{Shape: tilemap.ShapeRectangle, Width: 32, Height: 16}, box2d.B2Shape_Type.E_polygon, 1},
+		{tilemap.Object{Shape: tilemap.ShapeEllipse, Width: 32, Height: 32}, box2d.B2Shape_Type.E_circle, 1},
+		{tilemap.Object{Shape: tilemap.ShapeEllipse, Width: 32, Height: 16}, box2d.B2Shape_Type.E_polygon, 1},
+		{tilemap.Object{Shape: tilemap.ShapePolygon, Points: []mgl64.Vec2{{0, 0}, {32, 0}, {0, 32}}}, box2d.B2Shape_Type.E_polygon, 1},
+		{tilemap.Object{Shape: tilemap.ShapePolygon, Points: []mgl64.Vec2{{0, 0}, {32, 0}, {8, 8}, {0, 32}}}, box2d.B2Shape_Type.E_chain, 1},
+		{tilemap.Object{Shape: tilemap.ShapePolyline, Points: []mgl64.Vec2{{0, 0}, {32, 0}}}, box2d.B2Shape_Type.E_chain, 1},
+		{tilemap.Object{Shape: tilemap.ShapePoint}, 0, 0},
+	}
+
+	for i, test := range tests {
+		world := box2d.MakeB2World(box2d.MakeB2Vec2(0, 10))
+		test.object.X = 64
+		test.object.Y = 32
+		body, err := NewBodyFromTilemapObject(&world, &test.object, box2d.B2BodyType.B2_staticBody, 32)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		position := body.GetPosition()
+		if position.X != 2 || position.Y != 1 {
+			t.Errorf("test %d: expected\n%v received\n%v", i, box2d.MakeB2Vec2(2, 1), position)
+		}
+		fixtures := 0
+		for f := body.GetFixtureList(); f != nil; f = f.GetNext() {
+			if f.GetType() != test.shapeType {
+				t.Errorf("test %d: expected\n%v received\n%v", i, test.shapeType, f.GetType())
+			}
+			fixtures++
+		}
+		if fixtures != test.fixtures {
+			t.Errorf("test %d: expected\n%v received\n%v", i, test.fixtures, fixtures)
+		}
+	}
+
+	world := box2d.MakeB2World(box2d.MakeB2Vec2(0, 10))
+	invalid := tilemap.Object{Shape: tilemap.ShapePolygon, Points: []mgl64.Vec2{{0, 0}, {32, 0}}}
+	if _, err := NewBodyFromTilemapObject(&world, &invalid, box2d.B2BodyType.B2_staticBody, 32); err == nil {
+		t.Errorf("expected an error for a polygon with two points")
+	}
+}
+
+func TestFlipObject(t *testing.T) {
+	const (
+		horizontally = tilemap.GID(tilemap.FlippedHorizontally)
+		vertically   = tilemap.GID(tilemap.FlippedVertically)
+		diagonally   = tilemap.GID(tilemap.FlippedDiagonally)
+	)
+	rectangle := tilemap.Object{Shape: tilemap.ShapeRectangle, X: 2, Y: 4, Width: 8, Height: 6}
+	triangle := tilemap.Object{Shape: tilemap.ShapePolygon, X: 2, Y: 4, Points: []mgl64.Vec2{{0, 0}, {8, 0}, {0, 6}}}
+	tile := tilemap.Object{Shape: tilemap.ShapeTile, X: 2, Y: 10, Width: 8, Height: 6}
+	tests := []struct {
+		object   tilemap.Object
+		gid      tilemap.GID
+		expected tilemap.Object
+	}{
+		{rectangle, 1, rectangle},
+		{rectangle, 1 | horizontally, tilemap.Object{Shape: tilemap.ShapeRectangle, X: 22, Y: 4, Width: 8, Height: 6}},
+		{rectangle, 1 | vertically, tilemap.Object{Shape: tilemap.ShapeRectangle, X: 2, Y: 6, Width: 8, Height: 6}},
+		{rectangle, 1 | diagonally, tilemap.Object{Shape: tilemap.ShapeRectangle, X: 4, Y: 2, Width: 6, Height: 8}},
+		{rectangle, 1 | diagonally | horizontally, tilemap.Object{Shape: tilemap.ShapeRectangle, X: 6, Y: 2, Width: 6, Height: 8}},
+		{tile, 1 | vertically, tilemap.Object{Shape: tilemap.ShapeTile, X: 2, Y: 12, Width: 8, Height: 6}},
+		{triangle, 1 | horizontally, tilemap.Object{Shape: tilemap.ShapePolygon, X: 30, Y: 4, Points: []mgl64.Vec2{{0, 0}, {-8, 0}, {0, 6}}}},
+		{triangle, 1 | diagonally, tilemap.Object{Shape: tilemap.ShapePolygon, X: 4, Y: 2, Points: []mgl64.Vec2{{0, 0}, {0, 8}, {6, 0}}}},
+	}
+
+	for i, test := range tests {
+		// The tile is 32x16
+		received := flipObject(&test.object, test.gid, 32, 16)
+		if !reflect.DeepEqual(*received, test.expected) {
+			t.Errorf("test %d: expected\n%v received\n%v", i, test.expected, *received)
+		}
+	}
+}
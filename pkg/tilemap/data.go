@@ -0,0 +1,135 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Encodings and compressions of the layer data
+const (
+	EncodingCSV     = "csv"
+	EncodingBase64  = "base64"
+	CompressionZlib = "zlib"
+	CompressionGzip = "gzip"
+	CompressionNone = ""
+
+	compressionZstd  = "zstd"
+	bytesPerGlobalID = 4
+)
+
+// DecodeTileData decodes the tiles of a layer, stored as CSV or as base64 with an optional zlib or gzip compression
+func DecodeTileData(encoding string, compression string, data string) ([]GID, error) {
+	switch encoding {
+	case EncodingCSV:
+		if compression != CompressionNone {
+			return nil, fmt.Errorf("csv data can't be compressed")
+		}
+		return decodeCSV(data)
+	case EncodingBase64:
+		return decodeBase64(data, compression)
+	}
+	return nil, fmt.Errorf("unsupported encoding '%s'", encoding)
+}
+
+func decodeCSV(data string) ([]GID, error) {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+	tiles := make([]GID, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv tile '%s'", f)
+		}
+		tiles[i] = GID(v)
+	}
+	return tiles, nil
+}
+
+func decodeBase64(data string, compression string) ([]GID, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	switch compression {
+	case CompressionNone:
+	case CompressionZlib:
+		reader, err = zlib.NewReader(bytes.NewReader(raw))
+	case CompressionGzip:
+		reader, err = gzip.NewReader(bytes.NewReader(raw))
+	case compressionZstd:
+		return nil, fmt.Errorf("zstd compression is not supported")
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", compression)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reader != nil {
+		if raw, err = ioutil.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(raw)%bytesPerGlobalID != 0 {
+		return nil, fmt.Errorf("data length %d is not a multiple of %d", len(raw), bytesPerGlobalID)
+	}
+	tiles := make([]GID, len(raw)/bytesPerGlobalID)
+	for i := range tiles {
+		tiles[i] = GID(binary.LittleEndian.Uint32(raw[i*bytesPerGlobalID:]))
+	}
+	return tiles, nil
+}
+
+// tileChunk a rectangular part of an infinite layer
+type tileChunk struct {
+	x, y, width, height int
+	tiles               []GID
+}
+
+// mergeChunks builds a single grid covering all the chunks of an infinite layer
+func mergeChunks(layer *TileLayer, chunks []tileChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	minX, minY := chunks[0].x, chunks[0].y
+	maxX, maxY := minX+chunks[0].width, minY+chunks[0].height
+	for _, c := range chunks {
+		if len(c.tiles) != c.width*c.height {
+			return fmt.Errorf("chunk %d,%d has %d tiles, expecting %dx%d", c.x, c.y, len(c.tiles), c.width, c.height)
+		}
+		if c.x < minX {
+			minX = c.x
+		}
+		if c.y < minY {
+			minY = c.y
+		}
+		if c.x+c.width > maxX {
+			maxX = c.x + c.width
+		}
+		if c.y+c.height > maxY {
+			maxY = c.y + c.height
+		}
+	}
+
+	layer.X, layer.Y = minX, minY
+	layer.Width, layer.Height = maxX-minX, maxY-minY
+	layer.Tiles = make([]GID, layer.Width*layer.Height)
+	for _, c := range chunks {
+		for row := 0; row < c.height; row++ {
+			start := (c.y-minY+row)*layer.Width + c.x - minX
+			copy(layer.Tiles[start:start+c.width], c.tiles[row*c.width:(row+1)*c.width])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package tilemap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// NewMapFromFile loads a TMX or TMJ map, the format is chosen by the file extension.
+// External tilesets and images are resolved relative to the map file
+func NewMapFromFile(filePath string) (*Map, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	baseDir := filepath.Dir(filePath)
+
+	var m *Map
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".tmx", ".xml":
+		m, err = ParseTMX(data, baseDir)
+	case ".tmj", ".json":
+		m, err = ParseTMJ(data, baseDir)
+	default:
+		return nil, fmt.Errorf("%s: unknown map format", filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return m, nil
+}
+
+// loadExternalTileset loads a TSX or TSJ tileset referenced by a map
+func loadExternalTileset(filePath string) (*Tileset, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	baseDir := filepath.Dir(filePath)
+
+	var ts *Tileset
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".tsx", ".xml":
+		ts, err = ParseTSX(data, baseDir)
+	case ".tsj", ".json":
+		ts, err = ParseTSJ(data, baseDir)
+	default:
+		return nil, fmt.Errorf("%s: unknown tileset format", filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return ts, nil
+}
+
+// resolvePath makes a path found in a file relative to the working directory
+func resolvePath(baseDir string, path string) string {
+	if baseDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
@@ -0,0 +1,508 @@
+package tilemap
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// DefaultChunkSize number of tiles on each side of a chunk
+const DefaultChunkSize = 16
+
+// Renderer draws the tile layers of an orthogonal map. Each layer is split into chunks of tiles and each chunk
+// is drawn with one draw call per texture. Only the chunks inside the camera visible area are drawn
+type Renderer struct {
+	tilemap    *Map
+	position   mgl64.Vec3
+	chunkSize  int
+	shader     *graphics.ShaderProgram
+	layers     []*layerRenderer
+	animations []*tileAnimation
+	// Textures loaded by loadTextures, owned by the renderer
+	textures []*graphics.Texture
+	elapsed  float64
+	drawn    int
+}
+
+type layerRenderer struct {
+	layer  *TileLayer
+	color  graphics.Color
+	chunks []*chunk
+}
+
+// chunk a square of tiles of a layer, with one mesh per texture
+type chunk struct {
+	min    mgl64.Vec2
+	max    mgl64.Vec2
+	meshes []*chunkMesh
+}
+
+// chunkMesh the tiles of a chunk sharing the same texture, drawn as triangles
+type chunkMesh struct {
+	tileset *Tileset
+	// Set for the tiles of a collection of images, which have their own texture
+	tile      *Tile
+	gids      []GID
+	animated  []animatedQuad
+	vertices  []float32
+	uvCoords  []float32
+	primitive *graphics.Primitive2D
+}
+
+// animatedQuad a tile of a mesh whose UV coordinates follow an animation
+type animatedQuad struct {
+	quad      int
+	animation *tileAnimation
+}
+
+// tileAnimation the state of an animated tile, shared by all its instances
+type tileAnimation struct {
+	tile     *Tile
+	duration float64
+	frame    int
+	changed  bool
+}
+
+// Vertices of the two triangles of a tile, as corners of the unit square
+var quadCorners = [6][2]int{{0, 0}, {0, 1}, {1, 1}, {0, 0}, {1, 1}, {1, 0}}
+
+const floatsPerQuad = len(quadCorners) * 2
+
+// NewRenderer creates a renderer for the tile layers of a map. The textures of the tilesets are loaded from the
+// image files, unless they have been set already. Only orthogonal maps are supported
+func NewRenderer(m *Map, position mgl64.Vec3) (*Renderer, error) {
+	r, err := newRenderer(m, position, DefaultChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadTextures(); err != nil {
+		r.Release()
+		return nil, err
+	}
+	r.shader, err = graphics.DefaultShaderCache.Acquire(graphics.VertexShaderBase, "", graphics.FragmentShaderTextureColor)
+	if err != nil {
+		r.Release()
+		return nil, err
+	}
+	for _, lr := range r.layers {
+		for _, c := range lr.chunks {
+			for _, mesh := range c.meshes {
+				// The texture sizes are known now, the coordinates are computed again
+				mesh.buildUV()
+				mesh.primitive = graphics.NewTriangles(
+					mesh.vertices, mesh.uvCoords, mesh.texture(), position, mgl64.Vec2{1, 1}, r.shader)
+				mesh.primitive.SetColor(lr.color)
+			}
+		}
+	}
+	return r, nil
+}
+
+// newRenderer builds the geometry of the chunks, without touching OpenGL
+func newRenderer(m *Map, position mgl64.Vec3, chunkSize int) (*Renderer, error) {
+	if m.Orientation != "" && m.Orientation != OrientationOrthogonal {
+		return nil, fmt.Errorf("tilemap: %s maps are not supported", m.Orientation)
+	}
+	r := &Renderer{
+		tilemap:   m,
+		position:  position,
+		chunkSize: chunkSize,
+	}
+	animations := make(map[*Tile]*tileAnimation)
+	for _, l := range m.TileLayers {
+		color, err := layerColor(&l.Layer)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: layer '%s': %v", l.Name, err)
+		}
+		lr := &layerRenderer{layer: l, color: color}
+		for y := 0; y < l.Height; y += chunkSize {
+			for x := 0; x < l.Width; x += chunkSize {
+				if c := r.buildChunk(l, x, y, animations); c != nil {
+					lr.chunks = append(lr.chunks, c)
+				}
+			}
+		}
+		r.layers = append(r.layers, lr)
+	}
+	return r, nil
+}
+
+// buildChunk creates the meshes of the tiles starting at x,y in the layer, nil if the area is empty
+func (r *Renderer) buildChunk(l *TileLayer, startX int, startY int, animations map[*Tile]*tileAnimation) *chunk {
+	m := r.tilemap
+	c := &chunk{
+		min: mgl64.Vec2{math.Inf(1), math.Inf(1)},
+		max: mgl64.Vec2{math.Inf(-1), math.Inf(-1)},
+	}
+	meshes := make(map[interface{}]*chunkMesh)
+	for y := startY; y < startY+r.chunkSize && y < l.Height; y++ {
+		for x := startX; x < startX+r.chunkSize && x < l.Width; x++ {
+			gid := l.Tiles[y*l.Width+x]
+			ts := m.TilesetForGID(gid)
+			if ts == nil {
+				continue
+			}
+			tile := ts.Tiles[gid.ID()-ts.FirstGID]
+
+			var key interface{} = ts
+			if tile != nil && tile.Image != nil {
+				key = tile
+			}
+			mesh := meshes[key]
+			if mesh == nil {
+				mesh = &chunkMesh{tileset: ts}
+				if key == tile {
+					mesh.tile = tile
+				}
+				meshes[key] = mesh
+				c.meshes = append(c.meshes, mesh)
+			}
+
+			// Animations are supported only by the tilesets made of a single image
+			if tile != nil && len(tile.Animation) > 0 && mesh.tile == nil {
+				a, found := animations[tile]
+				if !found {
+					a = newTileAnimation(tile)
+					animations[tile] = a
+					r.animations = append(r.animations, a)
+				}
+				mesh.animated = append(mesh.animated, animatedQuad{len(mesh.gids), a})
+			}
+			mesh.gids = append(mesh.gids, gid)
+
+			_, _, width, height := ts.TileRect(gid.ID() - ts.FirstGID)
+			if gid.FlippedDiagonally() {
+				width, height = height, width
+			}
+			// Tiles bigger than the grid are aligned to the bottom left corner of the cell
+			left := float64((l.X+x)*m.TileWidth+ts.OffsetX) + l.OffsetX
+			top := float64((l.Y+y+1)*m.TileHeight-height+ts.OffsetY) + l.OffsetY
+			right := left + float64(width)
+			bottom := top + float64(height)
+			c.min = mgl64.Vec2{math.Min(c.min.X(), left), math.Min(c.min.Y(), top)}
+			c.max = mgl64.Vec2{math.Max(c.max.X(), right), math.Max(c.max.Y(), bottom)}
+			for _, corner := range quadCorners {
+				mesh.vertices = append(mesh.vertices,
+					float32(left+float64(corner[0]*width)), float32(top+float64(corner[1]*height)))
+			}
+		}
+	}
+	if len(c.meshes) == 0 {
+		return nil
+	}
+	for _, mesh := range c.meshes {
+		mesh.buildUV()
+	}
+	return c
+}
+
+// texture returns the texture used by the mesh
+func (m *chunkMesh) texture() *graphics.Texture {
+	if m.tile != nil {
+		return m.tile.Texture
+	}
+	return m.tileset.Texture
+}
+
+// buildUV computes the UV coordinates of all the tiles of the mesh
+func (m *chunkMesh) buildUV() {
+	if len(m.uvCoords) != len(m.gids)*floatsPerQuad {
+		m.uvCoords = make([]float32, len(m.gids)*floatsPerQuad)
+	}
+	for quad, gid := range m.gids {
+		m.setQuadUV(quad, gid.ID()-m.tileset.FirstGID, gid)
+	}
+	for _, a := range m.animated {
+		m.setQuadUV(a.quad, a.animation.tileID(), m.gids[a.quad])
+	}
+}
+
+// setQuadUV sets the UV coordinates of the 6 vertices of a tile, applying the flip flags of the gid
+func (m *chunkMesh) setQuadUV(quad int, id uint32, gid GID) {
+	x, y, width, height := m.tileset.TileRect(id)
+	textureWidth, textureHeight := m.textureSize()
+	u1 := float32(x) / float32(textureWidth)
+	v1 := float32(y) / float32(textureHeight)
+	u2 := float32(x+width) / float32(textureWidth)
+	v2 := float32(y+height) / float32(textureHeight)
+
+	uv := m.uvCoords[quad*floatsPerQuad : (quad+1)*floatsPerQuad]
+	for i, corner := range quadCorners {
+		// Tiled flips diagonally first, then horizontally and vertically.
+		// The inverse transformation applied to a corner gives the texture corner to use
+		sx, sy := corner[0], corner[1]
+		if gid.FlippedVertically() {
+			sy = 1 - sy
+		}
+		if gid.FlippedHorizontally() {
+			sx = 1 - sx
+		}
+		if gid.FlippedDiagonally() {
+			sx, sy = sy, sx
+		}
+		uv[i*2], uv[i*2+1] = u1, v1
+		if sx == 1 {
+			uv[i*2] = u2
+		}
+		if sy == 1 {
+			uv[i*2+1] = v2
+		}
+	}
+}
+
+// textureSize returns the size of the image used by the mesh, from the texture if loaded
+func (m *chunkMesh) textureSize() (int, int) {
+	texture, image := m.tileset.Texture, m.tileset.Image
+	if m.tile != nil {
+		texture, image = m.tile.Texture, m.tile.Image
+	}
+	if texture != nil {
+		return int(texture.Width()), int(texture.Height())
+	}
+	if image != nil && image.Width > 0 && image.Height > 0 {
+		return image.Width, image.Height
+	}
+	return 1, 1
+}
+
+func newTileAnimation(tile *Tile) *tileAnimation {
+	a := &tileAnimation{tile: tile}
+	for _, f := range tile.Animation {
+		a.duration += f.Duration
+	}
+	return a
+}
+
+// update finds the frame shown at the specified time
+func (a *tileAnimation) update(elapsed float64) {
+	a.changed = false
+	if a.duration <= 0 {
+		return
+	}
+	t := math.Mod(elapsed, a.duration)
+	frame := 0
+	for frame < len(a.tile.Animation)-1 && t >= a.tile.Animation[frame].Duration {
+		t -= a.tile.Animation[frame].Duration
+		frame++
+	}
+	if frame != a.frame {
+		a.frame = frame
+		a.changed = true
+	}
+}
+
+// tileID returns the local id of the tile shown by the current frame
+func (a *tileAnimation) tileID() uint32 {
+	return a.tile.Animation[a.frame].TileID
+}
+
+// loadTextures loads the images of the meshes which don't have a texture yet
+func (r *Renderer) loadTextures() error {
+	loaded := make(map[string]*graphics.Texture)
+	load := func(image *Image) (*graphics.Texture, error) {
+		if image == nil {
+			return nil, fmt.Errorf("tilemap: missing tileset image")
+		}
+		if t, found := loaded[image.Source]; found {
+			return t, nil
+		}
+		t, err := graphics.NewTextureFromFile(image.Source)
+		if err != nil {
+			return nil, fmt.Errorf("tilemap: %w", err)
+		}
+		loaded[image.Source] = t
+		r.textures = append(r.textures, t)
+		return t, nil
+	}
+
+	var err error
+	for _, lr := range r.layers {
+		for _, c := range lr.chunks {
+			for _, mesh := range c.meshes {
+				if mesh.tile != nil && mesh.tile.Texture == nil {
+					mesh.tile.Texture, err = load(mesh.tile.Image)
+				} else if mesh.tile == nil && mesh.tileset.Texture == nil {
+					mesh.tileset.Texture, err = load(mesh.tileset.Image)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Map returns the map being drawn
+func (r *Renderer) Map() *Map {
+	return r.tilemap
+}
+
+// SetPosition moves the map, the position is the top left corner of the tile at 0,0
+func (r *Renderer) SetPosition(position mgl64.Vec3) {
+	r.position = position
+	r.forEachMesh(func(mesh *chunkMesh) {
+		if mesh.primitive != nil {
+			mesh.primitive.SetPosition(position)
+		}
+	})
+}
+
+// Position returns the position of the map
+func (r *Renderer) Position() mgl64.Vec3 {
+	return r.position
+}
+
+// Update advances the animated tiles, usually with the deltaTime passed by app.MainLoop
+func (r *Renderer) Update(deltaTime float64) {
+	if len(r.animations) == 0 {
+		return
+	}
+	r.elapsed += deltaTime
+	changed := false
+	for _, a := range r.animations {
+		a.update(r.elapsed)
+		changed = changed || a.changed
+	}
+	if !changed {
+		return
+	}
+	r.forEachMesh(func(mesh *chunkMesh) {
+		updated := false
+		for _, a := range mesh.animated {
+			if a.animation.changed {
+				mesh.setQuadUV(a.quad, a.animation.tileID(), mesh.gids[a.quad])
+				updated = true
+			}
+		}
+		if updated && mesh.primitive != nil {
+			mesh.primitive.SetUVCoords(mesh.uvCoords)
+		}
+	})
+}
+
+func (r *Renderer) forEachMesh(function func(mesh *chunkMesh)) {
+	for _, lr := range r.layers {
+		for _, c := range lr.chunks {
+			for _, mesh := range c.meshes {
+				function(mesh)
+			}
+		}
+	}
+}
+
+// Draw draws all the visible tile layers, in the order they have in the map
+func (r *Renderer) Draw(context *graphics.Context) {
+	r.drawn = 0
+	for i := range r.layers {
+		r.drawLayer(context, i)
+	}
+}
+
+// DrawLayer draws a single tile layer, by index in Map.TileLayers. Entities can be drawn between the layers
+func (r *Renderer) DrawLayer(context *graphics.Context, index int) {
+	r.drawn = 0
+	r.drawLayer(context, index)
+}
+
+// DrawInBatch is the same as Draw, the chunks are drawn as they are
+func (r *Renderer) DrawInBatch(context *graphics.Context) {
+	r.Draw(context)
+}
+
+// Texture returns nil, the chunks can use many textures
+func (r *Renderer) Texture() *graphics.Texture {
+	return nil
+}
+
+// Shader returns the shader used by the chunks
+func (r *Renderer) Shader() *graphics.ShaderProgram {
+	return r.shader
+}
+
+// Release releases the meshes of the chunks, the shader program and the textures loaded by the renderer. The textures
+// set by the caller on the tilesets and tiles are not released
+func (r *Renderer) Release() {
+	r.forEachMesh(func(mesh *chunkMesh) {
+		if mesh.primitive != nil {
+			mesh.primitive.Release()
+			mesh.primitive = nil
+		}
+		if mesh.tile != nil && r.loaded(mesh.tile.Texture) {
+			mesh.tile.Texture = nil
+		} else if mesh.tile == nil && r.loaded(mesh.tileset.Texture) {
+			mesh.tileset.Texture = nil
+		}
+	})
+	for _, texture := range r.textures {
+		texture.Release()
+	}
+	r.textures = nil
+	if r.shader != nil {
+		r.shader.Release()
+		r.shader = nil
+	}
+}
+
+// loaded tells if a texture has been loaded by the renderer
+func (r *Renderer) loaded(texture *graphics.Texture) bool {
+	for _, t := range r.textures {
+		if t == texture {
+			return true
+		}
+	}
+	return false
+}
+
+// DrawnChunks returns the number of chunks drawn by the last Draw or DrawLayer call
+func (r *Renderer) DrawnChunks() int {
+	return r.drawn
+}
+
+func (r *Renderer) drawLayer(context *graphics.Context, index int) {
+	if index < 0 || index >= len(r.layers) {
+		return
+	}
+	lr := r.layers[index]
+	if !lr.layer.Visible {
+		return
+	}
+	for _, c := range lr.chunks {
+		if !r.chunkVisible(context.Camera2D, c) {
+			continue
+		}
+		r.drawn++
+		for _, mesh := range c.meshes {
+			if mesh.primitive != nil {
+				mesh.primitive.Draw(context)
+			}
+		}
+	}
+}
+
+// chunkVisible tells if a chunk intersects the area covered by the camera
+func (r *Renderer) chunkVisible(camera *graphics.Camera2D, c *chunk) bool {
+	if camera == nil {
+		return true
+	}
+	visibleMin, visibleMax := camera.VisibleArea()
+	offset := mgl64.Vec2{r.position.X(), r.position.Y()}
+	chunkMin, chunkMax := c.min.Add(offset), c.max.Add(offset)
+	return chunkMin.X() < visibleMax.X() && chunkMax.X() > visibleMin.X() &&
+		chunkMin.Y() < visibleMax.Y() && chunkMax.Y() > visibleMin.Y()
+}
+
+// layerColor returns the color passed to the shader for a layer: its tint with the opacity applied
+func layerColor(l *Layer) (graphics.Color, error) {
+	color := graphics.Color{1, 1, 1, 1}
+	if l.TintColor != "" {
+		var err error
+		if color, err = ParseColor(l.TintColor); err != nil {
+			return color, err
+		}
+	}
+	color[3] *= float32(l.Opacity)
+	return color, nil
+}
@@ -0,0 +1,155 @@
+package tilemap
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	m, err := ParseTMX([]byte(testTMX), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRenderer(m, mgl64.Vec3{0, 0, 0}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// quadUV returns the UV coordinates of the top left and bottom right vertices of a quad
+func quadUV(mesh *chunkMesh, quad int) [4]float32 {
+	uv := mesh.uvCoords[quad*floatsPerQuad:]
+	return [4]float32{uv[0], uv[1], uv[4], uv[5]}
+}
+
+func TestRendererChunks(t *testing.T) {
+	r := newTestRenderer(t)
+	ground := r.layers[0]
+	if len(ground.chunks) != 2 {
+		t.Fatalf("Chunks expected\n2 received\n%d", len(ground.chunks))
+	}
+	left, right := ground.chunks[0].meshes[0], ground.chunks[1].meshes[0]
+	if len(left.gids) != 4 || len(right.gids) != 1 {
+		t.Errorf("Tiles per chunk expected\n4 1 received\n%d %d", len(left.gids), len(right.gids))
+	}
+	if len(left.vertices) != 4*floatsPerQuad || len(left.uvCoords) != len(left.vertices) {
+		t.Errorf("Wrong number of vertices %d, uv %d", len(left.vertices), len(left.uvCoords))
+	}
+	if ground.chunks[1].min != (mgl64.Vec2{32, 16}) || ground.chunks[1].max != (mgl64.Vec2{48, 32}) {
+		t.Errorf("Chunk bounds expected\n[32 16] [48 32] received\n%v %v", ground.chunks[1].min, ground.chunks[1].max)
+	}
+	expectedColor := graphics.Color{1, 0, 0, float32(128.0/255) * 0.5}
+	if ground.color != expectedColor {
+		t.Errorf("Layer color expected\n%v received\n%v", expectedColor, ground.color)
+	}
+
+	// Tile 0 of the tileset, with 1px of margin and 2px of spacing
+	expected := [4]float32{1.0 / 36, 1.0 / 36, 17.0 / 36, 17.0 / 36}
+	if uv := quadUV(left, 0); uv != expected {
+		t.Errorf("UV expected\n%v received\n%v", expected, uv)
+	}
+	// Tile 3 flipped horizontally: left and right sides of the tile are swapped
+	expected = [4]float32{35.0 / 36, 19.0 / 36, 19.0 / 36, 35.0 / 36}
+	if uv := quadUV(left, 3); uv != expected {
+		t.Errorf("Flipped UV expected\n%v received\n%v", expected, uv)
+	}
+	// The deco layer has a tile in the second chunk only, moved by the group offset
+	deco := r.layers[1]
+	if len(deco.chunks) != 1 || deco.chunks[0].min != (mgl64.Vec2{42, 4}) {
+		t.Errorf("Wrong deco layer chunks")
+	}
+}
+
+func TestRendererFlipFlags(t *testing.T) {
+	var tests = []struct {
+		flags uint32
+		// UV corners (0 = u1/v1, 1 = u2/v2) of the top left, bottom left and top right vertices
+		expected [3][2]int
+	}{
+		{0, [3][2]int{{0, 0}, {0, 1}, {1, 0}}},
+		{FlippedVertically, [3][2]int{{0, 1}, {0, 0}, {1, 1}}},
+		{FlippedDiagonally, [3][2]int{{0, 0}, {1, 0}, {0, 1}}},
+		// Rotated by 90 degrees clockwise
+		{FlippedDiagonally | FlippedHorizontally, [3][2]int{{0, 1}, {1, 1}, {0, 0}}},
+	}
+
+	ts := &Tileset{FirstGID: 1, TileWidth: 1, TileHeight: 1, Columns: 1, Image: &Image{Width: 1, Height: 1}}
+	for _, test := range tests {
+		mesh := &chunkMesh{tileset: ts, gids: []GID{GID(1 | test.flags)}}
+		mesh.buildUV()
+		uv := mesh.uvCoords
+		// Vertices 0, 1 and 5 are the top left, bottom left and top right corners
+		received := [3][2]int{
+			{int(uv[0]), int(uv[1])},
+			{int(uv[2]), int(uv[3])},
+			{int(uv[10]), int(uv[11])},
+		}
+		if received != test.expected {
+			t.Errorf("Flags %x: expected\n%v received\n%v", test.flags, test.expected, received)
+		}
+	}
+}
+
+func TestRendererAnimation(t *testing.T) {
+	r := newTestRenderer(t)
+	left := r.layers[0].chunks[0].meshes[0]
+	if len(r.animations) != 1 || len(left.animated) != 1 {
+		t.Fatalf("Expected one animated tile")
+	}
+	frame0 := quadUV(left, 1)
+
+	r.Update(0.05)
+	if quadUV(left, 1) != frame0 {
+		t.Errorf("The frame changed too early")
+	}
+	r.Update(0.1)
+	expected := [4]float32{19.0 / 36, 19.0 / 36, 35.0 / 36, 35.0 / 36}
+	if uv := quadUV(left, 1); uv != expected {
+		t.Errorf("Second frame expected\n%v received\n%v", expected, uv)
+	}
+	// The animation lasts 0.3 seconds, then it starts again
+	r.Update(0.2)
+	if quadUV(left, 1) != frame0 {
+		t.Errorf("The animation didn't loop")
+	}
+}
+
+func TestRendererCulling(t *testing.T) {
+	r := newTestRenderer(t)
+	camera := graphics.NewCamera2D(20, 20, 1)
+	ground := r.layers[0]
+
+	var tests = []struct {
+		x, y    float64
+		visible [2]bool
+	}{
+		{0, 0, [2]bool{true, false}},
+		{20, 10, [2]bool{true, true}},
+		{40, 0, [2]bool{false, true}},
+		{0, 40, [2]bool{false, false}},
+	}
+	for _, test := range tests {
+		camera.SetPosition(test.x, test.y)
+		visible := [2]bool{r.chunkVisible(camera, ground.chunks[0]), r.chunkVisible(camera, ground.chunks[1])}
+		if visible != test.visible {
+			t.Errorf("Camera at %v,%v: expected\n%v received\n%v", test.x, test.y, test.visible, visible)
+		}
+	}
+
+	// Moving the map moves the chunks
+	r.position = mgl64.Vec3{100, 0, 0}
+	camera.SetPosition(100, 0)
+	if !r.chunkVisible(camera, ground.chunks[0]) {
+		t.Errorf("The chunk should follow the map position")
+	}
+}
+
+func TestRendererOrientation(t *testing.T) {
+	m := &Map{Orientation: OrientationIsometric, TileWidth: 16, TileHeight: 8}
+	if _, err := newRenderer(m, mgl64.Vec3{}, 2); err == nil {
+		t.Errorf("Isometric maps should not be supported")
+	}
+}
@@ -0,0 +1,389 @@
+package tilemap
+
+// Maps created with Tiled https://www.mapeditor.org, in both TMX (XML) and TMJ (JSON) formats.
+// See https://doc.mapeditor.org/en/stable/reference/tmx-map-format/ for the meaning of the fields
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Flags stored in the highest bits of a global tile id
+const (
+	FlippedHorizontally uint32 = 0x80000000
+	FlippedVertically   uint32 = 0x40000000
+	FlippedDiagonally   uint32 = 0x20000000
+	// Only used by hexagonal maps
+	RotatedHexagonal120 uint32 = 0x10000000
+
+	flagsMask = FlippedHorizontally | FlippedVertically | FlippedDiagonally | RotatedHexagonal120
+)
+
+// Map orientations
+const (
+	OrientationOrthogonal = "orthogonal"
+	OrientationIsometric  = "isometric"
+	OrientationStaggered  = "staggered"
+	OrientationHexagonal  = "hexagonal"
+)
+
+// GID a global tile id: the id of the tile among all the tilesets of the map and the flip flags
+type GID uint32
+
+// ID returns the global tile id without the flip flags, 0 means no tile
+func (g GID) ID() uint32 {
+	return uint32(g) &^ flagsMask
+}
+
+// FlippedHorizontally tells if the tile is mirrored around the Y axis
+func (g GID) FlippedHorizontally() bool {
+	return uint32(g)&FlippedHorizontally != 0
+}
+
+// FlippedVertically tells if the tile is mirrored around the X axis
+func (g GID) FlippedVertically() bool {
+	return uint32(g)&FlippedVertically != 0
+}
+
+// FlippedDiagonally tells if the tile is mirrored around the top left - bottom right diagonal
+func (g GID) FlippedDiagonally() bool {
+	return uint32(g)&FlippedDiagonally != 0
+}
+
+// Properties custom properties set in Tiled, stored as strings whatever their type
+type Properties map[string]string
+
+// String returns a property, or the default value if not set
+func (p Properties) String(name string, defaultValue string) string {
+	if v, found := p[name]; found {
+		return v
+	}
+	return defaultValue
+}
+
+// Int returns an integer property, or the default value if not set or invalid
+func (p Properties) Int(name string, defaultValue int) int {
+	if v, err := strconv.Atoi(p[name]); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// Float returns a float property, or the default value if not set or invalid
+func (p Properties) Float(name string, defaultValue float64) float64 {
+	if v, err := strconv.ParseFloat(p[name], 64); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// Bool returns a boolean property, or the default value if not set or invalid
+func (p Properties) Bool(name string, defaultValue bool) bool {
+	if v, err := strconv.ParseBool(p[name]); err == nil {
+		return v
+	}
+	return defaultValue
+}
+
+// Map a Tiled map
+type Map struct {
+	Orientation string
+	// Order in which the tiles are drawn, only "right-down" is supported by the renderer
+	RenderOrder string
+	// Size in tiles. Infinite maps have the size of the area covered by their chunks
+	Width  int
+	Height int
+	// Size of a tile of the grid, in pixels
+	TileWidth  int
+	TileHeight int
+	Infinite   bool
+	// Background color in the "#AARRGGBB" or "#RRGGBB" format, it can be empty
+	BackgroundColor string
+	Properties      Properties
+	Tilesets        []*Tileset
+	TileLayers      []*TileLayer
+	ObjectGroups    []*ObjectGroup
+}
+
+// Tileset a set of tiles, taken from a single image or from a collection of images
+type Tileset struct {
+	// Global id of the first tile
+	FirstGID   uint32
+	Name       string
+	TileWidth  int
+	TileHeight int
+	Spacing    int
+	Margin     int
+	TileCount  int
+	Columns    int
+	// Offset applied when drawing the tiles, in pixels
+	OffsetX int
+	OffsetY int
+	// The image containing all the tiles, nil for the collections of images
+	Image      *Image
+	Properties Properties
+	// Tiles with properties, animations, collision shapes or their own image, by local id
+	Tiles map[uint32]*Tile
+	// Set this to use an already loaded texture instead of the Image one
+	Texture *graphics.Texture
+}
+
+// Image an image referenced by a map
+type Image struct {
+	// Path of the image file, relative to the map directory if the map was loaded from a file
+	Source string
+	Width  int
+	Height int
+}
+
+// Tile the extra information of a tile of a tileset
+type Tile struct {
+	ID uint32
+	// Type is called class since Tiled 1.9
+	Type       string
+	Properties Properties
+	// Image of the tile for the collections of images
+	Image *Image
+	// Animation frames, empty if the tile is not animated
+	Animation []AnimationFrame
+	// Collision shapes, relative to the top left corner of the tile
+	Objects []*Object
+	// Set this to use an already loaded texture for the tile image
+	Texture *graphics.Texture
+}
+
+// AnimationFrame a frame of an animated tile
+type AnimationFrame struct {
+	// Local id of the tile shown
+	TileID uint32
+	// Duration in seconds
+	Duration float64
+}
+
+// Layer fields shared by tile layers and object groups
+type Layer struct {
+	ID      int
+	Name    string
+	Class   string
+	Visible bool
+	Opacity float64
+	// Tint color in the "#AARRGGBB" or "#RRGGBB" format, it can be empty
+	TintColor string
+	// Offset applied when drawing the layer, in pixels
+	OffsetX    float64
+	OffsetY    float64
+	Properties Properties
+	// Position of the layer among all the layers of the map, groups are flattened
+	Order int
+}
+
+// TileLayer a grid of tiles
+type TileLayer struct {
+	Layer
+	// Area covered, in tiles. For infinite maps X and Y can be negative
+	X      int
+	Y      int
+	Width  int
+	Height int
+	// Tiles row by row, Width x Height
+	Tiles []GID
+}
+
+// TileAt returns the tile at the specified position, in tiles. It returns 0 outside the layer
+func (l *TileLayer) TileAt(x int, y int) GID {
+	x -= l.X
+	y -= l.Y
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// ObjectGroup a layer of objects, used to place entities, triggers and collision shapes
+type ObjectGroup struct {
+	Layer
+	Objects []*Object
+}
+
+// ObjectShape the kind of shape of an object
+type ObjectShape int
+
+// Shapes of the objects
+const (
+	ShapeRectangle ObjectShape = iota
+	ShapeEllipse
+	ShapePoint
+	ShapePolygon
+	ShapePolyline
+	// A tile placed as an object, its position is the bottom left corner
+	ShapeTile
+	ShapeText
+)
+
+// Object an object of an object group
+type Object struct {
+	ID   int
+	Name string
+	// Type is called class since Tiled 1.9
+	Type string
+	// Position in pixels, the top left corner for all the shapes but the tiles
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	// Rotation in degrees, clockwise, around X,Y
+	Rotation float64
+	Visible  bool
+	Shape    ObjectShape
+	// Tile of the ShapeTile objects
+	GID GID
+	// Points of polygons and polylines, relative to X,Y
+	Points     []mgl64.Vec2
+	Text       string
+	Properties Properties
+}
+
+// Bounds returns the top left corner and the size of the object, without the rotation
+func (o *Object) Bounds() (mgl64.Vec2, mgl64.Vec2) {
+	switch o.Shape {
+	case ShapeTile:
+		return mgl64.Vec2{o.X, o.Y - o.Height}, mgl64.Vec2{o.Width, o.Height}
+	case ShapePolygon, ShapePolyline:
+		if len(o.Points) == 0 {
+			return mgl64.Vec2{o.X, o.Y}, mgl64.Vec2{}
+		}
+		min, max := o.Points[0], o.Points[0]
+		for _, p := range o.Points[1:] {
+			min = mgl64.Vec2{minFloat(min.X(), p.X()), minFloat(min.Y(), p.Y())}
+			max = mgl64.Vec2{maxFloat(max.X(), p.X()), maxFloat(max.Y(), p.Y())}
+		}
+		return mgl64.Vec2{o.X + min.X(), o.Y + min.Y()}, max.Sub(min)
+	}
+	return mgl64.Vec2{o.X, o.Y}, mgl64.Vec2{o.Width, o.Height}
+}
+
+// TilesetForGID returns the tileset a global tile id belongs to, nil for the empty tile
+func (m *Map) TilesetForGID(gid GID) *Tileset {
+	id := gid.ID()
+	if id == 0 {
+		return nil
+	}
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID <= id && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	return found
+}
+
+// TileLayer returns a tile layer by name, nil if not found
+func (m *Map) TileLayer(name string) *TileLayer {
+	for _, l := range m.TileLayers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// ObjectGroup returns an object group by name, nil if not found
+func (m *Map) ObjectGroup(name string) *ObjectGroup {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Objects returns the objects of all the groups having the specified type, pass an empty type to get all of them
+func (m *Map) Objects(objectType string) []*Object {
+	var objects []*Object
+	for _, g := range m.ObjectGroups {
+		for _, o := range g.Objects {
+			if objectType == "" || o.Type == objectType {
+				objects = append(objects, o)
+			}
+		}
+	}
+	return objects
+}
+
+// Tile returns the extra information of a tile by local id, nil if there is none
+func (ts *Tileset) Tile(id uint32) *Tile {
+	return ts.Tiles[id]
+}
+
+// TileRect returns the rectangle, in pixels, covered by a tile in the tileset image
+func (ts *Tileset) TileRect(id uint32) (x int, y int, width int, height int) {
+	if tile := ts.Tiles[id]; tile != nil && tile.Image != nil {
+		return 0, 0, tile.Image.Width, tile.Image.Height
+	}
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	col := int(id) % columns
+	row := int(id) / columns
+	x = ts.Margin + col*(ts.TileWidth+ts.Spacing)
+	y = ts.Margin + row*(ts.TileHeight+ts.Spacing)
+	return x, y, ts.TileWidth, ts.TileHeight
+}
+
+// ParseColor parses a color in the "#AARRGGBB" or "#RRGGBB" format used by Tiled
+func ParseColor(s string) (graphics.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex = "ff" + hex
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return graphics.Color{}, fmt.Errorf("invalid color '%s'", s)
+	}
+	return graphics.Color{
+		float32(v>>16&0xff) / 255,
+		float32(v>>8&0xff) / 255,
+		float32(v&0xff) / 255,
+		float32(v>>24&0xff) / 255,
+	}, nil
+}
+
+// validate checks the consistency of a parsed map and fills the derived fields
+func (m *Map) validate() error {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("invalid tile size %dx%d", m.TileWidth, m.TileHeight)
+	}
+	for _, ts := range m.Tilesets {
+		if ts.FirstGID == 0 {
+			return fmt.Errorf("tileset '%s' has no firstgid", ts.Name)
+		}
+		if ts.Columns == 0 && ts.Image != nil && ts.TileWidth > 0 {
+			ts.Columns = (ts.Image.Width - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+		}
+	}
+	for _, l := range m.TileLayers {
+		if len(l.Tiles) != l.Width*l.Height {
+			return fmt.Errorf("layer '%s' has %d tiles, expecting %dx%d", l.Name, len(l.Tiles), l.Width, l.Height)
+		}
+	}
+	return nil
+}
+
+func minFloat(a float64, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
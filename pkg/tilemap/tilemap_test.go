@@ -0,0 +1,332 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="16" tileheight="16" infinite="0" backgroundcolor="#336699">
+ <properties>
+  <property name="music" value="level1.ogg"/>
+  <property name="gravity" type="float" value="9.8"/>
+ </properties>
+ <tileset firstgid="1" name="terrain" tilewidth="16" tileheight="16" spacing="2" margin="1" tilecount="4" columns="2">
+  <image source="terrain.png" width="36" height="36"/>
+  <tile id="1" class="water">
+   <properties><property name="solid" type="bool" value="false"/></properties>
+   <animation>
+    <frame tileid="1" duration="100"/>
+    <frame tileid="3" duration="200"/>
+   </animation>
+  </tile>
+  <tile id="2">
+   <objectgroup><object id="1" x="0" y="8" width="16" height="8"/></objectgroup>
+  </tile>
+ </tileset>
+ <layer id="1" name="ground" width="3" height="2" opacity="0.5" tintcolor="#80ff0000">
+  <data encoding="csv">
+1,2,0,
+3,2147483652,1
+</data>
+ </layer>
+ <group id="5" name="top" offsetx="10" visible="0">
+  <layer id="2" name="deco" width="3" height="2" offsety="4">
+   <data><tile gid="0"/><tile gid="0"/><tile gid="4"/><tile/><tile/><tile/></data>
+  </layer>
+ </group>
+ <objectgroup id="3" name="entities">
+  <object id="1" name="player" type="spawn" x="32" y="16" width="16" height="16" rotation="90"/>
+  <object id="2" name="coin" x="10" y="20"><point/></object>
+  <object id="3" class="hill" x="0" y="0"><polygon points="0,0 10,5 -2,8"/></object>
+  <object id="4" x="1" y="2"><polyline points="0,0 4,4"/></object>
+  <object id="5" x="5" y="5" width="8" height="4"><ellipse/></object>
+  <object id="6" gid="3" x="20" y="40" width="16" height="16"/>
+  <object id="7" x="0" y="0" width="50" height="10" visible="0"><text wrap="1">Hello</text></object>
+ </objectgroup>
+</map>`
+
+const testTMJ = `{
+ "orientation": "orthogonal", "renderorder": "right-down", "width": 3, "height": 2,
+ "tilewidth": 16, "tileheight": 16, "infinite": false,
+ "properties": [{"name": "gravity", "type": "float", "value": 9.8}, {"name": "music", "type": "string", "value": "level1.ogg"}],
+ "tilesets": [{
+  "firstgid": 1, "name": "terrain", "tilewidth": 16, "tileheight": 16, "spacing": 2, "margin": 1,
+  "tilecount": 4, "columns": 2, "image": "terrain.png", "imagewidth": 36, "imageheight": 36,
+  "tiles": [
+   {"id": 1, "type": "water", "properties": [{"name": "solid", "type": "bool", "value": false}],
+    "animation": [{"tileid": 1, "duration": 100}, {"tileid": 3, "duration": 200}]},
+   {"id": 2, "objectgroup": {"objects": [{"id": 1, "x": 0, "y": 8, "width": 16, "height": 8}]}}
+  ]
+ }],
+ "layers": [
+  {"type": "tilelayer", "id": 1, "name": "ground", "width": 3, "height": 2, "opacity": 0.5, "tintcolor": "#80ff0000",
+   "data": [1, 2, 0, 3, 2147483652, 1]},
+  {"type": "group", "id": 5, "name": "top", "offsetx": 10, "visible": false, "layers": [
+   {"type": "tilelayer", "id": 2, "name": "deco", "width": 3, "height": 2, "offsety": 4, "data": [0, 0, 4, 0, 0, 0]}
+  ]},
+  {"type": "objectgroup", "id": 3, "name": "entities", "objects": [
+   {"id": 1, "name": "player", "type": "spawn", "x": 32, "y": 16, "width": 16, "height": 16, "rotation": 90},
+   {"id": 2, "name": "coin", "x": 10, "y": 20, "point": true},
+   {"id": 3, "class": "hill", "x": 0, "y": 0, "polygon": [{"x": 0, "y": 0}, {"x": 10, "y": 5}, {"x": -2, "y": 8}]},
+   {"id": 4, "x": 1, "y": 2, "polyline": [{"x": 0, "y": 0}, {"x": 4, "y": 4}]},
+   {"id": 5, "x": 5, "y": 5, "width": 8, "height": 4, "ellipse": true},
+   {"id": 6, "gid": 3, "x": 20, "y": 40, "width": 16, "height": 16},
+   {"id": 7, "x": 0, "y": 0, "width": 50, "height": 10, "visible": false, "text": {"text": "Hello", "wrap": true}}
+  ]}
+ ]
+}`
+
+// checkTestMap checks the content of testTMX and testTMJ, which describe the same map
+func checkTestMap(t *testing.T, format string, m *Map) {
+	if m.Width != 3 || m.Height != 2 || m.TileWidth != 16 || m.TileHeight != 16 || m.Infinite {
+		t.Errorf("%s: wrong map size %dx%d, tiles %dx%d", format, m.Width, m.Height, m.TileWidth, m.TileHeight)
+	}
+	if m.Properties.String("music", "") != "level1.ogg" || m.Properties.Float("gravity", 0) != 9.8 {
+		t.Errorf("%s: wrong map properties %v", format, m.Properties)
+	}
+
+	if len(m.Tilesets) != 1 {
+		t.Fatalf("%s: expected\n1 tileset received\n%d", format, len(m.Tilesets))
+	}
+	ts := m.Tilesets[0]
+	if ts.Image == nil || ts.Image.Source != filepath.Join("maps", "terrain.png") || ts.Columns != 2 {
+		t.Errorf("%s: wrong tileset image %+v", format, ts.Image)
+	}
+	water := ts.Tile(1)
+	expectedAnimation := []AnimationFrame{{1, 0.1}, {3, 0.2}}
+	if water == nil || water.Type != "water" || !reflect.DeepEqual(water.Animation, expectedAnimation) {
+		t.Errorf("%s: wrong animated tile %+v", format, water)
+	} else if water.Properties.Bool("solid", true) {
+		t.Errorf("%s: wrong tile properties %v", format, water.Properties)
+	}
+	if ts.Tile(2) == nil || len(ts.Tile(2).Objects) != 1 || ts.Tile(2).Objects[0].Height != 8 {
+		t.Errorf("%s: wrong tile collision shapes", format)
+	}
+
+	if len(m.TileLayers) != 2 {
+		t.Fatalf("%s: expected\n2 tile layers received\n%d", format, len(m.TileLayers))
+	}
+	ground := m.TileLayer("ground")
+	expectedTiles := []GID{1, 2, 0, 3, GID(FlippedHorizontally | 4), 1}
+	if !reflect.DeepEqual(ground.Tiles, expectedTiles) {
+		t.Errorf("%s: tiles expected\n%v received\n%v", format, expectedTiles, ground.Tiles)
+	}
+	if ground.Opacity != 0.5 || ground.TintColor != "#80ff0000" || !ground.Visible || ground.Order != 0 {
+		t.Errorf("%s: wrong ground layer %+v", format, ground.Layer)
+	}
+	if gid := ground.TileAt(1, 1); gid.ID() != 4 || !gid.FlippedHorizontally() || gid.FlippedVertically() {
+		t.Errorf("%s: wrong flipped tile %x", format, uint32(gid))
+	}
+	deco := m.TileLayer("deco")
+	if deco.Visible || deco.OffsetX != 10 || deco.OffsetY != 4 || deco.TileAt(2, 0) != 4 || deco.Order != 1 {
+		t.Errorf("%s: wrong grouped layer %+v", format, deco.Layer)
+	}
+
+	group := m.ObjectGroup("entities")
+	if group == nil || len(group.Objects) != 7 || group.Order != 2 {
+		t.Fatalf("%s: wrong object group %+v", format, group)
+	}
+	var shapes []ObjectShape
+	for _, o := range group.Objects {
+		shapes = append(shapes, o.Shape)
+	}
+	expectedShapes := []ObjectShape{ShapeRectangle, ShapePoint, ShapePolygon, ShapePolyline, ShapeEllipse, ShapeTile, ShapeText}
+	if !reflect.DeepEqual(shapes, expectedShapes) {
+		t.Errorf("%s: shapes expected\n%v received\n%v", format, expectedShapes, shapes)
+	}
+	player := group.Objects[0]
+	if player.Name != "player" || player.Type != "spawn" || player.Rotation != 90 || !player.Visible {
+		t.Errorf("%s: wrong player object %+v", format, player)
+	}
+	hill := group.Objects[2]
+	expectedPoints := []mgl64.Vec2{{0, 0}, {10, 5}, {-2, 8}}
+	if hill.Type != "hill" || !reflect.DeepEqual(hill.Points, expectedPoints) {
+		t.Errorf("%s: wrong polygon %+v", format, hill)
+	}
+	if min, size := hill.Bounds(); min != (mgl64.Vec2{-2, 0}) || size != (mgl64.Vec2{12, 8}) {
+		t.Errorf("%s: polygon bounds expected\n[-2 0] [12 8] received\n%v %v", format, min, size)
+	}
+	tile := group.Objects[5]
+	if min, _ := tile.Bounds(); tile.GID != 3 || min != (mgl64.Vec2{20, 24}) {
+		t.Errorf("%s: wrong tile object %+v", format, tile)
+	}
+	if text := group.Objects[6]; text.Text != "Hello" || text.Visible {
+		t.Errorf("%s: wrong text object %+v", format, text)
+	}
+	if spawns := m.Objects("spawn"); len(spawns) != 1 || spawns[0] != player {
+		t.Errorf("%s: Objects by type failed", format)
+	}
+}
+
+func TestParseTMX(t *testing.T) {
+	m, err := ParseTMX([]byte(testTMX), "maps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTestMap(t, "TMX", m)
+	if m.BackgroundColor != "#336699" {
+		t.Errorf("Background color expected\n#336699 received\n%s", m.BackgroundColor)
+	}
+}
+
+func TestParseTMJ(t *testing.T) {
+	m, err := ParseTMJ([]byte(testTMJ), "maps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTestMap(t, "TMJ", m)
+}
+
+func encodeTestTiles(t *testing.T, tiles []uint32, compression string) string {
+	raw := new(bytes.Buffer)
+	binary.Write(raw, binary.LittleEndian, tiles)
+
+	compressed := new(bytes.Buffer)
+	switch compression {
+	case CompressionZlib:
+		w := zlib.NewWriter(compressed)
+		w.Write(raw.Bytes())
+		w.Close()
+	case CompressionGzip:
+		w := gzip.NewWriter(compressed)
+		w.Write(raw.Bytes())
+		w.Close()
+	default:
+		compressed = raw
+	}
+	return base64.StdEncoding.EncodeToString(compressed.Bytes())
+}
+
+func TestDecodeTileData(t *testing.T) {
+	tiles := []uint32{1, 0, 7, FlippedVertically | 2}
+	expected := []GID{1, 0, 7, GID(FlippedVertically | 2)}
+
+	var tests = []struct {
+		encoding    string
+		compression string
+		data        string
+	}{
+		{EncodingCSV, CompressionNone, "1,0,7,\n1073741826"},
+		{EncodingBase64, CompressionNone, encodeTestTiles(t, tiles, CompressionNone)},
+		{EncodingBase64, CompressionZlib, "\n   " + encodeTestTiles(t, tiles, CompressionZlib) + "\n  "},
+		{EncodingBase64, CompressionGzip, encodeTestTiles(t, tiles, CompressionGzip)},
+	}
+	for _, test := range tests {
+		decoded, err := DecodeTileData(test.encoding, test.compression, test.data)
+		if err != nil {
+			t.Errorf("%s %s: %v", test.encoding, test.compression, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, expected) {
+			t.Errorf("%s %s: expected\n%v received\n%v", test.encoding, test.compression, expected, decoded)
+		}
+	}
+
+	if _, err := DecodeTileData(EncodingBase64, "zstd", "AAAA"); err == nil {
+		t.Errorf("zstd should not be supported")
+	}
+	if _, err := DecodeTileData(EncodingCSV, CompressionNone, "1,x"); err == nil {
+		t.Errorf("invalid csv should fail")
+	}
+}
+
+func TestInfiniteMap(t *testing.T) {
+	data := `<map orientation="orthogonal" width="4" height="4" tilewidth="8" tileheight="8" infinite="1">
+ <layer id="1" name="ground" width="4" height="4">
+  <data encoding="base64" compression="zlib">
+   <chunk x="-2" y="0" width="2" height="1">` + encodeTestTiles(t, []uint32{1, 2}, CompressionZlib) + `</chunk>
+   <chunk x="0" y="1" width="2" height="1">` + encodeTestTiles(t, []uint32{3, 4}, CompressionZlib) + `</chunk>
+  </data>
+ </layer>
+</map>`
+	m, err := ParseTMX([]byte(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := m.TileLayers[0]
+	if l.X != -2 || l.Y != 0 || l.Width != 4 || l.Height != 2 {
+		t.Errorf("Infinite layer area expected\n-2,0 4x2 received\n%d,%d %dx%d", l.X, l.Y, l.Width, l.Height)
+	}
+	expected := []GID{1, 2, 0, 0, 0, 0, 3, 4}
+	if !reflect.DeepEqual(l.Tiles, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, l.Tiles)
+	}
+	if l.TileAt(-1, 0) != 2 || l.TileAt(1, 1) != 4 || l.TileAt(5, 5) != 0 {
+		t.Errorf("TileAt failed on an infinite layer")
+	}
+}
+
+func TestExternalTileset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tilemap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tsx := `<tileset name="items" tilewidth="8" tileheight="8" tilecount="2" columns="2">
+ <tileoffset x="1" y="-2"/>
+ <image source="../images/items.png" width="16" height="8"/>
+</tileset>`
+	tsj := `{"name": "props", "tilewidth": 32, "tileheight": 32, "tilecount": 1, "columns": 0,
+ "tiles": [{"id": 0, "image": "tree.png", "imagewidth": 32, "imageheight": 64}]}`
+	tmj := `{"orientation": "orthogonal", "width": 1, "height": 1, "tilewidth": 8, "tileheight": 8,
+ "tilesets": [{"firstgid": 1, "source": "tilesets/items.tsx"}, {"firstgid": 3, "source": "tilesets/props.tsj"}],
+ "layers": [{"type": "tilelayer", "name": "l", "width": 1, "height": 1, "data": [3]}]}`
+	os.MkdirAll(filepath.Join(dir, "tilesets"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "tilesets", "items.tsx"), []byte(tsx), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "tilesets", "props.tsj"), []byte(tsj), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "level.tmj"), []byte(tmj), 0644)
+
+	m, err := NewMapFromFile(filepath.Join(dir, "level.tmj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, props := m.Tilesets[0], m.Tilesets[1]
+	if items.FirstGID != 1 || items.OffsetX != 1 || items.OffsetY != -2 {
+		t.Errorf("Wrong TSX tileset %+v", items)
+	}
+	if items.Image.Source != filepath.Join(dir, "images", "items.png") {
+		t.Errorf("Image path expected\n%s received\n%s", filepath.Join(dir, "images", "items.png"), items.Image.Source)
+	}
+	if props.FirstGID != 3 || props.Tile(0).Image.Source != filepath.Join(dir, "tilesets", "tree.png") {
+		t.Errorf("Wrong TSJ tileset %+v", props)
+	}
+	if m.TilesetForGID(2) != items || m.TilesetForGID(3) != props || m.TilesetForGID(0) != nil {
+		t.Errorf("TilesetForGID failed")
+	}
+	if x, y, w, h := props.TileRect(0); x != 0 || y != 0 || w != 32 || h != 64 {
+		t.Errorf("TileRect of an image collection expected\n0 0 32 64 received\n%d %d %d %d", x, y, w, h)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	var tests = []struct {
+		s        string
+		expected graphics.Color
+	}{
+		{"#ff0000", graphics.Color{1, 0, 0, 1}},
+		{"#8000ff00", graphics.Color{0, 1, 0, 128.0 / 255}},
+		{"0000ff", graphics.Color{0, 0, 1, 1}},
+	}
+	for _, test := range tests {
+		c, err := ParseColor(test.s)
+		if err != nil || c != test.expected {
+			t.Errorf("%s: expected\n%v received\n%v %v", test.s, test.expected, c, err)
+		}
+	}
+	if _, err := ParseColor("#12"); err == nil {
+		t.Errorf("invalid color should fail")
+	}
+}
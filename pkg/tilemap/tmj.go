@@ -0,0 +1,360 @@
+package tilemap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type jsonProperty struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type jsonObject struct {
+	ID         int                    `json:"id"`
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Class      string                 `json:"class"`
+	X          float64                `json:"x"`
+	Y          float64                `json:"y"`
+	Width      float64                `json:"width"`
+	Height     float64                `json:"height"`
+	Rotation   float64                `json:"rotation"`
+	GID        uint32                 `json:"gid"`
+	Visible    *bool                  `json:"visible"`
+	Ellipse    bool                   `json:"ellipse"`
+	Point      bool                   `json:"point"`
+	Polygon    []jsonPoint            `json:"polygon"`
+	Polyline   []jsonPoint            `json:"polyline"`
+	Text       *struct{ Text string } `json:"text"`
+	Properties []jsonProperty         `json:"properties"`
+}
+
+type jsonChunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Class       string          `json:"class"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	TintColor   string          `json:"tintcolor"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []jsonChunk     `json:"chunks"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonTile struct {
+	ID          uint32         `json:"id"`
+	Type        string         `json:"type"`
+	Class       string         `json:"class"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	Properties  []jsonProperty `json:"properties"`
+	Animation   []struct {
+		TileID   uint32  `json:"tileid"`
+		Duration float64 `json:"duration"`
+	} `json:"animation"`
+	ObjectGroup *jsonLayer `json:"objectgroup"`
+}
+
+type jsonTileset struct {
+	FirstGID    uint32 `json:"firstgid"`
+	Source      string `json:"source"`
+	Name        string `json:"name"`
+	TileWidth   int    `json:"tilewidth"`
+	TileHeight  int    `json:"tileheight"`
+	Spacing     int    `json:"spacing"`
+	Margin      int    `json:"margin"`
+	TileCount   int    `json:"tilecount"`
+	Columns     int    `json:"columns"`
+	Image       string `json:"image"`
+	ImageWidth  int    `json:"imagewidth"`
+	ImageHeight int    `json:"imageheight"`
+	TileOffset  *struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"tileoffset"`
+	Properties []jsonProperty `json:"properties"`
+	Tiles      []jsonTile     `json:"tiles"`
+}
+
+type jsonMap struct {
+	Orientation     string         `json:"orientation"`
+	RenderOrder     string         `json:"renderorder"`
+	Width           int            `json:"width"`
+	Height          int            `json:"height"`
+	TileWidth       int            `json:"tilewidth"`
+	TileHeight      int            `json:"tileheight"`
+	Infinite        bool           `json:"infinite"`
+	BackgroundColor string         `json:"backgroundcolor"`
+	Properties      []jsonProperty `json:"properties"`
+	Tilesets        []jsonTileset  `json:"tilesets"`
+	Layers          []jsonLayer    `json:"layers"`
+}
+
+// ParseTMJ parses a map in the Tiled JSON format. External tilesets and images are relative to baseDir
+func ParseTMJ(data []byte, baseDir string) (*Map, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation:     jm.Orientation,
+		RenderOrder:     jm.RenderOrder,
+		Width:           jm.Width,
+		Height:          jm.Height,
+		TileWidth:       jm.TileWidth,
+		TileHeight:      jm.TileHeight,
+		Infinite:        jm.Infinite,
+		BackgroundColor: jm.BackgroundColor,
+		Properties:      jsonToProperties(jm.Properties),
+	}
+	for i := range jm.Tilesets {
+		ts, err := jsonToTileset(&jm.Tilesets[i], baseDir)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	if err := jsonToLayers(m, jm.Layers, layerParent{visible: true, opacity: 1}); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseTSJ parses a tileset in the Tiled JSON format. Images are relative to baseDir
+func ParseTSJ(data []byte, baseDir string) (*Tileset, error) {
+	var jt jsonTileset
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return nil, err
+	}
+	// The first id is set by the map referencing the tileset
+	jt.Source = ""
+	return jsonToTileset(&jt, baseDir)
+}
+
+func jsonToTileset(jt *jsonTileset, baseDir string) (*Tileset, error) {
+	if jt.Source != "" {
+		ts, err := loadExternalTileset(resolvePath(baseDir, jt.Source))
+		if err != nil {
+			return nil, err
+		}
+		ts.FirstGID = jt.FirstGID
+		return ts, nil
+	}
+
+	ts := &Tileset{
+		FirstGID:   jt.FirstGID,
+		Name:       jt.Name,
+		TileWidth:  jt.TileWidth,
+		TileHeight: jt.TileHeight,
+		Spacing:    jt.Spacing,
+		Margin:     jt.Margin,
+		TileCount:  jt.TileCount,
+		Columns:    jt.Columns,
+		Image:      jsonToImage(jt.Image, jt.ImageWidth, jt.ImageHeight, baseDir),
+		Properties: jsonToProperties(jt.Properties),
+		Tiles:      make(map[uint32]*Tile),
+	}
+	if jt.TileOffset != nil {
+		ts.OffsetX = jt.TileOffset.X
+		ts.OffsetY = jt.TileOffset.Y
+	}
+	for _, j := range jt.Tiles {
+		tile := &Tile{
+			ID:         j.ID,
+			Type:       firstNotEmpty(j.Class, j.Type),
+			Properties: jsonToProperties(j.Properties),
+			Image:      jsonToImage(j.Image, j.ImageWidth, j.ImageHeight, baseDir),
+		}
+		for _, f := range j.Animation {
+			// Tiled stores milliseconds
+			tile.Animation = append(tile.Animation, AnimationFrame{TileID: f.TileID, Duration: f.Duration / 1000})
+		}
+		if j.ObjectGroup != nil {
+			for i := range j.ObjectGroup.Objects {
+				tile.Objects = append(tile.Objects, jsonToObject(&j.ObjectGroup.Objects[i]))
+			}
+		}
+		ts.Tiles[j.ID] = tile
+	}
+	return ts, nil
+}
+
+// jsonToLayers adds the layers to the map, the groups are flattened combining their offset, opacity and visibility
+func jsonToLayers(m *Map, layers []jsonLayer, parent layerParent) error {
+	for i := range layers {
+		jl := &layers[i]
+		layer := Layer{
+			ID:         jl.ID,
+			Name:       jl.Name,
+			Class:      jl.Class,
+			Visible:    parent.visible && (jl.Visible == nil || *jl.Visible),
+			Opacity:    parent.opacity,
+			TintColor:  jl.TintColor,
+			OffsetX:    parent.offsetX + jl.OffsetX,
+			OffsetY:    parent.offsetY + jl.OffsetY,
+			Properties: jsonToProperties(jl.Properties),
+			Order:      len(m.TileLayers) + len(m.ObjectGroups),
+		}
+		if jl.Opacity != nil {
+			layer.Opacity *= *jl.Opacity
+		}
+
+		switch jl.Type {
+		case "tilelayer":
+			tl := &TileLayer{Layer: layer, Width: jl.Width, Height: jl.Height}
+			if err := jsonToTiles(tl, jl); err != nil {
+				return fmt.Errorf("layer '%s': %v", jl.Name, err)
+			}
+			m.TileLayers = append(m.TileLayers, tl)
+		case "objectgroup":
+			og := &ObjectGroup{Layer: layer}
+			for j := range jl.Objects {
+				og.Objects = append(og.Objects, jsonToObject(&jl.Objects[j]))
+			}
+			m.ObjectGroups = append(m.ObjectGroups, og)
+		case "group":
+			group := layerParent{layer.Visible, layer.Opacity, layer.OffsetX, layer.OffsetY}
+			if err := jsonToLayers(m, jl.Layers, group); err != nil {
+				return err
+			}
+		}
+		// Image layers are ignored
+	}
+	return nil
+}
+
+func jsonToTiles(layer *TileLayer, jl *jsonLayer) error {
+	if len(jl.Chunks) == 0 {
+		tiles, err := jsonDecodeTiles(jl.Encoding, jl.Compression, jl.Data)
+		layer.Tiles = tiles
+		return err
+	}
+
+	chunks := make([]tileChunk, 0, len(jl.Chunks))
+	for _, c := range jl.Chunks {
+		tiles, err := jsonDecodeTiles(jl.Encoding, jl.Compression, c.Data)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, tileChunk{c.X, c.Y, c.Width, c.Height, tiles})
+	}
+	return mergeChunks(layer, chunks)
+}
+
+// jsonDecodeTiles decodes the data of a layer or of a chunk: an array of ids or a base64 string
+func jsonDecodeTiles(encoding string, compression string, data json.RawMessage) ([]GID, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] == '[' {
+		var ids []uint32
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return nil, err
+		}
+		tiles := make([]GID, len(ids))
+		for i, id := range ids {
+			tiles[i] = GID(id)
+		}
+		return tiles, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return DecodeTileData(encoding, compression, s)
+}
+
+func jsonToObject(jo *jsonObject) *Object {
+	o := &Object{
+		ID:         jo.ID,
+		Name:       jo.Name,
+		Type:       firstNotEmpty(jo.Class, jo.Type),
+		X:          jo.X,
+		Y:          jo.Y,
+		Width:      jo.Width,
+		Height:     jo.Height,
+		Rotation:   jo.Rotation,
+		Visible:    jo.Visible == nil || *jo.Visible,
+		Properties: jsonToProperties(jo.Properties),
+	}
+	switch {
+	case jo.GID != 0:
+		o.Shape = ShapeTile
+		o.GID = GID(jo.GID)
+	case jo.Ellipse:
+		o.Shape = ShapeEllipse
+	case jo.Point:
+		o.Shape = ShapePoint
+	case jo.Polygon != nil:
+		o.Shape = ShapePolygon
+		o.Points = jsonToPoints(jo.Polygon)
+	case jo.Polyline != nil:
+		o.Shape = ShapePolyline
+		o.Points = jsonToPoints(jo.Polyline)
+	case jo.Text != nil:
+		o.Shape = ShapeText
+		o.Text = jo.Text.Text
+	}
+	return o
+}
+
+func jsonToPoints(jp []jsonPoint) []mgl64.Vec2 {
+	points := make([]mgl64.Vec2, len(jp))
+	for i, p := range jp {
+		points[i] = mgl64.Vec2{p.X, p.Y}
+	}
+	return points
+}
+
+func jsonToImage(source string, width int, height int, baseDir string) *Image {
+	if source == "" {
+		return nil
+	}
+	return &Image{Source: resolvePath(baseDir, source), Width: width, Height: height}
+}
+
+func jsonToProperties(jp []jsonProperty) Properties {
+	properties := make(Properties, len(jp))
+	for _, p := range jp {
+		var s string
+		if err := json.Unmarshal(p.Value, &s); err == nil {
+			properties[p.Name] = s
+			continue
+		}
+		// Numbers and booleans are kept as they are written
+		properties[p.Name] = string(bytes.TrimSpace(p.Value))
+	}
+	return properties
+}
@@ -0,0 +1,409 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type xmlProperty struct {
+	Name    string `xml:"name,attr"`
+	Value   string `xml:"value,attr"`
+	Content string `xml:",chardata"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type xmlFrame struct {
+	TileID   uint32  `xml:"tileid,attr"`
+	Duration float64 `xml:"duration,attr"`
+}
+
+type xmlTile struct {
+	ID          uint32        `xml:"id,attr"`
+	Type        string        `xml:"type,attr"`
+	Class       string        `xml:"class,attr"`
+	Properties  []xmlProperty `xml:"properties>property"`
+	Image       *xmlImage     `xml:"image"`
+	Animation   []xmlFrame    `xml:"animation>frame"`
+	ObjectGroup *xmlLayer     `xml:"objectgroup"`
+}
+
+type xmlTileset struct {
+	FirstGID   uint32 `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	TileOffset *struct {
+		X int `xml:"x,attr"`
+		Y int `xml:"y,attr"`
+	} `xml:"tileoffset"`
+	Image      *xmlImage     `xml:"image"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Tiles      []xmlTile     `xml:"tile"`
+}
+
+type xmlTileData struct {
+	Tiles []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Content string `xml:",chardata"`
+}
+
+type xmlChunk struct {
+	X      int `xml:"x,attr"`
+	Y      int `xml:"y,attr"`
+	Width  int `xml:"width,attr"`
+	Height int `xml:"height,attr"`
+	xmlTileData
+}
+
+type xmlData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Chunks      []xmlChunk `xml:"chunk"`
+	xmlTileData
+}
+
+type xmlObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Polyline *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polyline"`
+	Text *struct {
+		Content string `xml:",chardata"`
+	} `xml:"text"`
+}
+
+// xmlLayer any kind of layer: tile layers, object groups and groups, told apart by the element name
+type xmlLayer struct {
+	XMLName    xml.Name
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	TintColor  string        `xml:"tintcolor,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Data       *xmlData      `xml:"data"`
+	Objects    []xmlObject   `xml:"object"`
+	Layers     []xmlLayer    `xml:",any"`
+}
+
+type xmlMap struct {
+	Orientation     string        `xml:"orientation,attr"`
+	RenderOrder     string        `xml:"renderorder,attr"`
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	Properties      []xmlProperty `xml:"properties>property"`
+	Tilesets        []xmlTileset  `xml:"tileset"`
+	Layers          []xmlLayer    `xml:",any"`
+}
+
+// ParseTMX parses a map in the TMX format. External tilesets and images are relative to baseDir
+func ParseTMX(data []byte, baseDir string) (*Map, error) {
+	var xm xmlMap
+	if err := xml.Unmarshal(data, &xm); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation:     xm.Orientation,
+		RenderOrder:     xm.RenderOrder,
+		Width:           xm.Width,
+		Height:          xm.Height,
+		TileWidth:       xm.TileWidth,
+		TileHeight:      xm.TileHeight,
+		Infinite:        xm.Infinite != 0,
+		BackgroundColor: xm.BackgroundColor,
+		Properties:      xmlToProperties(xm.Properties),
+	}
+	for _, xt := range xm.Tilesets {
+		ts, err := xmlToTileset(&xt, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	if err := xmlToLayers(m, xm.Layers, layerParent{visible: true, opacity: 1}); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParseTSX parses a tileset in the TSX format. Images are relative to baseDir
+func ParseTSX(data []byte, baseDir string) (*Tileset, error) {
+	var xt xmlTileset
+	if err := xml.Unmarshal(data, &xt); err != nil {
+		return nil, err
+	}
+	// The first id is set by the map referencing the tileset
+	xt.Source = ""
+	return xmlToTileset(&xt, baseDir)
+}
+
+func xmlToTileset(xt *xmlTileset, baseDir string) (*Tileset, error) {
+	if xt.Source != "" {
+		ts, err := loadExternalTileset(resolvePath(baseDir, xt.Source))
+		if err != nil {
+			return nil, err
+		}
+		ts.FirstGID = xt.FirstGID
+		return ts, nil
+	}
+
+	ts := &Tileset{
+		FirstGID:   xt.FirstGID,
+		Name:       xt.Name,
+		TileWidth:  xt.TileWidth,
+		TileHeight: xt.TileHeight,
+		Spacing:    xt.Spacing,
+		Margin:     xt.Margin,
+		TileCount:  xt.TileCount,
+		Columns:    xt.Columns,
+		Image:      xmlToImage(xt.Image, baseDir),
+		Properties: xmlToProperties(xt.Properties),
+		Tiles:      make(map[uint32]*Tile),
+	}
+	if xt.TileOffset != nil {
+		ts.OffsetX = xt.TileOffset.X
+		ts.OffsetY = xt.TileOffset.Y
+	}
+	for _, x := range xt.Tiles {
+		tile := &Tile{
+			ID:         x.ID,
+			Type:       firstNotEmpty(x.Class, x.Type),
+			Properties: xmlToProperties(x.Properties),
+			Image:      xmlToImage(x.Image, baseDir),
+		}
+		for _, f := range x.Animation {
+			// Tiled stores milliseconds
+			tile.Animation = append(tile.Animation, AnimationFrame{TileID: f.TileID, Duration: f.Duration / 1000})
+		}
+		if x.ObjectGroup != nil {
+			for _, xo := range x.ObjectGroup.Objects {
+				o, err := xmlToObject(&xo)
+				if err != nil {
+					return nil, fmt.Errorf("tileset '%s', tile %d: %v", ts.Name, x.ID, err)
+				}
+				tile.Objects = append(tile.Objects, o)
+			}
+		}
+		ts.Tiles[x.ID] = tile
+	}
+	return ts, nil
+}
+
+type layerParent struct {
+	visible          bool
+	opacity          float64
+	offsetX, offsetY float64
+}
+
+// xmlToLayers adds the layers to the map, the groups are flattened combining their offset, opacity and visibility
+func xmlToLayers(m *Map, layers []xmlLayer, parent layerParent) error {
+	for i := range layers {
+		xl := &layers[i]
+		layer := Layer{
+			ID:         xl.ID,
+			Name:       xl.Name,
+			Class:      xl.Class,
+			Visible:    parent.visible && (xl.Visible == nil || *xl.Visible != 0),
+			Opacity:    parent.opacity,
+			TintColor:  xl.TintColor,
+			OffsetX:    parent.offsetX + xl.OffsetX,
+			OffsetY:    parent.offsetY + xl.OffsetY,
+			Properties: xmlToProperties(xl.Properties),
+			Order:      len(m.TileLayers) + len(m.ObjectGroups),
+		}
+		if xl.Opacity != nil {
+			layer.Opacity *= *xl.Opacity
+		}
+
+		switch xl.XMLName.Local {
+		case "layer":
+			tl := &TileLayer{Layer: layer, Width: xl.Width, Height: xl.Height}
+			if err := xmlToTiles(tl, xl.Data); err != nil {
+				return fmt.Errorf("layer '%s': %v", xl.Name, err)
+			}
+			m.TileLayers = append(m.TileLayers, tl)
+		case "objectgroup":
+			og := &ObjectGroup{Layer: layer}
+			for _, xo := range xl.Objects {
+				o, err := xmlToObject(&xo)
+				if err != nil {
+					return fmt.Errorf("object group '%s': %v", xl.Name, err)
+				}
+				og.Objects = append(og.Objects, o)
+			}
+			m.ObjectGroups = append(m.ObjectGroups, og)
+		case "group":
+			group := layerParent{layer.Visible, layer.Opacity, layer.OffsetX, layer.OffsetY}
+			if err := xmlToLayers(m, xl.Layers, group); err != nil {
+				return err
+			}
+		}
+		// Image layers and editor settings are ignored
+	}
+	return nil
+}
+
+func xmlToTiles(layer *TileLayer, data *xmlData) error {
+	if data == nil {
+		layer.Tiles = make([]GID, layer.Width*layer.Height)
+		return nil
+	}
+	if len(data.Chunks) == 0 {
+		tiles, err := xmlDecodeTiles(data.Encoding, data.Compression, &data.xmlTileData)
+		layer.Tiles = tiles
+		return err
+	}
+
+	chunks := make([]tileChunk, 0, len(data.Chunks))
+	for i := range data.Chunks {
+		c := &data.Chunks[i]
+		tiles, err := xmlDecodeTiles(data.Encoding, data.Compression, &c.xmlTileData)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, tileChunk{c.X, c.Y, c.Width, c.Height, tiles})
+	}
+	return mergeChunks(layer, chunks)
+}
+
+func xmlDecodeTiles(encoding string, compression string, data *xmlTileData) ([]GID, error) {
+	if encoding == "" {
+		// Deprecated format, one element per tile
+		tiles := make([]GID, len(data.Tiles))
+		for i, t := range data.Tiles {
+			tiles[i] = GID(t.GID)
+		}
+		return tiles, nil
+	}
+	return DecodeTileData(encoding, compression, data.Content)
+}
+
+func xmlToObject(xo *xmlObject) (*Object, error) {
+	o := &Object{
+		ID:         xo.ID,
+		Name:       xo.Name,
+		Type:       firstNotEmpty(xo.Class, xo.Type),
+		X:          xo.X,
+		Y:          xo.Y,
+		Width:      xo.Width,
+		Height:     xo.Height,
+		Rotation:   xo.Rotation,
+		Visible:    xo.Visible == nil || *xo.Visible != 0,
+		Properties: xmlToProperties(xo.Properties),
+	}
+	var err error
+	switch {
+	case xo.GID != 0:
+		o.Shape = ShapeTile
+		o.GID = GID(xo.GID)
+	case xo.Ellipse != nil:
+		o.Shape = ShapeEllipse
+	case xo.Point != nil:
+		o.Shape = ShapePoint
+	case xo.Polygon != nil:
+		o.Shape = ShapePolygon
+		o.Points, err = parsePoints(xo.Polygon.Points)
+	case xo.Polyline != nil:
+		o.Shape = ShapePolyline
+		o.Points, err = parsePoints(xo.Polyline.Points)
+	case xo.Text != nil:
+		o.Shape = ShapeText
+		o.Text = xo.Text.Content
+	}
+	if err != nil {
+		return nil, fmt.Errorf("object %d: %v", xo.ID, err)
+	}
+	return o, nil
+}
+
+// parsePoints parses a list of points in the "x1,y1 x2,y2" format
+func parsePoints(s string) ([]mgl64.Vec2, error) {
+	fields := strings.Fields(s)
+	points := make([]mgl64.Vec2, 0, len(fields))
+	for _, f := range fields {
+		coords := strings.Split(f, ",")
+		if len(coords) != 2 {
+			return nil, fmt.Errorf("invalid point '%s'", f)
+		}
+		x, errX := strconv.ParseFloat(coords[0], 64)
+		y, errY := strconv.ParseFloat(coords[1], 64)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid point '%s'", f)
+		}
+		points = append(points, mgl64.Vec2{x, y})
+	}
+	return points, nil
+}
+
+func xmlToImage(xi *xmlImage, baseDir string) *Image {
+	if xi == nil {
+		return nil
+	}
+	return &Image{Source: resolvePath(baseDir, xi.Source), Width: xi.Width, Height: xi.Height}
+}
+
+func xmlToProperties(xp []xmlProperty) Properties {
+	properties := make(Properties, len(xp))
+	for _, p := range xp {
+		value := p.Value
+		if value == "" {
+			// Multi-line strings are stored as the content of the element
+			value = p.Content
+		}
+		properties[p.Name] = value
+	}
+	return properties
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
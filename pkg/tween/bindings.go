@@ -0,0 +1,107 @@
+package tween
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+// Targets of the bindings. graphics.Primitive2D, ui.Text and graphics.Camera2D implement the ones they support
+
+// Positionable can be moved
+type Positionable interface {
+	Position() mgl64.Vec3
+	SetPosition(position mgl64.Vec3)
+}
+
+// Rotatable can be rotated
+type Rotatable interface {
+	Angle() float64
+	SetAngle(radians float64)
+}
+
+// Scalable can be scaled
+type Scalable interface {
+	Scale() mgl64.Vec2
+	SetScale(scale mgl64.Vec2)
+}
+
+// Colorable has a color
+type Colorable interface {
+	Color() graphics.Color
+	SetColor(color graphics.Color)
+}
+
+// Zoomable can be zoomed, like the camera
+type Zoomable interface {
+	Zoom() float64
+	SetZoom(zoom float64)
+}
+
+// Position moves the target to a position. The start position is read when the tween starts
+func Position(target Positionable, to mgl64.Vec3, duration float64, easing EasingFunc) *Tween {
+	var from mgl64.Vec3
+	t := New(duration, easing, func(progress float64) {
+		target.SetPosition(lerpVec3(from, to, progress))
+	})
+	return t.OnStart(func() { from = target.Position() })
+}
+
+// Angle rotates the target to an angle, in radians. The start angle is read when the tween starts
+func Angle(target Rotatable, to float64, duration float64, easing EasingFunc) *Tween {
+	var from float64
+	t := New(duration, easing, func(progress float64) {
+		target.SetAngle(lerp(from, to, progress))
+	})
+	return t.OnStart(func() { from = target.Angle() })
+}
+
+// Scale scales the target. The start scale is read when the tween starts
+func Scale(target Scalable, to mgl64.Vec2, duration float64, easing EasingFunc) *Tween {
+	var from mgl64.Vec2
+	t := New(duration, easing, func(progress float64) {
+		target.SetScale(mgl64.Vec2{
+			lerp(from.X(), to.X(), progress),
+			lerp(from.Y(), to.Y(), progress),
+		})
+	})
+	return t.OnStart(func() { from = target.Scale() })
+}
+
+// Color changes the color of the target, alpha included. The start color is read when the tween starts
+func Color(target Colorable, to graphics.Color, duration float64, easing EasingFunc) *Tween {
+	var from graphics.Color
+	t := New(duration, easing, func(progress float64) {
+		var color graphics.Color
+		for i := range color {
+			color[i] = float32(lerp(float64(from[i]), float64(to[i]), progress))
+		}
+		target.SetColor(color)
+	})
+	return t.OnStart(func() { from = target.Color() })
+}
+
+// Zoom changes the zoom of the target. The start zoom is read when the tween starts
+func Zoom(target Zoomable, to float64, duration float64, easing EasingFunc) *Tween {
+	var from float64
+	t := New(duration, easing, func(progress float64) {
+		target.SetZoom(lerp(from, to, progress))
+	})
+	return t.OnStart(func() { from = target.Zoom() })
+}
+
+// Float changes a float64 variable
+func Float(value *float64, to float64, duration float64, easing EasingFunc) *Tween {
+	var from float64
+	t := New(duration, easing, func(progress float64) {
+		*value = lerp(from, to, progress)
+	})
+	return t.OnStart(func() { from = *value })
+}
+
+func lerp(from float64, to float64, progress float64) float64 {
+	return from + (to-from)*progress
+}
+
+func lerpVec3(from mgl64.Vec3, to mgl64.Vec3, progress float64) mgl64.Vec3 {
+	return from.Add(to.Sub(from).Mul(progress))
+}
@@ -0,0 +1,240 @@
+package tween
+
+// Easing functions by Robert Penner, see http://robertpenner.com/easing/
+// All of them map a progress in the range [0,1] to an eased progress, with f(0) = 0 and f(1) = 1
+
+import "math"
+
+// EasingFunc maps the linear progress of a tween to the eased one
+type EasingFunc func(t float64) float64
+
+// Linear no easing
+func Linear(t float64) float64 {
+	return t
+}
+
+// InQuad quadratic, accelerating
+func InQuad(t float64) float64 {
+	return t * t
+}
+
+// OutQuad quadratic, decelerating
+func OutQuad(t float64) float64 {
+	return t * (2 - t)
+}
+
+// InOutQuad quadratic, accelerating then decelerating
+func InOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// InCubic cubic, accelerating
+func InCubic(t float64) float64 {
+	return t * t * t
+}
+
+// OutCubic cubic, decelerating
+func OutCubic(t float64) float64 {
+	t--
+	return t*t*t + 1
+}
+
+// InOutCubic cubic, accelerating then decelerating
+func InOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return 0.5*t*t*t + 1
+}
+
+// InQuart quartic, accelerating
+func InQuart(t float64) float64 {
+	return t * t * t * t
+}
+
+// OutQuart quartic, decelerating
+func OutQuart(t float64) float64 {
+	t--
+	return 1 - t*t*t*t
+}
+
+// InOutQuart quartic, accelerating then decelerating
+func InOutQuart(t float64) float64 {
+	if t < 0.5 {
+		return 8 * t * t * t * t
+	}
+	t--
+	return 1 - 8*t*t*t*t
+}
+
+// InQuint quintic, accelerating
+func InQuint(t float64) float64 {
+	return t * t * t * t * t
+}
+
+// OutQuint quintic, decelerating
+func OutQuint(t float64) float64 {
+	t--
+	return 1 + t*t*t*t*t
+}
+
+// InOutQuint quintic, accelerating then decelerating
+func InOutQuint(t float64) float64 {
+	if t < 0.5 {
+		return 16 * t * t * t * t * t
+	}
+	t--
+	return 1 + 16*t*t*t*t*t
+}
+
+// InSine sinusoidal, accelerating
+func InSine(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+// OutSine sinusoidal, decelerating
+func OutSine(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+// InOutSine sinusoidal, accelerating then decelerating
+func InOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// InExpo exponential, accelerating
+func InExpo(t float64) float64 {
+	if t == 0 {
+		return 0
+	}
+	return math.Pow(2, 10*(t-1))
+}
+
+// OutExpo exponential, decelerating
+func OutExpo(t float64) float64 {
+	if t == 1 {
+		return 1
+	}
+	return 1 - math.Pow(2, -10*t)
+}
+
+// InOutExpo exponential, accelerating then decelerating
+func InOutExpo(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	if t < 0.5 {
+		return math.Pow(2, 20*t-10) / 2
+	}
+	return (2 - math.Pow(2, -20*t+10)) / 2
+}
+
+// InCirc circular, accelerating
+func InCirc(t float64) float64 {
+	return 1 - math.Sqrt(1-t*t)
+}
+
+// OutCirc circular, decelerating
+func OutCirc(t float64) float64 {
+	t--
+	return math.Sqrt(1 - t*t)
+}
+
+// InOutCirc circular, accelerating then decelerating
+func InOutCirc(t float64) float64 {
+	if t < 0.5 {
+		return (1 - math.Sqrt(1-4*t*t)) / 2
+	}
+	t = 2*t - 2
+	return (math.Sqrt(1-t*t) + 1) / 2
+}
+
+const (
+	backOvershoot   = 1.70158
+	backOvershootIO = backOvershoot * 1.525
+	elasticPeriod   = 2 * math.Pi / 3
+	elasticPeriodIO = 2 * math.Pi / 4.5
+)
+
+// InBack goes slightly backwards before starting
+func InBack(t float64) float64 {
+	return t * t * ((backOvershoot+1)*t - backOvershoot)
+}
+
+// OutBack overshoots the target, then it comes back
+func OutBack(t float64) float64 {
+	t--
+	return 1 + t*t*((backOvershoot+1)*t+backOvershoot)
+}
+
+// InOutBack combines InBack and OutBack
+func InOutBack(t float64) float64 {
+	if t < 0.5 {
+		return (4 * t * t * ((backOvershootIO+1)*2*t - backOvershootIO)) / 2
+	}
+	t = 2*t - 2
+	return (t*t*((backOvershootIO+1)*t+backOvershootIO) + 2) / 2
+}
+
+// InElastic oscillates with growing amplitude
+func InElastic(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	return -math.Pow(2, 10*t-10) * math.Sin((t*10-10.75)*elasticPeriod)
+}
+
+// OutElastic oscillates around the target with decreasing amplitude
+func OutElastic(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*elasticPeriod) + 1
+}
+
+// InOutElastic combines InElastic and OutElastic
+func InOutElastic(t float64) float64 {
+	if t == 0 || t == 1 {
+		return t
+	}
+	if t < 0.5 {
+		return -(math.Pow(2, 20*t-10) * math.Sin((20*t-11.125)*elasticPeriodIO)) / 2
+	}
+	return (math.Pow(2, -20*t+10)*math.Sin((20*t-11.125)*elasticPeriodIO))/2 + 1
+}
+
+// InBounce bounces with growing height
+func InBounce(t float64) float64 {
+	return 1 - OutBounce(1-t)
+}
+
+// OutBounce bounces on the target, like a falling ball
+func OutBounce(t float64) float64 {
+	const n = 7.5625
+	const d = 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// InOutBounce combines InBounce and OutBounce
+func InOutBounce(t float64) float64 {
+	if t < 0.5 {
+		return (1 - OutBounce(1-2*t)) / 2
+	}
+	return (1 + OutBounce(2*t-1)) / 2
+}
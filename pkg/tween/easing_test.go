@@ -0,0 +1,112 @@
+package tween
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+var allEasings = map[string]EasingFunc{
+	"Linear":       Linear,
+	"InQuad":       InQuad,
+	"OutQuad":      OutQuad,
+	"InOutQuad":    InOutQuad,
+	"InCubic":      InCubic,
+	"OutCubic":     OutCubic,
+	"InOutCubic":   InOutCubic,
+	"InQuart":      InQuart,
+	"OutQuart":     OutQuart,
+	"InOutQuart":   InOutQuart,
+	"InQuint":      InQuint,
+	"OutQuint":     OutQuint,
+	"InOutQuint":   InOutQuint,
+	"InSine":       InSine,
+	"OutSine":      OutSine,
+	"InOutSine":    InOutSine,
+	"InExpo":       InExpo,
+	"OutExpo":      OutExpo,
+	"InOutExpo":    InOutExpo,
+	"InCirc":       InCirc,
+	"OutCirc":      OutCirc,
+	"InOutCirc":    InOutCirc,
+	"InBack":       InBack,
+	"OutBack":      OutBack,
+	"InOutBack":    InOutBack,
+	"InElastic":    InElastic,
+	"OutElastic":   OutElastic,
+	"InOutElastic": InOutElastic,
+	"InBounce":     InBounce,
+	"OutBounce":    OutBounce,
+	"InOutBounce":  InOutBounce,
+}
+
+func TestEasingBounds(t *testing.T) {
+	for name, easing := range allEasings {
+		if v := easing(0); math.Abs(v) > epsilon {
+			t.Errorf("%s(0) expected\n0 received\n%v", name, v)
+		}
+		if v := easing(1); math.Abs(v-1) > epsilon {
+			t.Errorf("%s(1) expected\n1 received\n%v", name, v)
+		}
+	}
+}
+
+func TestEasingValues(t *testing.T) {
+	var tests = []struct {
+		name     string
+		progress float64
+		expected float64
+	}{
+		{"Linear", 0.3, 0.3},
+		{"InQuad", 0.5, 0.25},
+		{"OutQuad", 0.5, 0.75},
+		{"InOutQuad", 0.25, 0.125},
+		{"InOutQuad", 0.5, 0.5},
+		{"InCubic", 0.5, 0.125},
+		{"OutCubic", 0.5, 0.875},
+		{"InOutCubic", 0.75, 0.9375},
+		{"InQuart", 0.5, 0.0625},
+		{"InQuint", 0.5, 0.03125},
+		{"InSine", 0.5, 1 - math.Sqrt2/2},
+		{"InOutSine", 0.5, 0.5},
+		{"InExpo", 0.5, 1.0 / 32},
+		{"OutExpo", 0.5, 1 - 1.0/32},
+		{"InOutCirc", 0.5, 0.5},
+		{"InOutBack", 0.5, 0.5},
+		{"OutBounce", 0.5, 0.765625},
+		{"InBounce", 0.5, 0.234375},
+		{"InOutBounce", 0.5, 0.5},
+	}
+
+	for _, test := range tests {
+		v := allEasings[test.name](test.progress)
+		if math.Abs(v-test.expected) > epsilon {
+			t.Errorf("%s(%v) expected\n%v received\n%v", test.name, test.progress, test.expected, v)
+		}
+	}
+}
+
+func TestEasingSymmetry(t *testing.T) {
+	// The InOut variants are symmetric around the middle point
+	for name, easing := range allEasings {
+		if !strings.HasPrefix(name, "InOut") {
+			continue
+		}
+		for _, p := range []float64{0.1, 0.2, 0.3, 0.4} {
+			if v := easing(p) + easing(1-p); math.Abs(v-1) > 1e-6 {
+				t.Errorf("%s(%v) + %s(%v) expected\n1 received\n%v", name, p, name, 1-p, v)
+			}
+		}
+	}
+}
+
+func TestEasingOvershoot(t *testing.T) {
+	if v := InBack(0.2); v >= 0 {
+		t.Errorf("InBack(0.2) expected a negative value, received %v", v)
+	}
+	if v := OutBack(0.8); v <= 1 {
+		t.Errorf("OutBack(0.8) expected a value over 1, received %v", v)
+	}
+}
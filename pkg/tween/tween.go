@@ -0,0 +1,364 @@
+package tween
+
+// Tweener is anything that can be advanced in time: single tweens, sequences and parallel groups
+type Tweener interface {
+	// Advance moves the tweener forward by deltaTime seconds. Once finished, it returns true and the part of
+	// deltaTime that was not needed
+	Advance(deltaTime float64) (left float64, finished bool)
+	// Reset brings the tweener back to its initial state
+	Reset()
+}
+
+// RepeatForever makes a tween repeat until it's removed
+const RepeatForever = -1
+
+// Tween interpolates a value over time. The value itself is changed by the apply function, which receives the
+// eased progress: 0 at the start and 1 at the end
+type Tween struct {
+	duration   float64
+	delay      float64
+	easing     EasingFunc
+	apply      func(progress float64)
+	start      func()
+	repeat     int
+	yoyo       bool
+	onComplete func()
+
+	delayLeft float64
+	elapsed   float64
+	iteration int
+	started   bool
+	finished  bool
+}
+
+// New creates a tween lasting duration seconds. A nil easing is Linear
+func New(duration float64, easing EasingFunc, apply func(progress float64)) *Tween {
+	if easing == nil {
+		easing = Linear
+	}
+	return &Tween{
+		duration: duration,
+		easing:   easing,
+		apply:    apply,
+	}
+}
+
+// Delay creates a tween doing nothing for the specified seconds, used to pause a sequence
+func Delay(seconds float64) *Tween {
+	return New(seconds, Linear, nil)
+}
+
+// Call creates a tween calling a function and finishing immediately, used to trigger actions from a sequence
+func Call(function func()) *Tween {
+	return New(0, Linear, nil).OnComplete(function)
+}
+
+// SetDelay sets the seconds waited before starting
+func (t *Tween) SetDelay(seconds float64) *Tween {
+	t.delay = seconds
+	t.delayLeft = seconds
+	return t
+}
+
+// SetRepeat sets how many times the tween is repeated after the first run, RepeatForever never stops
+func (t *Tween) SetRepeat(times int) *Tween {
+	t.repeat = times
+	return t
+}
+
+// SetYoyo makes every other repetition run backwards
+func (t *Tween) SetYoyo(yoyo bool) *Tween {
+	t.yoyo = yoyo
+	return t
+}
+
+// OnStart sets a function called when the tween starts, after the delay. Bindings use it to read the start value
+func (t *Tween) OnStart(function func()) *Tween {
+	t.start = function
+	return t
+}
+
+// OnComplete sets a function called when the tween finishes
+func (t *Tween) OnComplete(function func()) *Tween {
+	t.onComplete = function
+	return t
+}
+
+// Duration returns the duration of a single run, in seconds
+func (t *Tween) Duration() float64 {
+	return t.duration
+}
+
+// Finished tells if the tween has finished
+func (t *Tween) Finished() bool {
+	return t.finished
+}
+
+// Reset brings the tween back to its initial state, the start value will be read again
+func (t *Tween) Reset() {
+	t.delayLeft = t.delay
+	t.elapsed = 0
+	t.iteration = 0
+	t.started = false
+	t.finished = false
+}
+
+// Advance moves the tween forward by deltaTime seconds
+func (t *Tween) Advance(deltaTime float64) (float64, bool) {
+	if t.finished {
+		return deltaTime, true
+	}
+	if t.delayLeft > 0 {
+		if deltaTime < t.delayLeft {
+			t.delayLeft -= deltaTime
+			return 0, false
+		}
+		deltaTime -= t.delayLeft
+		t.delayLeft = 0
+	}
+	if !t.started {
+		t.started = true
+		if t.start != nil {
+			t.start()
+		}
+	}
+
+	t.elapsed += deltaTime
+	for t.elapsed >= t.duration {
+		if t.duration <= 0 || (t.repeat != RepeatForever && t.iteration >= t.repeat) {
+			left := t.elapsed - t.duration
+			if left < 0 {
+				left = 0
+			}
+			t.elapsed = t.duration
+			t.update(1)
+			t.finished = true
+			if t.onComplete != nil {
+				t.onComplete()
+			}
+			return left, true
+		}
+		t.elapsed -= t.duration
+		t.iteration++
+	}
+	t.update(t.elapsed / t.duration)
+	return 0, false
+}
+
+// Update advances the tween, it returns true when finished
+func (t *Tween) Update(deltaTime float64) bool {
+	_, finished := t.Advance(deltaTime)
+	return finished
+}
+
+func (t *Tween) update(progress float64) {
+	if t.apply == nil {
+		return
+	}
+	if t.yoyo && t.iteration%2 == 1 {
+		progress = 1 - progress
+	}
+	t.apply(t.easing(progress))
+}
+
+// Sequence runs its tweeners one after the other
+type Sequence struct {
+	tweeners []Tweener
+	index    int
+}
+
+// NewSequence creates a sequence of tweeners
+func NewSequence(tweeners ...Tweener) *Sequence {
+	return &Sequence{tweeners: tweeners}
+}
+
+// Append adds a tweener at the end of the sequence
+func (s *Sequence) Append(tweener Tweener) *Sequence {
+	s.tweeners = append(s.tweeners, tweener)
+	return s
+}
+
+// Advance moves the sequence forward, the time left by a finished tweener is passed to the next one
+func (s *Sequence) Advance(deltaTime float64) (float64, bool) {
+	for s.index < len(s.tweeners) {
+		left, finished := s.tweeners[s.index].Advance(deltaTime)
+		if !finished {
+			return 0, false
+		}
+		deltaTime = left
+		s.index++
+	}
+	return deltaTime, true
+}
+
+// Update advances the sequence, it returns true when finished
+func (s *Sequence) Update(deltaTime float64) bool {
+	_, finished := s.Advance(deltaTime)
+	return finished
+}
+
+// Reset resets the sequence and all its tweeners
+func (s *Sequence) Reset() {
+	s.index = 0
+	for _, t := range s.tweeners {
+		t.Reset()
+	}
+}
+
+// Parallel runs its tweeners at the same time, it finishes when all of them have finished
+type Parallel struct {
+	tweeners []Tweener
+	finished []bool
+	left     []float64
+}
+
+// NewParallel creates a group of tweeners running together
+func NewParallel(tweeners ...Tweener) *Parallel {
+	return &Parallel{
+		tweeners: tweeners,
+		finished: make([]bool, len(tweeners)),
+		left:     make([]float64, len(tweeners)),
+	}
+}
+
+// Add adds a tweener to the group
+func (p *Parallel) Add(tweener Tweener) *Parallel {
+	p.tweeners = append(p.tweeners, tweener)
+	p.finished = append(p.finished, false)
+	p.left = append(p.left, 0)
+	return p
+}
+
+// Advance moves all the running tweeners forward
+func (p *Parallel) Advance(deltaTime float64) (float64, bool) {
+	allFinished := true
+	for i, t := range p.tweeners {
+		if p.finished[i] {
+			// Already finished, the time not needed keeps growing
+			p.left[i] += deltaTime
+			continue
+		}
+		p.left[i], p.finished[i] = t.Advance(deltaTime)
+		if !p.finished[i] {
+			allFinished = false
+		}
+	}
+	if !allFinished {
+		return 0, false
+	}
+	// The time left is the one after the last tweener finished
+	left := deltaTime
+	for _, l := range p.left {
+		if l < left {
+			left = l
+		}
+	}
+	return left, true
+}
+
+// Update advances the group, it returns true when finished
+func (p *Parallel) Update(deltaTime float64) bool {
+	_, finished := p.Advance(deltaTime)
+	return finished
+}
+
+// Reset resets the group and all its tweeners
+func (p *Parallel) Reset() {
+	for i, t := range p.tweeners {
+		t.Reset()
+		p.finished[i] = false
+		p.left[i] = 0
+	}
+}
+
+// Manager keeps a list of running tweeners and advances them, the finished ones are removed
+type Manager struct {
+	tweeners []Tweener
+	// Changes made by the callbacks of the tweeners during Update, applied when it ends
+	updating bool
+	added    []Tweener
+	removed  map[Tweener]bool
+}
+
+// NewManager creates an empty manager
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Add starts running a tweener. Added during Update, it's advanced from the next one
+func (m *Manager) Add(tweener Tweener) Tweener {
+	if m.updating {
+		m.added = append(m.added, tweener)
+		return tweener
+	}
+	m.tweeners = append(m.tweeners, tweener)
+	return tweener
+}
+
+// Remove stops a tweener, it won't be advanced anymore
+func (m *Manager) Remove(tweener Tweener) {
+	if m.updating {
+		for i, t := range m.added {
+			if t == tweener {
+				m.added = append(m.added[:i], m.added[i+1:]...)
+				return
+			}
+		}
+		for _, t := range m.tweeners {
+			if t == tweener {
+				if m.removed == nil {
+					m.removed = make(map[Tweener]bool)
+				}
+				m.removed[tweener] = true
+				return
+			}
+		}
+		return
+	}
+	for i, t := range m.tweeners {
+		if t == tweener {
+			m.tweeners = append(m.tweeners[:i], m.tweeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clear stops all the tweeners
+func (m *Manager) Clear() {
+	if m.updating {
+		m.added = nil
+		for _, t := range m.tweeners {
+			m.Remove(t)
+		}
+		return
+	}
+	m.tweeners = nil
+}
+
+// Len returns the number of running tweeners
+func (m *Manager) Len() int {
+	return len(m.tweeners) + len(m.added) - len(m.removed)
+}
+
+// Update advances all the tweeners, usually with the deltaTime passed by app.MainLoop. The callbacks of the tweeners
+// can add and remove tweeners meanwhile
+func (m *Manager) Update(deltaTime float64) {
+	m.updating = true
+	running := m.tweeners[:0]
+	for _, t := range m.tweeners {
+		if m.removed[t] {
+			continue
+		}
+		if _, finished := t.Advance(deltaTime); !finished && !m.removed[t] {
+			running = append(running, t)
+		}
+	}
+	// Clears the references to the finished tweeners
+	for i := len(running); i < len(m.tweeners); i++ {
+		m.tweeners[i] = nil
+	}
+	m.tweeners = append(running, m.added...)
+	m.updating = false
+	m.added = nil
+	m.removed = nil
+}
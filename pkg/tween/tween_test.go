@@ -0,0 +1,216 @@
+package tween
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/maxfish/gojira2d/pkg/graphics"
+	"github.com/maxfish/gojira2d/pkg/ui"
+)
+
+// The targets supported by the texts
+var (
+	_ Positionable = (*ui.Text)(nil)
+	_ Rotatable    = (*ui.Text)(nil)
+	_ Scalable     = (*ui.Text)(nil)
+	_ Colorable    = (*ui.Text)(nil)
+)
+
+func TestTweenProgress(t *testing.T) {
+	var values []float64
+	tw := New(1, Linear, func(progress float64) { values = append(values, progress) })
+	completed := 0
+	tw.OnComplete(func() { completed++ })
+
+	for i := 0; i < 3; i++ {
+		if tw.Update(0.25) {
+			t.Errorf("Step %d: finished too early", i)
+		}
+	}
+	left, finished := tw.Advance(0.5)
+	if !finished || left != 0.25 {
+		t.Errorf("expected\nfinished with 0.25 left received\n%v %v", finished, left)
+	}
+	expected := []float64{0.25, 0.5, 0.75, 1}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected\n%v received\n%v", expected, values)
+	}
+	tw.Update(1)
+	if completed != 1 {
+		t.Errorf("OnComplete expected\n1 call received\n%d", completed)
+	}
+}
+
+func TestTweenDelayRepeatYoyo(t *testing.T) {
+	var tests = []struct {
+		repeat   int
+		yoyo     bool
+		expected []float64
+		finished bool
+	}{
+		{0, false, []float64{0.5, 1, 1, 1}, true},
+		{1, false, []float64{0.5, 0, 0.5, 1}, true},
+		{1, true, []float64{0.5, 1, 0.5, 0}, true},
+		{RepeatForever, true, []float64{0.5, 1, 0.5, 0, 0.5, 1}, false},
+	}
+
+	for _, test := range tests {
+		value := -1.0
+		tw := New(1, Linear, func(progress float64) { value = progress }).
+			SetDelay(1).SetRepeat(test.repeat).SetYoyo(test.yoyo)
+		tw.Update(0.5)
+		if value != -1 {
+			t.Errorf("Repeat %d: applied during the delay", test.repeat)
+		}
+		tw.Update(0.5)
+		var values []float64
+		for range test.expected {
+			tw.Update(0.5)
+			values = append(values, value)
+		}
+		if !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("Repeat %d, yoyo %v: expected\n%v received\n%v", test.repeat, test.yoyo, test.expected, values)
+		}
+		if tw.Finished() != test.finished {
+			t.Errorf("Repeat %d: finished expected\n%v received\n%v", test.repeat, test.finished, tw.Finished())
+		}
+	}
+}
+
+func TestTweenZeroDuration(t *testing.T) {
+	value := 0.0
+	tw := New(0, Linear, func(progress float64) { value = progress })
+	left, finished := tw.Advance(0.5)
+	if !finished || left != 0.5 || value != 1 {
+		t.Errorf("expected\ntrue 0.5 1 received\n%v %v %v", finished, left, value)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var order []string
+	a, b := 0.0, 0.0
+	s := NewSequence(
+		Float(&a, 1, 1, Linear),
+		Delay(0.5),
+		Call(func() { order = append(order, "call") }),
+		Float(&b, 2, 1, Linear).OnComplete(func() { order = append(order, "b") }),
+	)
+
+	// The time left by the first tween goes to the delay and then to the second tween
+	s.Update(1.75)
+	if a != 1 || b != 0.5 {
+		t.Errorf("expected\n1 0.5 received\n%v %v", a, b)
+	}
+	left, finished := s.Advance(1)
+	if !finished || left != 0.25 || b != 2 {
+		t.Errorf("expected\ntrue 0.25 2 received\n%v %v %v", finished, left, b)
+	}
+	if !reflect.DeepEqual(order, []string{"call", "b"}) {
+		t.Errorf("expected\n[call b] received\n%v", order)
+	}
+
+	// The start value is read again after a reset
+	a = 0
+	s.Reset()
+	s.Update(0.5)
+	if a != 0.5 {
+		t.Errorf("After reset expected\n0.5 received\n%v", a)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	a, b := 0.0, 0.0
+	p := NewParallel(Float(&a, 1, 1, Linear), Float(&b, 1, 2, Linear))
+	p.Update(1)
+	if a != 1 || b != 0.5 {
+		t.Errorf("expected\n1 0.5 received\n%v %v", a, b)
+	}
+	left, finished := p.Advance(1.5)
+	if !finished || left != 0.5 || b != 1 {
+		t.Errorf("expected\ntrue 0.5 1 received\n%v %v %v", finished, left, b)
+	}
+}
+
+func TestManager(t *testing.T) {
+	a, b := 0.0, 0.0
+	m := NewManager()
+	m.Add(Float(&a, 1, 1, Linear))
+	long := m.Add(Float(&b, 1, 2, Linear))
+	m.Update(1)
+	if m.Len() != 1 {
+		t.Errorf("expected\n1 tweener received\n%d", m.Len())
+	}
+	m.Remove(long)
+	m.Update(1)
+	if m.Len() != 0 || b != 0.5 {
+		t.Errorf("expected\n0 0.5 received\n%d %v", m.Len(), b)
+	}
+}
+
+func TestManagerChangedByCallbacks(t *testing.T) {
+	a, b, c := 0.0, 0.0, 0.0
+	m := NewManager()
+	var second, third Tweener
+	m.Add(Float(&a, 1, 1, Linear).OnComplete(func() {
+		// Chained from a callback, it starts with the next update
+		second = m.Add(Float(&b, 1, 1, Linear))
+		m.Remove(third)
+	}))
+	third = m.Add(Float(&c, 1, 2, Linear))
+	m.Update(1)
+	if m.Len() != 1 || b != 0 || c != 0 {
+		t.Errorf("expected\n1 0 0 received\n%d %v %v", m.Len(), b, c)
+	}
+	m.Update(0.5)
+	if b != 0.5 || c != 0 {
+		t.Errorf("expected\n0.5 0 received\n%v %v", b, c)
+	}
+	m.Remove(second)
+	if m.Len() != 0 {
+		t.Errorf("expected\n0 tweeners received\n%d", m.Len())
+	}
+}
+
+func TestBindings(t *testing.T) {
+	p := &graphics.Primitive2D{}
+	p.SetPosition(mgl64.Vec3{0, 10, 1})
+	p.SetScale(mgl64.Vec2{1, 1})
+	p.SetColor(graphics.Color{0, 0, 0, 1})
+
+	m := NewManager()
+	m.Add(Position(p, mgl64.Vec3{10, 20, 1}, 1, Linear))
+	m.Add(Angle(p, math.Pi, 1, Linear))
+	m.Add(Scale(p, mgl64.Vec2{3, 2}, 1, Linear))
+	m.Add(Color(p, graphics.Color{1, 0.5, 0, 0}, 1, Linear))
+	m.Update(0.5)
+
+	if !p.Position().ApproxEqual(mgl64.Vec3{5, 15, 1}) {
+		t.Errorf("Position expected\n%v received\n%v", mgl64.Vec3{5, 15, 1}, p.Position())
+	}
+	if p.Angle() != math.Pi/2 {
+		t.Errorf("Angle expected\n%v received\n%v", math.Pi/2, p.Angle())
+	}
+	if !p.Scale().ApproxEqual(mgl64.Vec2{2, 1.5}) {
+		t.Errorf("Scale expected\n%v received\n%v", mgl64.Vec2{2, 1.5}, p.Scale())
+	}
+	expectedColor := graphics.Color{0.5, 0.25, 0, 0.5}
+	if p.Color() != expectedColor {
+		t.Errorf("Color expected\n%v received\n%v", expectedColor, p.Color())
+	}
+
+	m.Update(0.5)
+	if !p.Position().ApproxEqual(mgl64.Vec3{10, 20, 1}) {
+		t.Errorf("Position expected\n%v received\n%v", mgl64.Vec3{10, 20, 1}, p.Position())
+	}
+}
+
+func TestZoomBinding(t *testing.T) {
+	camera := graphics.NewCamera2D(800, 600, 1)
+	tw := Zoom(camera, 3, 2, InQuad)
+	tw.Update(1)
+	if camera.Zoom() != 1.5 {
+		t.Errorf("Zoom expected\n1.5 received\n%v", camera.Zoom())
+	}
+}
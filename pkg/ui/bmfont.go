@@ -4,11 +4,14 @@ package ui
 // http://www.angelcode.com/products/bmfont/doc/file_format.html
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/maxfish/gojira2d/pkg/graphics"
 )
 
 // BmChar holds information about a single character, see
@@ -57,19 +60,47 @@ type BmFont struct {
 	charactersCount int
 }
 
-// NewBmFontFromFile parse the font data out of a file
-func NewBmFontFromFile(fileName string) *BmFont {
-	f := &BmFont{}
+// FontFormatError a font file is not in the expected format
+type FontFormatError struct {
+	Path string
+	// Line where the error occurred, 0 if it concerns the whole file
+	Line int
+	Err  error
+}
 
-	f.pageFiles = make(map[int]string)
-	f.Characters = make(map[int32]*BmChar)
+func (e *FontFormatError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid font '%s' at line %d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("invalid font '%s': %v", e.Path, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *FontFormatError) Unwrap() error {
+	return e.Err
+}
 
+// NewBmFontFromFile parse the font data out of a file.
+// The error is a *graphics.AssetNotFoundError or a *FontFormatError
+func NewBmFontFromFile(fileName string) (*BmFont, error) {
 	fileContent, err := ioutil.ReadFile(fileName)
 	if err != nil {
-		fmt.Println(err)
+		return nil, &graphics.AssetNotFoundError{Path: fileName, Err: err}
 	}
+	return NewBmFont(fileContent, fileName)
+}
+
+// NewBmFont parse the font data, fileName is only used to report the errors.
+// The error is a *FontFormatError
+func NewBmFont(fileContent []byte, fileName string) (*BmFont, error) {
+	f := &BmFont{}
+
+	f.pageFiles = make(map[int]string)
+	f.Characters = make(map[int32]*BmChar)
+
+	var err error
 	lines := strings.Split(string(fileContent), "\n")
-	for _, line := range lines {
+	for i, line := range lines {
 		section, keyValues := f.tokenizeLine(line)
 		switch section {
 		case "info":
@@ -81,13 +112,33 @@ func NewBmFontFromFile(fileName string) *BmFont {
 		case "char":
 			f.parseCharSection(keyValues)
 		case "kerning":
-			f.parseKerningSection(keyValues)
+			err = f.parseKerningSection(keyValues)
+		}
+		if err != nil {
+			return nil, &FontFormatError{Path: fileName, Line: i + 1, Err: err}
 		}
 	}
+	if len(f.Characters) == 0 {
+		return nil, &FontFormatError{Path: fileName, Err: errors.New("no characters found")}
+	}
 
+	return f, nil
+}
+
+// MustNewBmFontFromFile is like NewBmFontFromFile but panics if the font can't be loaded
+func MustNewBmFontFromFile(fileName string) *BmFont {
+	f, err := NewBmFontFromFile(fileName)
+	if err != nil {
+		panic(err)
+	}
 	return f
 }
 
+// PageFile returns the image file of a page, relative to the font file. It's empty if the page doesn't exist
+func (f *BmFont) PageFile(page int) string {
+	return f.pageFiles[page]
+}
+
 func (f *BmFont) parseInfoSection(keyValues map[string]string) {
 	f.face = keyValues["face"]
 	f.size, _ = strconv.Atoi(keyValues["size"])
@@ -100,12 +151,12 @@ func (f *BmFont) parseInfoSection(keyValues map[string]string) {
 
 	// Padding
 	paddingStrings := strings.Split(keyValues["padding"], ",")
-	for i := 0; i < 4; i++ {
+	for i := 0; i < 4 && i < len(paddingStrings); i++ {
 		f.padding[i], _ = strconv.Atoi(paddingStrings[i])
 	}
 	// Spacing
 	spacingStrings := strings.Split(keyValues["spacing"], ",")
-	for i := 0; i < 2; i++ {
+	for i := 0; i < 2 && i < len(spacingStrings); i++ {
 		f.spacing[i], _ = strconv.Atoi(spacingStrings[i])
 	}
 }
@@ -142,16 +193,17 @@ func (f *BmFont) parseCharSection(keyValues map[string]string) {
 	f.Characters[c.id] = c
 }
 
-func (f *BmFont) parseKerningSection(keyValues map[string]string) {
+func (f *BmFont) parseKerningSection(keyValues map[string]string) error {
 	first, _ := strconv.Atoi(keyValues["first"])
 	second, _ := strconv.Atoi(keyValues["second"])
 	amount, _ := strconv.Atoi(keyValues["amount"])
 
 	char, ok := f.Characters[int32(second)]
 	if !ok {
-		fmt.Printf("Kerning parse error: char %v not found", first)
+		return fmt.Errorf("kerning of char %v not found", second)
 	}
 	char.kernings[int32(first)] = amount
+	return nil
 }
 
 var bmSectionRex = regexp.MustCompile("^(\\w+) ")
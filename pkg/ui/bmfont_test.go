@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxfish/gojira2d/pkg/graphics"
+)
+
+const testFont = `info face="Test" size=32 bold=0 italic=0 charset="" unicode=1 stretchH=100 smooth=1 aa=1 padding=1,2,3,4 spacing=1,1
+common lineHeight=40 base=30 scaleW=256 scaleH=256 pages=1 packed=0
+page id=0 file="test.png"
+chars count=2
+char id=65 x=0 y=0 width=20 height=30 xoffset=0 yoffset=2 xadvance=22 page=0 chnl=15
+char id=86 x=20 y=0 width=20 height=30 xoffset=0 yoffset=2 xadvance=22 page=0 chnl=15
+kernings count=1
+kerning first=65 second=86 amount=-2
+`
+
+func writeFont(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewBmFontFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fonts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := NewBmFontFromFile(writeFont(t, dir, "test.fnt", testFont))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Characters) != 2 || f.lineHeight != 40 || f.padding != [4]int{1, 2, 3, 4} {
+		t.Errorf("unexpected font %+v", f)
+	}
+	if kerning := f.Characters[86].kernings[65]; kerning != -2 {
+		t.Errorf("expected\n%v received\n%v", -2, kerning)
+	}
+
+	_, err = NewBmFontFromFile(filepath.Join(dir, "missing.fnt"))
+	var notFound *graphics.AssetNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected an AssetNotFoundError, received %v", err)
+	}
+
+	var formatErr *FontFormatError
+	_, err = NewBmFontFromFile(writeFont(t, dir, "kerning.fnt", testFont+"kerning first=65 second=90 amount=1\n"))
+	if !errors.As(err, &formatErr) || formatErr.Line != 9 {
+		t.Errorf("expected a FontFormatError at line 9, received %v", err)
+	}
+	_, err = NewBmFontFromFile(writeFont(t, dir, "empty.fnt", "not a font\n"))
+	if !errors.As(err, &formatErr) || formatErr.Line != 0 {
+		t.Errorf("expected a FontFormatError, received %v", err)
+	}
+}
+
+func TestNewBmFont(t *testing.T) {
+	f, err := NewBmFont([]byte(testFont), "fonts/test.fnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := f.PageFile(0); page != "test.png" {
+		t.Errorf("expected\n%v received\n%v", "test.png", page)
+	}
+	if page := f.PageFile(1); page != "" {
+		t.Errorf("expected\n%v received\n%v", "", page)
+	}
+
+	var formatErr *FontFormatError
+	if _, err = NewBmFont([]byte("not a font\n"), "fonts/empty.fnt"); !errors.As(err, &formatErr) || formatErr.Path != "fonts/empty.fnt" {
+		t.Errorf("expected a FontFormatError for fonts/empty.fnt, received %v", err)
+	}
+}
@@ -13,15 +13,40 @@ type Font struct {
 // FontRegistry is a dictionary of loaded fonts
 var FontRegistry = make(map[string]*Font)
 
-// NewFontFromFiles create Font structure from metadata and texture files
-func NewFontFromFiles(name, bmpath, texpath string) *Font {
+// NewFontFromFiles create Font structure from metadata and texture files.
+// Fonts that fail to load are not added to the registry
+func NewFontFromFiles(name, bmpath, texpath string) (*Font, error) {
 	if f, ok := FontRegistry[name]; ok {
-		return f
+		return f, nil
 	}
 
+	var err error
 	f := &Font{}
-	f.bm = NewBmFontFromFile(bmpath)
-	f.tx = g.NewTextureFromFile(texpath)
+	if f.bm, err = NewBmFontFromFile(bmpath); err != nil {
+		return nil, err
+	}
+	if f.tx, err = g.NewTextureFromFile(texpath); err != nil {
+		return nil, err
+	}
 	FontRegistry[name] = f
+	return f, nil
+}
+
+// NewFont creates a Font from metadata and a texture already loaded, it's not added to the registry
+func NewFont(bm *BmFont, tx *g.Texture) *Font {
+	return &Font{bm: bm, tx: tx}
+}
+
+// Texture returns the texture of the font
+func (f *Font) Texture() *g.Texture {
+	return f.tx
+}
+
+// MustNewFontFromFiles is like NewFontFromFiles but panics if the font can't be loaded
+func MustNewFontFromFiles(name, bmpath, texpath string) *Font {
+	f, err := NewFontFromFiles(name, bmpath, texpath)
+	if err != nil {
+		panic(err)
+	}
 	return f
 }
@@ -1,11 +1,9 @@
 package ui
 
 import (
-	"fmt"
-
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl64"
 	"github.com/maxfish/gojira2d/pkg/graphics"
+	"github.com/maxfish/gojira2d/pkg/log"
 )
 
 // Text is a UI element that just renders a string
@@ -54,7 +52,7 @@ func (t *Text) makeNewQuads() ([]float32, []float32) {
 		}
 		bmc, ok := t.font.bm.Characters[char]
 		if !ok {
-			fmt.Printf("ERR: char %v (%v) not found in font map\n", string(char), char)
+			log.Warnf("char %v (%v) not found in font map", string(char), char)
 			continue
 		}
 
@@ -107,7 +105,7 @@ func NewText(
 	color graphics.Color,
 ) *Text {
 	if textShaderProgram == nil {
-		textShaderProgram = graphics.NewShaderProgram(
+		textShaderProgram = graphics.DefaultShaderCache.MustAcquire(
 			graphics.VertexShaderBase, "", fragmentDistanceFieldFont,
 		)
 	}
@@ -146,10 +144,51 @@ func (t *Text) SetColor(color graphics.Color) {
 	t.color = color
 }
 
+// Color returns the color of the text
+func (t *Text) Color() graphics.Color {
+	return t.color
+}
+
+// SetPosition moves the text
+func (t *Text) SetPosition(position mgl64.Vec3) {
+	t.position = position
+	t.drawable.SetPosition(position)
+}
+
+// Position returns the position of the text
+func (t *Text) Position() mgl64.Vec3 {
+	return t.position
+}
+
+// SetAngle rotates the text around its position, in radians
+func (t *Text) SetAngle(radians float64) {
+	t.drawable.SetAngle(radians)
+}
+
+// Angle returns the rotation of the text, in radians
+func (t *Text) Angle() float64 {
+	return t.drawable.Angle()
+}
+
+// SetScale scales the text, on top of its size
+func (t *Text) SetScale(scale mgl64.Vec2) {
+	t.drawable.SetScale(scale)
+}
+
+// Scale returns the scaling factor of the text on X and Y
+func (t *Text) Scale() mgl64.Vec2 {
+	return t.drawable.Scale()
+}
+
+// SetParent attaches the text to a node of the scene graph, nil detaches it
+func (t *Text) SetParent(parent *graphics.Node) {
+	t.drawable.SetParent(parent)
+}
+
 // SetUniforms uploads relevant uniforms
 func (t *Text) SetUniforms() {
 	shaderProgram := t.Shader()
-	shaderProgram.SetUniform("textColor", &t.color)
+	shaderProgram.LogUniformError(shaderProgram.SetUniform("textColor", &t.color))
 }
 
 // Drawable implementation
@@ -166,8 +205,8 @@ func (t *Text) Shader() *graphics.ShaderProgram {
 
 // Draw runs all the necessary routines to make drawable appear on screen
 func (t *Text) Draw(context *graphics.Context) {
-	shaderProgram := t.Shader()
-	gl.UseProgram(shaderProgram.ID())
+	context.FlushBatch()
+	context.BindShader(t.Shader())
 	t.SetUniforms()
 	t.drawable.Draw(context)
 }
@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DefaultCurveTolerance maximum distance between a curve and its tessellation used when the tolerance is not set
+const DefaultCurveTolerance = 0.25
+
+const (
+	// Minimum number of subdivisions of every span, so that closed curves are not mistaken for a point
+	minTessellationDepth = 2
+	maxTessellationDepth = 16
+)
+
+// Curve a parametric curve, t goes from 0 (start) to 1 (end)
+type Curve interface {
+	PointAt(t float64) mgl64.Vec2
+}
+
+// QuadraticBezier a Bezier curve with one control point
+type QuadraticBezier struct {
+	P0, P1, P2 mgl64.Vec2
+}
+
+// PointAt returns the point of the curve at t
+func (c QuadraticBezier) PointAt(t float64) mgl64.Vec2 {
+	u := 1 - t
+	return c.P0.Mul(u * u).Add(c.P1.Mul(2 * u * t)).Add(c.P2.Mul(t * t))
+}
+
+// CubicBezier a Bezier curve with two control points
+type CubicBezier struct {
+	P0, P1, P2, P3 mgl64.Vec2
+}
+
+// PointAt returns the point of the curve at t
+func (c CubicBezier) PointAt(t float64) mgl64.Vec2 {
+	u := 1 - t
+	return c.P0.Mul(u * u * u).Add(c.P1.Mul(3 * u * u * t)).Add(c.P2.Mul(3 * u * t * t)).Add(c.P3.Mul(t * t * t))
+}
+
+// Catmull-Rom parameterizations
+const (
+	CatmullRomUniform     = 0.0
+	CatmullRomCentripetal = 0.5
+	CatmullRomChordal     = 1.0
+)
+
+// CatmullRom a spline passing through all its points. Alpha selects the parameterization, the centripetal one
+// never forms cusps or loops within a segment
+type CatmullRom struct {
+	Points []mgl64.Vec2
+	Closed bool
+	Alpha  float64
+}
+
+// NumSegments returns the number of segments of the spline, each one going from a point to the next one
+func (c CatmullRom) NumSegments() int {
+	if len(c.Points) < 2 {
+		return 0
+	}
+	if c.Closed {
+		return len(c.Points)
+	}
+	return len(c.Points) - 1
+}
+
+// PointAt returns the point of the spline at t, every segment covers the same range of t
+func (c CatmullRom) PointAt(t float64) mgl64.Vec2 {
+	numSegments := c.NumSegments()
+	if numSegments == 0 {
+		if len(c.Points) == 1 {
+			return c.Points[0]
+		}
+		return mgl64.Vec2{}
+	}
+	t = mgl64.Clamp(t, 0, 1) * float64(numSegments)
+	segment := int(t)
+	if segment == numSegments {
+		segment--
+	}
+	s := t - float64(segment)
+
+	n := len(c.Points)
+	p1 := c.Points[segment]
+	p2 := c.Points[(segment+1)%n]
+	var p0, p3 mgl64.Vec2
+	if c.Closed {
+		p0 = c.Points[(segment-1+n)%n]
+		p3 = c.Points[(segment+2)%n]
+	} else {
+		// The missing points at the ends are mirrored
+		if segment > 0 {
+			p0 = c.Points[segment-1]
+		} else {
+			p0 = p1.Mul(2).Sub(p2)
+		}
+		if segment+2 < n {
+			p3 = c.Points[segment+2]
+		} else {
+			p3 = p2.Mul(2).Sub(p1)
+		}
+	}
+
+	knot := func(a mgl64.Vec2, b mgl64.Vec2) float64 {
+		d := math.Pow(b.Sub(a).Len(), c.Alpha)
+		if d < 1e-9 {
+			return 1
+		}
+		return d
+	}
+	t0 := 0.0
+	t1 := t0 + knot(p0, p1)
+	t2 := t1 + knot(p1, p2)
+	t3 := t2 + knot(p2, p3)
+	u := t1 + (t2-t1)*s
+
+	lerp := func(a mgl64.Vec2, b mgl64.Vec2, ta float64, tb float64) mgl64.Vec2 {
+		return a.Mul((tb - u) / (tb - ta)).Add(b.Mul((u - ta) / (tb - ta)))
+	}
+	a1 := lerp(p0, p1, t0, t1)
+	a2 := lerp(p1, p2, t1, t2)
+	a3 := lerp(p2, p3, t2, t3)
+	b1 := lerp(a1, a2, t0, t2)
+	b2 := lerp(a2, a3, t1, t3)
+	return lerp(b1, b2, t1, t2)
+}
+
+// EllipticalArc a portion of an ellipse, the angles are in radians
+type EllipticalArc struct {
+	Center mgl64.Vec2
+	Radius mgl64.Vec2
+	// Rotation of the ellipse around its center
+	Rotation   float64
+	StartAngle float64
+	EndAngle   float64
+}
+
+// PointAt returns the point of the arc at t
+func (c EllipticalArc) PointAt(t float64) mgl64.Vec2 {
+	angle := c.StartAngle + (c.EndAngle-c.StartAngle)*t
+	p := mgl64.Vec2{c.Radius.X() * math.Cos(angle), c.Radius.Y() * math.Sin(angle)}
+	return c.Center.Add(mgl64.Rotate2D(c.Rotation).Mul2x1(p))
+}
+
+// Tessellate approximates a curve with a polyline. Straight parts get few points and tight turns many, so that
+// the polyline is never further than tolerance from the curve
+func Tessellate(curve Curve, tolerance float64) []mgl64.Vec2 {
+	_, points := tessellate(curve, tolerance)
+	return points
+}
+
+// tessellate returns the points of the polyline approximating the curve and their t
+func tessellate(curve Curve, tolerance float64) ([]float64, []mgl64.Vec2) {
+	if tolerance <= 0 {
+		tolerance = DefaultCurveTolerance
+	}
+	spans := 1
+	if segmented, ok := curve.(interface{ NumSegments() int }); ok && segmented.NumSegments() > 1 {
+		spans = segmented.NumSegments()
+	}
+
+	ts := []float64{0}
+	points := []mgl64.Vec2{curve.PointAt(0)}
+	for i := 0; i < spans; i++ {
+		t0 := float64(i) / float64(spans)
+		t1 := float64(i+1) / float64(spans)
+		ts, points = subdivideCurve(curve, t0, points[len(points)-1], t1, curve.PointAt(t1), tolerance, 0, ts, points)
+	}
+	return ts, points
+}
+
+// subdivideCurve appends the end of the span t0-t1, after splitting it in halves until each half is flat enough
+func subdivideCurve(
+	curve Curve,
+	t0 float64, p0 mgl64.Vec2,
+	t1 float64, p1 mgl64.Vec2,
+	tolerance float64, depth int,
+	ts []float64, points []mgl64.Vec2,
+) ([]float64, []mgl64.Vec2) {
+	tm := (t0 + t1) / 2
+	pm := curve.PointAt(tm)
+	if depth >= maxTessellationDepth || (depth >= minTessellationDepth &&
+		DistanceToSegment(pm, p0, p1) <= tolerance &&
+		DistanceToSegment(curve.PointAt((t0+tm)/2), p0, p1) <= tolerance &&
+		DistanceToSegment(curve.PointAt((tm+t1)/2), p0, p1) <= tolerance) {
+		return append(ts, t1), append(points, p1)
+	}
+	ts, points = subdivideCurve(curve, t0, p0, tm, pm, tolerance, depth+1, ts, points)
+	return subdivideCurve(curve, tm, pm, t1, p1, tolerance, depth+1, ts, points)
+}
+
+// DistanceToSegment returns the distance of p from the segment a-b
+func DistanceToSegment(p mgl64.Vec2, a mgl64.Vec2, b mgl64.Vec2) float64 {
+	ab := b.Sub(a)
+	lengthSquared := ab.Dot(ab)
+	if lengthSquared == 0 {
+		return p.Sub(a).Len()
+	}
+	t := mgl64.Clamp(p.Sub(a).Dot(ab)/lengthSquared, 0, 1)
+	return p.Sub(a.Add(ab.Mul(t))).Len()
+}
+
+// ArcLength maps distances along a curve to its t parameter, to move along the curve at constant speed
+type ArcLength struct {
+	curve   Curve
+	ts      []float64
+	lengths []float64
+}
+
+// NewArcLength measures a curve, the smaller the tolerance the more precise the distances
+func NewArcLength(curve Curve, tolerance float64) *ArcLength {
+	ts, points := tessellate(curve, tolerance)
+	lengths := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		lengths[i] = lengths[i-1] + points[i].Sub(points[i-1]).Len()
+	}
+	return &ArcLength{curve: curve, ts: ts, lengths: lengths}
+}
+
+// Length returns the length of the curve
+func (a *ArcLength) Length() float64 {
+	return a.lengths[len(a.lengths)-1]
+}
+
+// T returns the t parameter of the point at the given distance from the start of the curve
+func (a *ArcLength) T(distance float64) float64 {
+	if distance <= 0 {
+		return 0
+	}
+	if distance >= a.Length() {
+		return 1
+	}
+	i := sort.SearchFloat64s(a.lengths, distance)
+	l0, l1 := a.lengths[i-1], a.lengths[i]
+	return a.ts[i-1] + (a.ts[i]-a.ts[i-1])*(distance-l0)/(l1-l0)
+}
+
+// PointAtDistance returns the point at the given distance from the start of the curve
+func (a *ArcLength) PointAtDistance(distance float64) mgl64.Vec2 {
+	return a.curve.PointAt(a.T(distance))
+}
+
+// EvenlySpaced returns count points equally distant along the curve, the first and the last are its ends
+func (a *ArcLength) EvenlySpaced(count int) []mgl64.Vec2 {
+	if count < 2 {
+		return []mgl64.Vec2{a.curve.PointAt(0)}
+	}
+	points := make([]mgl64.Vec2, count)
+	step := a.Length() / float64(count-1)
+	for i := range points {
+		points[i] = a.PointAtDistance(step * float64(i))
+	}
+	return points
+}
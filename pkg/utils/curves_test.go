@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestCurvePointAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		curve    Curve
+		t        float64
+		expected mgl64.Vec2
+	}{
+		{"quadratic start", QuadraticBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{5, 10}, mgl64.Vec2{10, 0}}, 0, mgl64.Vec2{0, 0}},
+		{"quadratic middle", QuadraticBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{5, 10}, mgl64.Vec2{10, 0}}, 0.5, mgl64.Vec2{5, 5}},
+		{"cubic middle", CubicBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{0, 10}, mgl64.Vec2{10, 10}, mgl64.Vec2{10, 0}}, 0.5, mgl64.Vec2{5, 7.5}},
+		{"cubic end", CubicBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{0, 10}, mgl64.Vec2{10, 10}, mgl64.Vec2{10, 0}}, 1, mgl64.Vec2{10, 0}},
+		{"arc", EllipticalArc{mgl64.Vec2{10, 10}, mgl64.Vec2{4, 2}, 0, 0, math.Pi}, 0.5, mgl64.Vec2{10, 12}},
+		{"rotated arc", EllipticalArc{mgl64.Vec2{0, 0}, mgl64.Vec2{4, 2}, math.Pi / 2, 0, math.Pi}, 0, mgl64.Vec2{0, 4}},
+		{"catmull-rom point", CatmullRom{[]mgl64.Vec2{{0, 0}, {10, 5}, {20, 0}}, false, CatmullRomCentripetal}, 0.5, mgl64.Vec2{10, 5}},
+		{"catmull-rom end", CatmullRom{[]mgl64.Vec2{{0, 0}, {10, 5}, {20, 0}}, false, CatmullRomCentripetal}, 1, mgl64.Vec2{20, 0}},
+		{"catmull-rom line", CatmullRom{[]mgl64.Vec2{{0, 0}, {10, 0}, {20, 0}}, false, CatmullRomUniform}, 0.25, mgl64.Vec2{5, 0}},
+		{"closed catmull-rom", CatmullRom{[]mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}}, true, CatmullRomChordal}, 1, mgl64.Vec2{0, 0}},
+	}
+
+	for _, test := range tests {
+		p := test.curve.PointAt(test.t)
+		if p.Sub(test.expected).Len() > 1e-9 {
+			t.Errorf("%s: expected\n%v received\n%v", test.name, test.expected, p)
+		}
+	}
+}
+
+func TestTessellate(t *testing.T) {
+	circle := EllipticalArc{mgl64.Vec2{0, 0}, mgl64.Vec2{100, 100}, 0, 0, 2 * math.Pi}
+	for _, tolerance := range []float64{1, 0.1} {
+		points := Tessellate(circle, tolerance)
+		for i := 1; i < len(points); i++ {
+			// The middle of every chord is inside the circle by at most the tolerance
+			middle := points[i-1].Add(points[i]).Mul(0.5)
+			if d := 100 - middle.Len(); d > tolerance {
+				t.Errorf("Tolerance %v, chord %d is %v away from the circle", tolerance, i, d)
+			}
+		}
+		if points[0].Sub(points[len(points)-1]).Len() > 1e-9 {
+			t.Errorf("The tessellation of a closed curve should end on its start")
+		}
+	}
+	if len(Tessellate(circle, 0.1)) <= len(Tessellate(circle, 1)) {
+		t.Errorf("A smaller tolerance should give more points")
+	}
+
+	// Straight lines are not subdivided beyond the minimum
+	line := CubicBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{1, 0}, mgl64.Vec2{2, 0}, mgl64.Vec2{3, 0}}
+	if n := len(Tessellate(line, 0.1)); n != 5 {
+		t.Errorf("expected\n%v received\n%v", 5, n)
+	}
+}
+
+func TestArcLength(t *testing.T) {
+	// A cubic with unevenly spaced control points: t is not proportional to the distance
+	line := CubicBezier{mgl64.Vec2{0, 0}, mgl64.Vec2{1, 0}, mgl64.Vec2{2, 0}, mgl64.Vec2{30, 0}}
+	arcLength := NewArcLength(line, 0.01)
+	if math.Abs(arcLength.Length()-30) > 1e-6 {
+		t.Errorf("Length expected\n%v received\n%v", 30, arcLength.Length())
+	}
+	points := arcLength.EvenlySpaced(7)
+	for i, p := range points {
+		expected := mgl64.Vec2{float64(i) * 5, 0}
+		if !p.ApproxEqualThreshold(expected, 0.05) {
+			t.Errorf("Point %d expected\n%v received\n%v", i, expected, p)
+		}
+	}
+
+	circle := EllipticalArc{mgl64.Vec2{0, 0}, mgl64.Vec2{10, 10}, 0, 0, math.Pi}
+	arcLength = NewArcLength(circle, 0.001)
+	if math.Abs(arcLength.Length()-10*math.Pi) > 0.01 {
+		t.Errorf("Length expected\n%v received\n%v", 10*math.Pi, arcLength.Length())
+	}
+	if tm := arcLength.T(arcLength.Length() / 2); math.Abs(tm-0.5) > 1e-3 {
+		t.Errorf("T expected\n%v received\n%v", 0.5, tm)
+	}
+}
+
+func TestDistanceToSegment(t *testing.T) {
+	tests := []struct {
+		p, a, b  mgl64.Vec2
+		expected float64
+	}{
+		{mgl64.Vec2{5, 3}, mgl64.Vec2{0, 0}, mgl64.Vec2{10, 0}, 3},
+		{mgl64.Vec2{-3, 4}, mgl64.Vec2{0, 0}, mgl64.Vec2{10, 0}, 5},
+		{mgl64.Vec2{1, 1}, mgl64.Vec2{0, 0}, mgl64.Vec2{0, 0}, math.Sqrt2},
+	}
+	for _, test := range tests {
+		if d := DistanceToSegment(test.p, test.a, test.b); math.Abs(d-test.expected) > 1e-9 {
+			t.Errorf("expected\n%v received\n%v", test.expected, d)
+		}
+	}
+}
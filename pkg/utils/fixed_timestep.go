@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"errors"
+	"math"
+)
+
+// FixedTimestep splits the variable time between frames into a number of fixed size steps
+type FixedTimestep struct {
+	step        float64
+	maxSteps    int
+	accumulator float64
+}
+
+// NewFixedTimestep creates a timestep running ticksPerSecond steps per second.
+// maxSteps is the max number of steps returned by a single Advance, the time exceeding it is dropped.
+// Both must be positive
+func NewFixedTimestep(ticksPerSecond float64, maxSteps int) (*FixedTimestep, error) {
+	if !(ticksPerSecond > 0) || math.IsInf(ticksPerSecond, 1) {
+		return nil, errors.New("ticksPerSecond must be > 0")
+	}
+	if maxSteps <= 0 {
+		return nil, errors.New("maxSteps must be > 0")
+	}
+	return &FixedTimestep{
+		step:     1 / ticksPerSecond,
+		maxSteps: maxSteps,
+	}, nil
+}
+
+// Advance adds the time passed since the previous frame and returns the number of steps to run
+func (f *FixedTimestep) Advance(deltaTime float64) int {
+	f.accumulator += deltaTime
+	steps := int(f.accumulator / f.step)
+	if steps > f.maxSteps {
+		// Too far behind, it won't be possible to catch up
+		f.accumulator = math.Mod(f.accumulator, f.step)
+		return f.maxSteps
+	}
+	f.accumulator -= float64(steps) * f.step
+	return steps
+}
+
+// Step duration of a single step, in seconds
+func (f *FixedTimestep) Step() float64 {
+	return f.step
+}
+
+// Alpha returns how far the time is between the last step and the next one, in the range [0,1).
+// It's meant to be used to interpolate the state of the last two steps while rendering
+func (f *FixedTimestep) Alpha() float64 {
+	return f.accumulator / f.step
+}
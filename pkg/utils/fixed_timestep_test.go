@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestFixedTimestep(t *testing.T) {
+	var tests = []struct {
+		deltaTime float64
+		steps     int
+		alpha     float64
+	}{
+		{0.005, 0, 0.5},
+		{0.005, 1, 0},
+		{0.025, 2, 0.5},
+		{0.012, 1, 0.7},
+		// Exceeding maxSteps: the backlog is dropped
+		{0.1, 4, 0.7},
+		{0, 0, 0.7},
+	}
+
+	f, err := NewFixedTimestep(100, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mgl64.FloatEqual(f.Step(), 0.01) {
+		t.Errorf("Got step %f, expecting 0.01", f.Step())
+	}
+	for i, test := range tests {
+		steps := f.Advance(test.deltaTime)
+		if steps != test.steps {
+			t.Errorf("#%d: got %d steps, expecting %d", i, steps, test.steps)
+		}
+		if !mgl64.FloatEqualThreshold(f.Alpha(), test.alpha, 1e-6) {
+			t.Errorf("#%d: got alpha %f, expecting %f", i, f.Alpha(), test.alpha)
+		}
+	}
+}
+
+func TestFixedTimestepValidation(t *testing.T) {
+	var tests = []struct {
+		ticksPerSecond float64
+		maxSteps       int
+	}{
+		{0, 4},
+		{-60, 4},
+		{math.NaN(), 4},
+		{math.Inf(1), 4},
+		{60, 0},
+		{60, -1},
+	}
+	for _, test := range tests {
+		if f, err := NewFixedTimestep(test.ticksPerSecond, test.maxSteps); err == nil {
+			t.Errorf("expected\n%v received\n%v for %v", "an error", f, test)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Tolerance used by the triangulation to detect collinear and coincident points
+const triangulationEpsilon = 1e-10
+
+// TriangulatePolygon splits a simple polygon, convex or concave, into triangles using ear clipping.
+// The holes are connected to the outer polygon with bridges before clipping. The points can be in any winding order.
+// It returns the indices of the triangles corners, 3 per triangle, into the list made of the outer points followed
+// by the points of all the holes
+func TriangulatePolygon(points []mgl64.Vec2, holes [][]mgl64.Vec2) ([]int, error) {
+	if len(points) < 3 {
+		return nil, errors.New("a polygon needs at least 3 points")
+	}
+
+	all := append([]mgl64.Vec2(nil), points...)
+	polygon := makeIndices(0, len(points))
+	area := signedArea(all, polygon)
+	if math.Abs(area) < triangulationEpsilon {
+		return nil, errors.New("the polygon has no area")
+	}
+	// The outer polygon is counterclockwise (with the Y axis pointing up), the holes clockwise
+	if area < 0 {
+		reverseIndices(polygon)
+	}
+
+	holeIndices := make([][]int, 0, len(holes))
+	for _, hole := range holes {
+		if len(hole) < 3 {
+			return nil, errors.New("a hole needs at least 3 points")
+		}
+		indices := makeIndices(len(all), len(hole))
+		all = append(all, hole...)
+		if signedArea(all, indices) > 0 {
+			reverseIndices(indices)
+		}
+		holeIndices = append(holeIndices, indices)
+	}
+	// The holes are bridged from right to left, so a bridge never crosses a hole not yet connected
+	sort.SliceStable(holeIndices, func(i, j int) bool {
+		return all[rightmostIndex(all, holeIndices[i])].X() > all[rightmostIndex(all, holeIndices[j])].X()
+	})
+	for _, hole := range holeIndices {
+		var err error
+		polygon, err = bridgeHole(all, polygon, hole)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clipEars(all, polygon)
+}
+
+// clipEars removes one ear (a convex corner with no other point inside) at a time, until a triangle is left
+func clipEars(points []mgl64.Vec2, polygon []int) ([]int, error) {
+	triangles := make([]int, 0, (len(polygon)-2)*3)
+	for len(polygon) > 3 {
+		n := len(polygon)
+		ear := -1
+		for i := 0; i < n && ear < 0; i++ {
+			prev, next := polygon[(i-1+n)%n], polygon[(i+1)%n]
+			if isEar(points, polygon, prev, polygon[i], next) {
+				ear = i
+			}
+		}
+		if ear < 0 {
+			// Only degenerate corners left, collinear points can be dropped without changing the shape
+			for i := 0; i < n && ear < 0; i++ {
+				prev, next := polygon[(i-1+n)%n], polygon[(i+1)%n]
+				if math.Abs(cross(points[prev], points[polygon[i]], points[next])) < triangulationEpsilon {
+					ear = i
+				}
+			}
+			if ear < 0 {
+				return nil, errors.New("the polygon is not simple, it can't be triangulated")
+			}
+			polygon = append(polygon[:ear], polygon[ear+1:]...)
+			continue
+		}
+		triangles = append(triangles, polygon[(ear-1+n)%n], polygon[ear], polygon[(ear+1)%n])
+		polygon = append(polygon[:ear], polygon[ear+1:]...)
+	}
+	if math.Abs(cross(points[polygon[0]], points[polygon[1]], points[polygon[2]])) >= triangulationEpsilon {
+		triangles = append(triangles, polygon[0], polygon[1], polygon[2])
+	}
+	return triangles, nil
+}
+
+// isEar tells if the corner b of the counterclockwise polygon can be clipped
+func isEar(points []mgl64.Vec2, polygon []int, a int, b int, c int) bool {
+	pa, pb, pc := points[a], points[b], points[c]
+	if cross(pa, pb, pc) <= triangulationEpsilon {
+		return false
+	}
+	for _, i := range polygon {
+		p := points[i]
+		// The bridges duplicate some points, they are the corners of the triangle as well
+		if samePoint(p, pa) || samePoint(p, pb) || samePoint(p, pc) {
+			continue
+		}
+		if pointInTriangle(p, pa, pb, pc) {
+			return false
+		}
+	}
+	return true
+}
+
+// bridgeHole connects a hole to the polygon with two overlapping edges, going from the rightmost point of the hole
+// to a point of the polygon visible from it
+func bridgeHole(points []mgl64.Vec2, polygon []int, hole []int) ([]int, error) {
+	h := 0
+	for i := range hole {
+		if points[hole[i]].X() > points[hole[h]].X() {
+			h = i
+		}
+	}
+	m := points[hole[h]]
+
+	// Closest edge hit by a ray going right from m
+	n := len(polygon)
+	edge := -1
+	hitX := math.Inf(1)
+	for i := 0; i < n; i++ {
+		a, b := points[polygon[i]], points[polygon[(i+1)%n]]
+		if a.Y() == b.Y() || (a.Y()-m.Y())*(b.Y()-m.Y()) > 0 {
+			continue
+		}
+		x := a.X() + (m.Y()-a.Y())*(b.X()-a.X())/(b.Y()-a.Y())
+		if x >= m.X() && x < hitX {
+			hitX = x
+			edge = i
+		}
+	}
+	if edge < 0 {
+		return nil, errors.New("a hole is outside the polygon")
+	}
+
+	// The end of the edge furthest on the right is visible, unless other points are in the way
+	hit := mgl64.Vec2{hitX, m.Y()}
+	target := edge
+	if points[polygon[(edge+1)%n]].X() > points[polygon[edge]].X() {
+		target = (edge + 1) % n
+	}
+	if !samePoint(points[polygon[target]], hit) {
+		p := points[polygon[target]]
+		bestAngle := math.Inf(1)
+		bestDistance := math.Inf(1)
+		for i, index := range polygon {
+			v := points[index]
+			if i == target || samePoint(v, p) || !pointInTriangle(v, m, hit, p) {
+				continue
+			}
+			d := v.Sub(m)
+			angle := math.Abs(math.Atan2(d.Y(), d.X()))
+			if angle < bestAngle || (angle == bestAngle && d.Len() < bestDistance) {
+				bestAngle = angle
+				bestDistance = d.Len()
+				target = i
+			}
+		}
+	}
+
+	bridged := make([]int, 0, len(polygon)+len(hole)+2)
+	bridged = append(bridged, polygon[:target+1]...)
+	for i := 0; i <= len(hole); i++ {
+		bridged = append(bridged, hole[(h+i)%len(hole)])
+	}
+	bridged = append(bridged, polygon[target:]...)
+	return bridged, nil
+}
+
+// cross returns the Z of the cross product (b-a) x (c-b), positive for a counterclockwise turn
+func cross(a mgl64.Vec2, b mgl64.Vec2, c mgl64.Vec2) float64 {
+	return (b.X()-a.X())*(c.Y()-b.Y()) - (b.Y()-a.Y())*(c.X()-b.X())
+}
+
+// pointInTriangle tells if p is inside the triangle or on its border, whatever the winding
+func pointInTriangle(p mgl64.Vec2, a mgl64.Vec2, b mgl64.Vec2, c mgl64.Vec2) bool {
+	d1 := cross(a, b, p)
+	d2 := cross(b, c, p)
+	d3 := cross(c, a, p)
+	hasNegative := d1 < -triangulationEpsilon || d2 < -triangulationEpsilon || d3 < -triangulationEpsilon
+	hasPositive := d1 > triangulationEpsilon || d2 > triangulationEpsilon || d3 > triangulationEpsilon
+	return !(hasNegative && hasPositive)
+}
+
+func samePoint(a mgl64.Vec2, b mgl64.Vec2) bool {
+	return math.Abs(a.X()-b.X()) < triangulationEpsilon && math.Abs(a.Y()-b.Y()) < triangulationEpsilon
+}
+
+// signedArea returns the area of the polygon, positive if counterclockwise
+func signedArea(points []mgl64.Vec2, polygon []int) float64 {
+	area := 0.0
+	for i := range polygon {
+		a, b := points[polygon[i]], points[polygon[(i+1)%len(polygon)]]
+		area += a.X()*b.Y() - b.X()*a.Y()
+	}
+	return area / 2
+}
+
+func rightmostIndex(points []mgl64.Vec2, polygon []int) int {
+	best := polygon[0]
+	for _, i := range polygon {
+		if points[i].X() > points[best].X() {
+			best = i
+		}
+	}
+	return best
+}
+
+func makeIndices(start int, count int) []int {
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = start + i
+	}
+	return indices
+}
+
+func reverseIndices(indices []int) {
+	for i, j := 0, len(indices)-1; i < j; i, j = i+1, j-1 {
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestTriangulatePolygon(t *testing.T) {
+	square := []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	clockwiseSquare := []mgl64.Vec2{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+	lShape := []mgl64.Vec2{{0, 0}, {10, 0}, {10, 4}, {4, 4}, {4, 10}, {0, 10}}
+	comb := []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}, {8, 10}, {8, 2}, {6, 2}, {6, 10}, {4, 10}, {4, 2}, {2, 2}, {2, 10}, {0, 10}}
+	hole := []mgl64.Vec2{{4, 4}, {6, 4}, {6, 6}, {4, 6}}
+	otherHole := []mgl64.Vec2{{1, 1}, {2, 1}, {2, 2}, {1, 2}}
+
+	tests := []struct {
+		name         string
+		points       []mgl64.Vec2
+		holes        [][]mgl64.Vec2
+		numTriangles int
+		area         float64
+	}{
+		{"square", square, nil, 2, 100},
+		{"clockwise", clockwiseSquare, nil, 2, 100},
+		{"concave", lShape, nil, 4, 64},
+		{"comb", comb, nil, 10, 68},
+		{"hole", square, [][]mgl64.Vec2{hole}, 8, 96},
+		{"two holes", lShape, [][]mgl64.Vec2{otherHole, {{5, 1}, {7, 1}, {7, 3}, {5, 3}}}, 16, 59},
+	}
+
+	for _, test := range tests {
+		indices, err := TriangulatePolygon(test.points, test.holes)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(indices) != test.numTriangles*3 {
+			t.Errorf("%s: number of triangles expected\n%v received\n%v", test.name, test.numTriangles, len(indices)/3)
+		}
+
+		all := append([]mgl64.Vec2(nil), test.points...)
+		for _, h := range test.holes {
+			all = append(all, h...)
+		}
+		area := 0.0
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := all[indices[i]], all[indices[i+1]], all[indices[i+2]]
+			triangleArea := cross(a, b, c) / 2
+			if triangleArea <= 0 {
+				t.Errorf("%s: triangle %v %v %v is not counterclockwise", test.name, a, b, c)
+			}
+			area += math.Abs(triangleArea)
+		}
+		if math.Abs(area-test.area) > 1e-9 {
+			t.Errorf("%s: area expected\n%v received\n%v", test.name, test.area, area)
+		}
+	}
+}
+
+func TestTriangulatePolygonErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []mgl64.Vec2
+		holes  [][]mgl64.Vec2
+	}{
+		{"too few points", []mgl64.Vec2{{0, 0}, {1, 1}}, nil},
+		{"no area", []mgl64.Vec2{{0, 0}, {1, 1}, {2, 2}}, nil},
+		{"invalid hole", []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}}, [][]mgl64.Vec2{{{1, 1}, {2, 1}}}},
+		{"hole outside", []mgl64.Vec2{{0, 0}, {10, 0}, {10, 10}}, [][]mgl64.Vec2{{{20, 1}, {21, 1}, {21, 2}}}},
+	}
+	for _, test := range tests {
+		if _, err := TriangulatePolygon(test.points, test.holes); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}